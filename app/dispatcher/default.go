@@ -6,6 +6,7 @@ import (
 	"context"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	core "github.com/v2fly/v2ray-core/v5"
@@ -129,7 +130,7 @@ func (*DefaultDispatcher) Start() error {
 // Close implements common.Closable.
 func (*DefaultDispatcher) Close() error { return nil }
 
-func (d *DefaultDispatcher) getLink(ctx context.Context, network net.Network, sniffing session.SniffingRequest) (*transport.Link, *transport.Link) {
+func (d *DefaultDispatcher) getLink(ctx context.Context, network net.Network, sniffing session.SniffingRequest) (*transport.Link, *transport.Link, error) {
 	opt := pipe.OptionsFromContext(ctx)
 	downlinkReader, downlinkWriter := pipe.New(opt...)
 
@@ -184,8 +185,12 @@ func (d *DefaultDispatcher) getLink(ctx context.Context, network net.Network, sn
 
 	sessionInbound := session.InboundFromContext(ctx)
 	var user *protocol.MemoryUser
+	var sourceIP string
 	if sessionInbound != nil {
 		user = sessionInbound.User
+		if source := sessionInbound.Source; source.IsValid() && source.Address.Family().IsIP() {
+			sourceIP = source.Address.IP().String()
+		}
 	}
 
 	if user != nil && len(user.Email) > 0 {
@@ -208,9 +213,68 @@ func (d *DefaultDispatcher) getLink(ctx context.Context, network net.Network, sn
 				}
 			}
 		}
+		var onClose []func()
+		if limiter, ok := d.policy.(policy.UserLimiter); ok {
+			tracker, err := limiter.TrackUser(ctx, user, sourceIP)
+			if err != nil {
+				common.Close(uplinkWriter)
+				common.Close(downlinkWriter)
+				return nil, nil, err
+			}
+			if tracker != nil {
+				inboundLink.Writer = &LimitedWriter{
+					Account: tracker.Uplink,
+					Writer:  inboundLink.Writer,
+				}
+				outboundLink.Writer = &LimitedWriter{
+					Account: tracker.Downlink,
+					Writer:  outboundLink.Writer,
+				}
+				onClose = append(onClose, func() { tracker.Close() })
+			}
+		}
+		if tracker, ok := d.stats.(stats.OnlineTracker); ok && p.Stats.UserOnline && sourceIP != "" {
+			tracker.AddOnlineIP(user.Email, sourceIP)
+			onClose = append(onClose, func() { tracker.RemoveOnlineIP(user.Email, sourceIP) })
+		}
+		if len(onClose) > 0 {
+			// The connection is over when both directions are closed, or when its context is done
+			// in case a writer is never closed.
+			var once sync.Once
+			released := make(chan struct{})
+			releaseAll := func() {
+				once.Do(func() {
+					close(released)
+					for _, f := range onClose {
+						f()
+					}
+				})
+			}
+			go func() {
+				select {
+				case <-ctx.Done():
+					releaseAll()
+				case <-released:
+				}
+			}()
+			pending := int32(2)
+			release := func() {
+				if atomic.AddInt32(&pending, -1) == 0 {
+					releaseAll()
+				}
+			}
+			inboundLink.Writer = &CloseNotifyWriter{
+				Writer:  inboundLink.Writer,
+				OnClose: release,
+			}
+			outboundLink.Writer = &CloseNotifyWriter{
+				Writer:  outboundLink.Writer,
+				OnClose: release,
+			}
+		}
 	}
 
-	return inboundLink, outboundLink
+	return inboundLink, outboundLink, nil
 }
 
 func shouldOverride(result SniffResult, domainOverride []string) bool {
@@ -250,7 +314,11 @@ func (d *DefaultDispatcher) Dispatch(ctx context.Context, destination net.Destin
 		ctx = session.ContextWithContent(ctx, content)
 	}
 	sniffingRequest := content.SniffingRequest
-	inbound, outbound := d.getLink(ctx, destination.Network, sniffingRequest)
+	inbound, outbound, err := d.getLink(ctx, destination.Network, sniffingRequest)
+	if err != nil {
+		newError("connection refused").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+		return nil, err
+	}
 	if !sniffingRequest.Enabled {
 		go d.routedDispatch(ctx, outbound, destination)
 	} else {
@@ -262,6 +330,16 @@ func (d *DefaultDispatcher) Dispatch(ctx context.Context, destination net.Destin
 			result, err := sniffer(ctx, cReader, sniffingRequest.MetadataOnly, destination.Network)
 			if err == nil {
 				content.Protocol = result.Protocol()
+				if attrs, ok := result.(SnifferResultAttributes); ok {
+					for key, value := range attrs.Attributes() {
+						if content.Attribute(key) == "" {
+							content.SetAttribute(key, value)
+						}
+					}
+				}
+				if accessMessage := log.AccessMessageFromContext(ctx); accessMessage != nil {
+					accessMessage.Domain = result.Domain()
+				}
 			}
 			if err == nil && shouldOverride(result, sniffingRequest.OverrideDestinationForProtocol) {
 				domain := result.Domain()
@@ -373,6 +451,10 @@ func (d *DefaultDispatcher) routedDispatch(ctx context.Context, link *transport.
 		if tag := handler.Tag(); tag != "" {
 			accessMessage.Detour = tag
 		}
+		if inbound := session.InboundFromContext(ctx); inbound != nil {
+			accessMessage.InboundTag = inbound.Tag
+		}
+		accessMessage.Network = destination.Network.SystemString()
 		log.Record(accessMessage)
 	}
 
@@ -0,0 +1,53 @@
+package dispatcher
+
+import (
+	"sync"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+)
+
+// LimitedWriter accounts the written bytes against the usage limits of a user.
+// Writing fails once Account returns an error.
+type LimitedWriter struct {
+	Account func(n int64) error
+	Writer  buf.Writer
+}
+
+func (w *LimitedWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	if err := w.Account(int64(mb.Len())); err != nil {
+		buf.ReleaseMulti(mb)
+		return err
+	}
+	return w.Writer.WriteMultiBuffer(mb)
+}
+
+func (w *LimitedWriter) Close() error {
+	return common.Close(w.Writer)
+}
+
+func (w *LimitedWriter) Interrupt() {
+	common.Interrupt(w.Writer)
+}
+
+// CloseNotifyWriter calls OnClose once, when the writer is closed or interrupted.
+type CloseNotifyWriter struct {
+	Writer  buf.Writer
+	OnClose func()
+
+	once sync.Once
+}
+
+func (w *CloseNotifyWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	return w.Writer.WriteMultiBuffer(mb)
+}
+
+func (w *CloseNotifyWriter) Close() error {
+	defer w.once.Do(w.OnClose)
+	return common.Close(w.Writer)
+}
+
+func (w *CloseNotifyWriter) Interrupt() {
+	defer w.once.Do(w.OnClose)
+	common.Interrupt(w.Writer)
+}
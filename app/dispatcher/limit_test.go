@@ -0,0 +1,47 @@
+package dispatcher_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/v2fly/v2ray-core/v5/app/dispatcher"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+)
+
+func TestLimitedWriter(t *testing.T) {
+	var total int64
+	writer := &LimitedWriter{
+		Account: func(n int64) error {
+			if total+n > 5 {
+				return errors.New("quota exhausted")
+			}
+			total += n
+			return nil
+		},
+		Writer: buf.Discard,
+	}
+
+	common.Must(writer.WriteMultiBuffer(buf.MergeBytes(nil, []byte("abcd"))))
+	if err := writer.WriteMultiBuffer(buf.MergeBytes(nil, []byte("efg"))); err == nil {
+		t.Error("expect write to fail")
+	}
+	if total != 4 {
+		t.Error("unexpected total bytes. want 4, but got ", total)
+	}
+}
+
+func TestCloseNotifyWriter(t *testing.T) {
+	closed := 0
+	writer := &CloseNotifyWriter{
+		Writer:  buf.Discard,
+		OnClose: func() { closed++ },
+	}
+
+	common.Must(writer.WriteMultiBuffer(buf.MergeBytes(nil, []byte("abcd"))))
+	common.Must(writer.Close())
+	writer.Interrupt()
+	if closed != 1 {
+		t.Error("expect OnClose to be called once, but got ", closed)
+	}
+}
@@ -134,6 +134,13 @@ func (c compositeResult) ProtocolForDomainResult() string {
 	return c.domainResult.Protocol()
 }
 
+func (c compositeResult) Attributes() map[string]string {
+	if attrs, ok := c.protocolResult.(SnifferResultAttributes); ok {
+		return attrs.Attributes()
+	}
+	return nil
+}
+
 type SnifferResultComposite interface {
 	ProtocolForDomainResult() string
 }
@@ -141,3 +148,8 @@ type SnifferResultComposite interface {
 type SnifferIsProtoSubsetOf interface {
 	IsProtoSubsetOf(protocolName string) bool
 }
+
+// SnifferResultAttributes is implemented by results that carry attributes of the content, such as HTTP headers.
+type SnifferResultAttributes interface {
+	Attributes() map[string]string
+}
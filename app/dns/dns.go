@@ -12,6 +12,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/v2fly/v2ray-core/v5/app/router"
 	"github.com/v2fly/v2ray-core/v5/common"
@@ -28,15 +29,23 @@ import (
 
 // DNS is a DNS rely server.
 type DNS struct {
+	// Mutex serializes the changes of ipOption and servers.
 	sync.Mutex
+	ctx context.Context
+
+	// ipOption and servers are replaced as a whole when they are changed, so lookups read them without locking.
+	ipOption atomic.Pointer[dns.IPOption]
+	servers  atomic.Pointer[nameServers]
+}
+
+// nameServers holds the name servers and the rules to pick them.
+type nameServers struct {
 	tag                    string
 	disableCache           bool
 	disableFallback        bool
 	disableFallbackIfMatch bool
-	ipOption               *dns.IPOption
 	hosts                  *StaticHosts
 	clients                []*Client
-	ctx                    context.Context
 	domainMatcher          strmatcher.IndexMatcher
 	matcherInfos           []DomainMatcherInfo
 }
@@ -49,6 +58,20 @@ type DomainMatcherInfo struct {
 
 // New creates a new DNS server with given configuration.
 func New(ctx context.Context, config *Config) (*DNS, error) {
+	servers, ipOption, err := newNameServers(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	d := &DNS{
+		ctx: ctx,
+	}
+	d.ipOption.Store(ipOption)
+	d.servers.Store(servers)
+	return d, nil
+}
+
+// newNameServers creates the name servers and the query option of the configuration.
+func newNameServers(ctx context.Context, config *Config) (*nameServers, *dns.IPOption, error) {
 	var tag string
 	if len(config.Tag) > 0 {
 		tag = config.Tag
@@ -61,7 +84,7 @@ func New(ctx context.Context, config *Config) (*DNS, error) {
 	case 0, net.IPv4len, net.IPv6len:
 		clientIP = net.IP(config.ClientIp)
 	default:
-		return nil, newError("unexpected client IP length ", len(config.ClientIp))
+		return nil, nil, newError("unexpected client IP length ", len(config.ClientIp))
 	}
 
 	var ipOption *dns.IPOption
@@ -88,7 +111,7 @@ func New(ctx context.Context, config *Config) (*DNS, error) {
 
 	hosts, err := NewStaticHosts(config.StaticHosts, config.Hosts)
 	if err != nil {
-		return nil, newError("failed to create hosts").Base(err)
+		return nil, nil, newError("failed to create hosts").Base(err)
 	}
 
 	clients := []*Client{}
@@ -106,7 +129,7 @@ func New(ctx context.Context, config *Config) (*DNS, error) {
 		features.PrintDeprecatedFeatureWarning("simple DNS server")
 		client, err := NewSimpleClient(ctx, endpoint, clientIP)
 		if err != nil {
-			return nil, newError("failed to create client").Base(err)
+			return nil, nil, newError("failed to create client").Base(err)
 		}
 		clients = append(clients, client)
 	}
@@ -129,7 +152,7 @@ func New(ctx context.Context, config *Config) (*DNS, error) {
 		}
 		client, err := NewClient(ctx, ns, myClientIP, geoipContainer, &matcherInfos, updateDomain)
 		if err != nil {
-			return nil, newError("failed to create client").Base(err)
+			return nil, nil, newError("failed to create client").Base(err)
 		}
 		clients = append(clients, client)
 	}
@@ -139,18 +162,42 @@ func New(ctx context.Context, config *Config) (*DNS, error) {
 		clients = append(clients, NewLocalDNSClient())
 	}
 
-	return &DNS{
+	return &nameServers{
 		tag:                    tag,
 		hosts:                  hosts,
-		ipOption:               ipOption,
 		clients:                clients,
-		ctx:                    ctx,
 		domainMatcher:          domainMatcher,
 		matcherInfos:           matcherInfos,
 		disableCache:           config.DisableCache,
 		disableFallback:        config.DisableFallback,
 		disableFallbackIfMatch: config.DisableFallbackIfMatch,
-	}, nil
+	}, ipOption, nil
+}
+
+// ReloadConfig implements features.ConfigReloader.
+func (s *DNS) ReloadConfig(config interface{}) error {
+	var c *Config
+	switch config := config.(type) {
+	case *Config:
+		c = config
+	case *SimplifiedConfig:
+		var err error
+		if c, err = config.toConfig(cfgcommon.NewConfigureLoadingContext(s.ctx)); err != nil {
+			return err
+		}
+	default:
+		return newError("unexpected config type")
+	}
+	servers, ipOption, err := newNameServers(s.ctx, c)
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.ipOption.Store(ipOption)
+	// Queries in progress on the replaced servers fail, and are not retried.
+	s.servers.Swap(servers).close()
+	return nil
 }
 
 // Type implements common.HasType.
@@ -165,40 +212,54 @@ func (s *DNS) Start() error {
 
 // Close implements common.Closable.
 func (s *DNS) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	s.servers.Load().close()
 	return nil
 }
 
 // IsOwnLink implements proxy.dns.ownLinkVerifier
 func (s *DNS) IsOwnLink(ctx context.Context) bool {
 	inbound := session.InboundFromContext(ctx)
-	return inbound != nil && inbound.Tag == s.tag
+	return inbound != nil && inbound.Tag == s.servers.Load().tag
 }
 
 // LookupIP implements dns.Client.
 func (s *DNS) LookupIP(domain string) ([]net.IP, error) {
-	return s.lookupIPInternal(domain, *s.ipOption)
+	return s.lookupIPInternal(domain, *s.ipOption.Load())
 }
 
 // LookupIPv4 implements dns.IPv4Lookup.
 func (s *DNS) LookupIPv4(domain string) ([]net.IP, error) {
-	if !s.ipOption.IPv4Enable {
+	o := *s.ipOption.Load()
+	if !o.IPv4Enable {
 		return nil, dns.ErrEmptyResponse
 	}
-	o := *s.ipOption
 	o.IPv6Enable = false
 	return s.lookupIPInternal(domain, o)
 }
 
 // LookupIPv6 implements dns.IPv6Lookup.
 func (s *DNS) LookupIPv6(domain string) ([]net.IP, error) {
-	if !s.ipOption.IPv6Enable {
+	o := *s.ipOption.Load()
+	if !o.IPv6Enable {
 		return nil, dns.ErrEmptyResponse
 	}
-	o := *s.ipOption
 	o.IPv4Enable = false
 	return s.lookupIPInternal(domain, o)
 }
 
+// LookupIPWithOption implements dns.IPLookupWithOption.
+func (s *DNS) LookupIPWithOption(domain string, option dns.IPOption) ([]net.IP, error) {
+	o := s.ipOption.Load()
+	option.IPv4Enable = option.IPv4Enable && o.IPv4Enable
+	option.IPv6Enable = option.IPv6Enable && o.IPv6Enable
+	if !option.IPv4Enable && !option.IPv6Enable {
+		return nil, dns.ErrEmptyResponse
+	}
+	return s.lookupIPInternal(domain, option)
+}
+
 func (s *DNS) lookupIPInternal(domain string, option dns.IPOption) ([]net.IP, error) {
 	if domain == "" {
 		return nil, newError("empty domain name")
@@ -207,8 +268,10 @@ func (s *DNS) lookupIPInternal(domain string, option dns.IPOption) ([]net.IP, er
 	// Normalize the FQDN form query
 	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
 
+	servers := s.servers.Load()
+
 	// Static host lookup
-	switch addrs := s.hosts.Lookup(domain, option); {
+	switch addrs := servers.hosts.Lookup(domain, option); {
 	case addrs == nil: // Domain not recorded in static host
 		break
 	case len(addrs) == 0: // Domain recorded, but no valid IP returned (e.g. IPv4 address with only IPv6 enabled)
@@ -223,13 +286,13 @@ func (s *DNS) lookupIPInternal(domain string, option dns.IPOption) ([]net.IP, er
 
 	// Name servers lookup
 	errs := []error{}
-	ctx := session.ContextWithInbound(s.ctx, &session.Inbound{Tag: s.tag})
-	for _, client := range s.sortClients(domain) {
+	ctx := session.ContextWithInbound(s.ctx, &session.Inbound{Tag: servers.tag})
+	for _, client := range servers.sortClients(domain) {
 		if !option.FakeEnable && strings.EqualFold(client.Name(), "FakeDNS") {
 			newError("skip DNS resolution for domain ", domain, " at server ", client.Name()).AtDebug().WriteToLog()
 			continue
 		}
-		ips, err := client.QueryIP(ctx, domain, option, s.disableCache)
+		ips, err := client.QueryIP(ctx, domain, option, servers.disableCache)
 		if len(ips) > 0 {
 			return ips, nil
 		}
@@ -245,23 +308,41 @@ func (s *DNS) lookupIPInternal(domain string, option dns.IPOption) ([]net.IP, er
 	return nil, newError("returning nil for domain ", domain).Base(errors.Combine(errs...))
 }
 
-// GetIPOption implements ClientWithIPOption.
+// GetIPOption implements ClientWithIPOption. The returned option must not be modified.
 func (s *DNS) GetIPOption() *dns.IPOption {
-	return s.ipOption
+	return s.ipOption.Load()
 }
 
 // SetQueryOption implements ClientWithIPOption.
 func (s *DNS) SetQueryOption(isIPv4Enable, isIPv6Enable bool) {
-	s.ipOption.IPv4Enable = isIPv4Enable
-	s.ipOption.IPv6Enable = isIPv6Enable
+	s.Lock()
+	defer s.Unlock()
+	o := *s.ipOption.Load()
+	o.IPv4Enable = isIPv4Enable
+	o.IPv6Enable = isIPv6Enable
+	s.ipOption.Store(&o)
 }
 
 // SetFakeDNSOption implements ClientWithIPOption.
 func (s *DNS) SetFakeDNSOption(isFakeEnable bool) {
-	s.ipOption.FakeEnable = isFakeEnable
+	s.Lock()
+	defer s.Unlock()
+	o := *s.ipOption.Load()
+	if o.FakeEnable == isFakeEnable {
+		return
+	}
+	o.FakeEnable = isFakeEnable
+	s.ipOption.Store(&o)
 }
 
-func (s *DNS) sortClients(domain string) []*Client {
+// close closes the name servers.
+func (s *nameServers) close() {
+	for _, client := range s.clients {
+		common.Close(client)
+	}
+}
+
+func (s *nameServers) sortClients(domain string) []*Client {
 	clients := make([]*Client, 0, len(s.clients))
 	clientUsed := make([]bool, len(s.clients))
 	clientNames := make([]string, 0, len(s.clients))
@@ -323,66 +404,73 @@ func init() {
 
 	common.Must(common.RegisterConfig((*SimplifiedConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
 		ctx = cfgcommon.NewConfigureLoadingContext(ctx)
+		fullConfig, err := config.(*SimplifiedConfig).toConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return common.CreateObject(ctx, fullConfig)
+	}))
+}
 
-		geoloadername := platform.NewEnvFlag("v2ray.conf.geoloader").GetValue(func() string {
-			return "memconservative"
-		})
+// toConfig converts the simplified config to a full Config. ctx must be a
+// configure loading context.
+func (c *SimplifiedConfig) toConfig(ctx context.Context) (*Config, error) {
+	geoloadername := platform.NewEnvFlag("v2ray.conf.geoloader").GetValue(func() string {
+		return "memconservative"
+	})
 
-		if loader, err := geodata.GetGeoDataLoader(geoloadername); err == nil {
-			cfgcommon.SetGeoDataLoader(ctx, loader)
-		} else {
-			return nil, newError("unable to create geo data loader ").Base(err)
-		}
+	if loader, err := geodata.GetGeoDataLoader(geoloadername); err == nil {
+		cfgcommon.SetGeoDataLoader(ctx, loader)
+	} else {
+		return nil, newError("unable to create geo data loader ").Base(err)
+	}
 
-		cfgEnv := cfgcommon.GetConfigureLoadingEnvironment(ctx)
-		geoLoader := cfgEnv.GetGeoLoader()
-
-		simplifiedConfig := config.(*SimplifiedConfig)
-		for _, v := range simplifiedConfig.NameServer {
-			for _, geo := range v.Geoip {
-				if geo.Code != "" {
-					filepath := "geoip.dat"
-					if geo.FilePath != "" {
-						filepath = geo.FilePath
-					} else {
-						geo.CountryCode = geo.Code
-					}
-					var err error
-					geo.Cidr, err = geoLoader.LoadIP(filepath, geo.Code)
-					if err != nil {
-						return nil, newError("unable to load geoip").Base(err)
-					}
+	cfgEnv := cfgcommon.GetConfigureLoadingEnvironment(ctx)
+	geoLoader := cfgEnv.GetGeoLoader()
+
+	for _, v := range c.NameServer {
+		for _, geo := range v.Geoip {
+			if geo.Code != "" {
+				filepath := "geoip.dat"
+				if geo.FilePath != "" {
+					filepath = geo.FilePath
+				} else {
+					geo.CountryCode = geo.Code
+				}
+				var err error
+				geo.Cidr, err = geoLoader.LoadIP(filepath, geo.Code)
+				if err != nil {
+					return nil, newError("unable to load geoip").Base(err)
 				}
 			}
 		}
+	}
 
-		var nameservers []*NameServer
+	var nameservers []*NameServer
 
-		for _, v := range simplifiedConfig.NameServer {
-			nameserver := &NameServer{
-				Address:      v.Address,
-				ClientIp:     net.ParseIP(v.ClientIp),
-				SkipFallback: v.SkipFallback,
-				Geoip:        v.Geoip,
-			}
-			for _, prioritizedDomain := range v.PrioritizedDomain {
-				nameserver.PrioritizedDomain = append(nameserver.PrioritizedDomain, &NameServer_PriorityDomain{
-					Type:   prioritizedDomain.Type,
-					Domain: prioritizedDomain.Domain,
-				})
-			}
-			nameservers = append(nameservers, nameserver)
+	for _, v := range c.NameServer {
+		nameserver := &NameServer{
+			Address:      v.Address,
+			ClientIp:     net.ParseIP(v.ClientIp),
+			SkipFallback: v.SkipFallback,
+			Geoip:        v.Geoip,
 		}
-
-		fullConfig := &Config{
-			NameServer:      nameservers,
-			ClientIp:        net.ParseIP(simplifiedConfig.ClientIp),
-			StaticHosts:     simplifiedConfig.StaticHosts,
-			Tag:             simplifiedConfig.Tag,
-			DisableCache:    simplifiedConfig.DisableCache,
-			QueryStrategy:   simplifiedConfig.QueryStrategy,
-			DisableFallback: simplifiedConfig.DisableFallback,
+		for _, prioritizedDomain := range v.PrioritizedDomain {
+			nameserver.PrioritizedDomain = append(nameserver.PrioritizedDomain, &NameServer_PriorityDomain{
+				Type:   prioritizedDomain.Type,
+				Domain: prioritizedDomain.Domain,
+			})
 		}
-		return common.CreateObject(ctx, fullConfig)
-	}))
+		nameservers = append(nameservers, nameserver)
+	}
+
+	return &Config{
+		NameServer:      nameservers,
+		ClientIp:        net.ParseIP(c.ClientIp),
+		StaticHosts:     c.StaticHosts,
+		Tag:             c.Tag,
+		DisableCache:    c.DisableCache,
+		QueryStrategy:   c.QueryStrategy,
+		DisableFallback: c.DisableFallback,
+	}, nil
 }
@@ -1,6 +1,7 @@
 package dns_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -969,3 +970,63 @@ func TestMultiMatchPrioritizedDomain(t *testing.T) {
 		t.Error("DNS query doesn't finish in 2 seconds.")
 	}
 }
+
+func TestReloadConfig(t *testing.T) {
+	newConfig := func(ip []byte, strategy QueryStrategy) *Config {
+		return &Config{
+			StaticHosts: []*HostMapping{
+				{
+					Type:   DomainMatchingType_Full,
+					Domain: "v2fly.org",
+					Ip:     [][]byte{ip},
+				},
+			},
+			QueryStrategy: strategy,
+		}
+	}
+
+	client, err := New(context.Background(), newConfig([]byte{1, 1, 1, 1}, QueryStrategy_USE_IP))
+	common.Must(err)
+	option := client.GetIPOption()
+
+	common.Must(client.ReloadConfig(newConfig([]byte{2, 2, 2, 2}, QueryStrategy_USE_IP4)))
+
+	ips, err := client.LookupIP("v2fly.org")
+	common.Must(err)
+	if r := cmp.Diff(ips, []net.IP{{2, 2, 2, 2}}); r != "" {
+		t.Error(r)
+	}
+	if o := client.GetIPOption(); !o.IPv4Enable || o.IPv6Enable {
+		t.Error("query strategy not reloaded: ", o)
+	}
+	// The option is replaced, not modified, as it may be in use.
+	if !option.IPv6Enable {
+		t.Error("previous option modified: ", option)
+	}
+	common.Must(client.Close())
+}
+
+func TestLookupIPWithOption(t *testing.T) {
+	client, err := New(context.Background(), &Config{
+		StaticHosts: []*HostMapping{
+			{
+				Type:   DomainMatchingType_Full,
+				Domain: "v2fly.org",
+				Ip:     [][]byte{{1, 1, 1, 1}, {0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}},
+			},
+		},
+		QueryStrategy: QueryStrategy_USE_IP4,
+	})
+	common.Must(err)
+	defer client.Close()
+
+	ips, err := client.LookupIPWithOption("v2fly.org", feature_dns.IPOption{IPv4Enable: true, IPv6Enable: true})
+	common.Must(err)
+	if r := cmp.Diff(ips, []net.IP{{1, 1, 1, 1}}); r != "" {
+		t.Error(r)
+	}
+	// The query strategy still applies.
+	if _, err := client.LookupIPWithOption("v2fly.org", feature_dns.IPOption{IPv6Enable: true}); err != feature_dns.ErrEmptyResponse {
+		t.Error("expected empty response, but got ", err)
+	}
+}
@@ -139,6 +139,12 @@ func (s *DoHNameServer) Name() string {
 	return s.name
 }
 
+// Close implements common.Closable.
+func (s *DoHNameServer) Close() error {
+	s.httpClient.CloseIdleConnections()
+	return s.cleanup.Close()
+}
+
 // Cleanup clears expired items from cache
 func (s *DoHNameServer) Cleanup() error {
 	now := time.Now()
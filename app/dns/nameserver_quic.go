@@ -21,6 +21,7 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common/signal/pubsub"
 	"github.com/v2fly/v2ray-core/v5/common/task"
 	dns_feature "github.com/v2fly/v2ray-core/v5/features/dns"
+	"github.com/v2fly/v2ray-core/v5/features/routing"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
 )
 
@@ -40,12 +41,37 @@ type QUICNameServer struct {
 	name        string
 	destination net.Destination
 	connection  quic.Connection
+	dial        func(context.Context) (quic.Connection, error)
 }
 
 // NewQUICNameServer creates DNS-over-QUIC client object for local resolving
 func NewQUICNameServer(url *url.URL) (*QUICNameServer, error) {
 	newError("DNS: created Local DNS-over-QUIC client for ", url.String()).AtInfo().WriteToLog()
 
+	s, err := baseQUICNameServer(url)
+	if err != nil {
+		return nil, err
+	}
+	s.dial = s.openConnection
+	return s, nil
+}
+
+// NewQUICRemoteNameServer creates DNS-over-QUIC client object for remote resolving.
+// The QUIC packets are sent through the dispatcher.
+func NewQUICRemoteNameServer(url *url.URL, dispatcher routing.Dispatcher) (*QUICNameServer, error) {
+	newError("DNS: created Remote DNS-over-QUIC client for ", url.String()).AtInfo().WriteToLog()
+
+	s, err := baseQUICNameServer(url)
+	if err != nil {
+		return nil, err
+	}
+	s.dial = func(ctx context.Context) (quic.Connection, error) {
+		return s.openDispatchedConnection(ctx, dispatcher)
+	}
+	return s, nil
+}
+
+func baseQUICNameServer(url *url.URL) (*QUICNameServer, error) {
 	var err error
 	port := net.Port(853)
 	if url.Port() != "" {
@@ -75,6 +101,17 @@ func (s *QUICNameServer) Name() string {
 	return s.name
 }
 
+// Close implements common.Closable.
+func (s *QUICNameServer) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	if s.connection != nil {
+		s.connection.CloseWithError(0, "")
+		s.connection = nil
+	}
+	return s.cleanup.Close()
+}
+
 // Cleanup clears expired items from cache
 func (s *QUICNameServer) Cleanup() error {
 	now := time.Now()
@@ -361,14 +398,14 @@ func (s *QUICNameServer) getConnection(ctx context.Context) (quic.Connection, er
 	defer s.Unlock()
 
 	var err error
-	conn, err = s.openConnection(ctx)
+	conn, err = s.dial(ctx)
 	if err != nil {
 		// This does not look too nice, but QUIC (or maybe quic-go)
 		// doesn't seem stable enough.
 		// Maybe retransmissions aren't fully implemented in quic-go?
 		// Anyways, the simple solution is to make a second try when
 		// it fails to open the QUIC connection.
-		conn, err = s.openConnection(ctx)
+		conn, err = s.dial(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -391,6 +428,36 @@ func (s *QUICNameServer) openConnection(ctx context.Context) (quic.Connection, e
 	return conn, nil
 }
 
+func (s *QUICNameServer) openDispatchedConnection(ctx context.Context, dispatcher routing.Dispatcher) (quic.Connection, error) {
+	// The connection is shared by later queries, so it must not end with the context of this one.
+	dispatcherCtx := context.Background()
+	dispatcherCtx = session.ContextWithContent(dispatcherCtx, session.ContentFromContext(ctx))
+	dispatcherCtx = session.ContextWithInbound(dispatcherCtx, session.InboundFromContext(ctx))
+
+	link, err := dispatcher.Dispatch(dispatcherCtx, s.destination)
+	if err != nil {
+		return nil, err
+	}
+	packetConn := newDispatchedPacketConn(link, s.destination)
+
+	tlsConfig := tls.Config{}
+	quicConfig := &quic.Config{
+		HandshakeIdleTimeout: handshakeIdleTimeout,
+	}
+
+	conn, err := quic.DialContext(ctx, packetConn, packetConn.remote, s.destination.NetAddr(), tlsConfig.GetTLSConfig(tls.WithNextProto("http/1.1", http2.NextProtoTLS, NextProtoDQ)), quicConfig)
+	if err != nil {
+		packetConn.Close()
+		return nil, err
+	}
+	go func() {
+		<-conn.Context().Done()
+		packetConn.Close()
+	}()
+
+	return conn, nil
+}
+
 func (s *QUICNameServer) openStream(ctx context.Context) (quic.Stream, error) {
 	conn, err := s.getConnection(ctx)
 	if err != nil {
@@ -6,6 +6,7 @@ package dns
 import (
 	"bytes"
 	"context"
+	gotls "crypto/tls"
 	"encoding/binary"
 	"net/url"
 	"sync"
@@ -25,9 +26,13 @@ import (
 	dns_feature "github.com/v2fly/v2ray-core/v5/features/dns"
 	"github.com/v2fly/v2ray-core/v5/features/routing"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
 )
 
-// TCPNameServer implemented DNS over TCP (RFC7766).
+// NextProtoDoT is the ALPN token of DNS over TLS.
+const NextProtoDoT = "dot"
+
+// TCPNameServer implemented DNS over TCP (RFC7766), and DNS over TLS (RFC7858) on top of it.
 type TCPNameServer struct {
 	sync.RWMutex
 	name        string
@@ -41,43 +46,55 @@ type TCPNameServer struct {
 
 // NewTCPNameServer creates DNS over TCP server object for remote resolving.
 func NewTCPNameServer(url *url.URL, dispatcher routing.Dispatcher) (*TCPNameServer, error) {
-	s, err := baseTCPNameServer(url, "TCP")
+	s, err := baseTCPNameServer(url, "TCP", 53)
 	if err != nil {
 		return nil, err
 	}
 
-	s.dial = func(ctx context.Context) (net.Conn, error) {
-		link, err := dispatcher.Dispatch(ctx, s.destination)
-		if err != nil {
-			return nil, err
-		}
-
-		return cnc.NewConnection(
-			cnc.ConnectionInputMulti(link.Writer),
-			cnc.ConnectionOutputMulti(link.Reader),
-		), nil
-	}
+	s.dial = dispatchedDialer(dispatcher, s.destination)
 
 	return s, nil
 }
 
 // NewTCPLocalNameServer creates DNS over TCP client object for local resolving
 func NewTCPLocalNameServer(url *url.URL) (*TCPNameServer, error) {
-	s, err := baseTCPNameServer(url, "TCPL")
+	s, err := baseTCPNameServer(url, "TCPL", 53)
+	if err != nil {
+		return nil, err
+	}
+
+	s.dial = systemDialer(s.destination)
+
+	return s, nil
+}
+
+// NewTLSNameServer creates DNS over TLS (RFC7858) server object for remote resolving.
+func NewTLSNameServer(url *url.URL, dispatcher routing.Dispatcher) (*TCPNameServer, error) {
+	s, err := baseTCPNameServer(url, "DOT", 853)
 	if err != nil {
 		return nil, err
 	}
 
-	s.dial = func(ctx context.Context) (net.Conn, error) {
-		return internet.DialSystem(ctx, s.destination, nil)
+	s.dial = tlsDialer(url, dispatchedDialer(dispatcher, s.destination))
+
+	return s, nil
+}
+
+// NewTLSLocalNameServer creates DNS over TLS (RFC7858) client object for local resolving
+func NewTLSLocalNameServer(url *url.URL) (*TCPNameServer, error) {
+	s, err := baseTCPNameServer(url, "DOTL", 853)
+	if err != nil {
+		return nil, err
 	}
 
+	s.dial = tlsDialer(url, systemDialer(s.destination))
+
 	return s, nil
 }
 
-func baseTCPNameServer(url *url.URL, prefix string) (*TCPNameServer, error) {
+func baseTCPNameServer(url *url.URL, prefix string, defaultPort net.Port) (*TCPNameServer, error) {
 	var err error
-	port := net.Port(53)
+	port := defaultPort
 	if url.Port() != "" {
 		port, err = net.PortFromString(url.Port())
 		if err != nil {
@@ -100,11 +117,58 @@ func baseTCPNameServer(url *url.URL, prefix string) (*TCPNameServer, error) {
 	return s, nil
 }
 
+func dispatchedDialer(dispatcher routing.Dispatcher, dest net.Destination) func(context.Context) (net.Conn, error) {
+	return func(ctx context.Context) (net.Conn, error) {
+		link, err := dispatcher.Dispatch(ctx, dest)
+		if err != nil {
+			return nil, err
+		}
+
+		return cnc.NewConnection(
+			cnc.ConnectionInputMulti(link.Writer),
+			cnc.ConnectionOutputMulti(link.Reader),
+		), nil
+	}
+}
+
+func systemDialer(dest net.Destination) func(context.Context) (net.Conn, error) {
+	return func(ctx context.Context) (net.Conn, error) {
+		return internet.DialSystem(ctx, dest, nil)
+	}
+}
+
+// tlsDialer wraps the connections of dial in TLS, verifying the certificate against the host of the url.
+func tlsDialer(url *url.URL, dial func(context.Context) (net.Conn, error)) func(context.Context) (net.Conn, error) {
+	config := &tls.Config{
+		ServerName: url.Hostname(),
+	}
+	tlsConfig := config.GetTLSConfig(tls.WithNextProto(NextProtoDoT))
+
+	return func(ctx context.Context) (net.Conn, error) {
+		conn, err := dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConn := gotls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, newError("failed to complete TLS handshake").Base(err)
+		}
+		return tlsConn, nil
+	}
+}
+
 // Name implements Server.
 func (s *TCPNameServer) Name() string {
 	return s.name
 }
 
+// Close implements common.Closable.
+func (s *TCPNameServer) Close() error {
+	return s.cleanup.Close()
+}
+
 // Cleanup clears expired items from cache
 func (s *TCPNameServer) Cleanup() error {
 	now := time.Now()
@@ -258,7 +322,7 @@ func (s *TCPNameServer) sendQuery(ctx context.Context, domain string, clientIP n
 
 			rec, err := parseResponse(respBuf.Bytes())
 			if err != nil {
-				newError("failed to parse DNS over TCP response").Base(err).AtError().WriteToLog()
+				newError("failed to parse ", s.name, " response").Base(err).AtError().WriteToLog()
 				return
 			}
 
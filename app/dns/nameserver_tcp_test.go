@@ -58,3 +58,42 @@ func TestTCPLocalNameServerWithCache(t *testing.T) {
 		t.Fatal(r)
 	}
 }
+
+func TestTLSLocalNameServer(t *testing.T) {
+	url, err := url.Parse("tls+local://1.1.1.1")
+	common.Must(err)
+	s, err := NewTLSLocalNameServer(url)
+	common.Must(err)
+	if s.Name() != "DOTL//1.1.1.1:853" {
+		t.Error("unexpected name: ", s.Name())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	ips, err := s.QueryIP(ctx, "google.com", net.IP(nil), dns_feature.IPOption{
+		IPv4Enable: true,
+		IPv6Enable: true,
+	}, false)
+	cancel()
+	common.Must(err)
+	if len(ips) == 0 {
+		t.Error("expect some ips, but got 0")
+	}
+}
+
+func TestNewServerForTLSAndQUIC(t *testing.T) {
+	testCases := []struct {
+		address string
+		name    string
+	}{
+		{address: "tls://1.1.1.1", name: "DOT//1.1.1.1:853"},
+		{address: "tls://dns.google:8853", name: "DOT//dns.google:8853"},
+		{address: "tls+local://1.1.1.1", name: "DOTL//1.1.1.1:853"},
+		{address: "quic://dns.adguard.com", name: "quic://dns.adguard.com"},
+	}
+	for _, tc := range testCases {
+		s, err := NewServer(net.Destination{Address: net.DomainAddress(tc.address)}, nil)
+		common.Must(err)
+		if s.Name() != tc.name {
+			t.Error("expect name ", tc.name, " for ", tc.address, ", but got ", s.Name())
+		}
+	}
+}
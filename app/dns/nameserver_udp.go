@@ -66,6 +66,12 @@ func (s *ClassicNameServer) Name() string {
 	return s.name
 }
 
+// Close implements common.Closable.
+func (s *ClassicNameServer) Close() error {
+	s.cleanup.Close()
+	return s.udpServer.Close()
+}
+
 // Cleanup clears expired items from cache
 func (s *ClassicNameServer) Cleanup() error {
 	now := time.Now()
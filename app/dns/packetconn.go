@@ -0,0 +1,113 @@
+package dns
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/transport"
+)
+
+var dispatchedPacketConnID uint32
+
+// dispatchedAddr is the local address of a dispatchedPacketConn.
+// quic-go multiplexes packet connections by their local addresses, so each of them has a distinct one.
+type dispatchedAddr struct {
+	id uint32
+}
+
+func (dispatchedAddr) Network() string {
+	return "dispatched"
+}
+
+func (a dispatchedAddr) String() string {
+	return "dispatched-" + strconv.FormatUint(uint64(a.id), 10)
+}
+
+// dispatchedPacketConn is a net.PacketConn over a UDP link of the dispatcher.
+// All packets are sent to and received from a single destination.
+type dispatchedPacketConn struct {
+	access sync.Mutex
+	cache  buf.MultiBuffer
+	link   *transport.Link
+	local  net.Addr
+	remote net.Addr
+}
+
+func newDispatchedPacketConn(link *transport.Link, dest net.Destination) *dispatchedPacketConn {
+	remote := &net.UDPAddr{Port: int(dest.Port)}
+	if dest.Address.Family().IsIP() {
+		remote.IP = dest.Address.IP()
+	}
+	return &dispatchedPacketConn{
+		link:   link,
+		local:  dispatchedAddr{id: atomic.AddUint32(&dispatchedPacketConnID, 1)},
+		remote: remote,
+	}
+}
+
+// ReadFrom implements net.PacketConn.
+func (c *dispatchedPacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	for len(c.cache) == 0 {
+		mb, err := c.link.Reader.ReadMultiBuffer()
+		if err != nil {
+			return 0, nil, err
+		}
+		c.cache = mb
+	}
+
+	var b *buf.Buffer
+	c.cache, b = buf.SplitFirst(c.cache)
+	if b == nil {
+		return 0, c.remote, nil
+	}
+	n := copy(p, b.Bytes())
+	b.Release()
+	return n, c.remote, nil
+}
+
+// WriteTo implements net.PacketConn.
+func (c *dispatchedPacketConn) WriteTo(p []byte, _ net.Addr) (int, error) {
+	if len(p) > buf.Size {
+		return 0, newError("packet of ", len(p), " bytes is too large")
+	}
+	b := buf.New()
+	b.Write(p)
+	if err := c.link.Writer.WriteMultiBuffer(buf.MultiBuffer{b}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close implements net.PacketConn.
+func (c *dispatchedPacketConn) Close() error {
+	common.Interrupt(c.link.Reader)
+	return common.Close(c.link.Writer)
+}
+
+// LocalAddr implements net.PacketConn.
+func (c *dispatchedPacketConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+// SetDeadline implements net.PacketConn.
+func (c *dispatchedPacketConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+// SetReadDeadline implements net.PacketConn.
+func (c *dispatchedPacketConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline implements net.PacketConn.
+func (c *dispatchedPacketConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
@@ -0,0 +1,53 @@
+package dns
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/transport"
+	"github.com/v2fly/v2ray-core/v5/transport/pipe"
+)
+
+func TestDispatchedPacketConn(t *testing.T) {
+	uplinkReader, uplinkWriter := pipe.New()
+	downlinkReader, downlinkWriter := pipe.New()
+	link := &transport.Link{Reader: downlinkReader, Writer: uplinkWriter}
+	dest := net.UDPDestination(net.LocalHostIP, 853)
+
+	conn := newDispatchedPacketConn(link, dest)
+	if conn.LocalAddr().String() == newDispatchedPacketConn(link, dest).LocalAddr().String() {
+		t.Error("expect distinct local addresses")
+	}
+
+	if _, err := conn.WriteTo([]byte("query"), nil); err != nil {
+		t.Fatal(err)
+	}
+	mb, err := uplinkReader.ReadMultiBuffer()
+	common.Must(err)
+	if len(mb) != 1 || mb[0].String() != "query" {
+		t.Error("unexpected packet: ", mb.String())
+	}
+	buf.ReleaseMulti(mb)
+
+	// Each buffer is read as a packet.
+	common.Must(downlinkWriter.WriteMultiBuffer(buf.MultiBuffer{buf.FromBytes([]byte("first")), buf.FromBytes([]byte("second"))}))
+	b := make([]byte, 64)
+	for _, expected := range []string{"first", "second"} {
+		n, addr, err := conn.ReadFrom(b)
+		common.Must(err)
+		if !bytes.Equal(b[:n], []byte(expected)) {
+			t.Error("expect ", expected, ", but got ", string(b[:n]))
+		}
+		if addr.String() != "127.0.0.1:853" {
+			t.Error("unexpected address: ", addr)
+		}
+	}
+
+	common.Must(conn.Close())
+	if _, _, err := conn.ReadFrom(b); err == nil {
+		t.Error("expect error after close")
+	}
+}
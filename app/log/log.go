@@ -49,7 +49,9 @@ func New(ctx context.Context, config *Config) (*Instance, error) {
 
 func (g *Instance) initAccessLogger() error {
 	handler, err := createHandler(g.config.Access.Type, HandlerCreatorOptions{
-		Path: g.config.Access.Path,
+		Path:     g.config.Access.Path,
+		Format:   g.config.Access.Format,
+		Rotation: g.config.Access.Rotation,
 	})
 	if err != nil {
 		return err
@@ -60,7 +62,9 @@ func (g *Instance) initAccessLogger() error {
 
 func (g *Instance) initErrorLogger() error {
 	handler, err := createHandler(g.config.Error.Type, HandlerCreatorOptions{
-		Path: g.config.Error.Path,
+		Path:     g.config.Error.Path,
+		Format:   g.config.Error.Format,
+		Rotation: g.config.Error.Rotation,
 	})
 	if err != nil {
 		return err
@@ -99,6 +103,23 @@ func (g *Instance) Start() error {
 	return g.startInternal()
 }
 
+// Reopen opens the log files again, so that the logs are written to new
+// files after the old ones are moved away. Messages that are not written yet
+// are kept.
+func (g *Instance) Reopen() error {
+	g.RLock()
+	defer g.RUnlock()
+
+	for _, handler := range []log.Handler{g.accessLogger, g.errorLogger} {
+		if reopener, ok := handler.(log.Reopener); ok {
+			if err := reopener.Reopen(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // AddFollower implements log.Follower.
 func (g *Instance) AddFollower(f func(msg log.Message)) {
 	g.Lock()
@@ -1,12 +1,16 @@
 package log
 
 import (
+	"time"
+
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/log"
 )
 
 type HandlerCreatorOptions struct {
-	Path string
+	Path     string
+	Format   LogFormat
+	Rotation *LogRotation
 }
 
 type HandlerCreator func(LogType, HandlerCreatorOptions) (log.Handler, error)
@@ -30,17 +34,35 @@ func createHandler(logType LogType, options HandlerCreatorOptions) (log.Handler,
 	return creator(logType, options)
 }
 
+func newLogger(creator log.WriterCreator, format LogFormat) log.Handler {
+	if format == LogFormat_JSON {
+		return log.NewJSONLogger(creator)
+	}
+	return log.NewLogger(creator)
+}
+
 func init() {
 	common.Must(RegisterHandlerCreator(LogType_Console, func(lt LogType, options HandlerCreatorOptions) (log.Handler, error) {
-		return log.NewLogger(log.CreateStdoutLogWriter()), nil
+		return newLogger(log.CreateStdoutLogWriter(), options.Format), nil
 	}))
 
 	common.Must(RegisterHandlerCreator(LogType_File, func(lt LogType, options HandlerCreatorOptions) (log.Handler, error) {
-		creator, err := log.CreateFileLogWriter(options.Path)
+		var creator log.WriterCreator
+		var err error
+		if r := options.Rotation; r != nil {
+			creator, err = log.CreateRotatingFileLogWriter(options.Path, log.RotationOptions{
+				MaxSize:    int64(r.MaxSize),
+				MaxAge:     time.Duration(r.MaxAge) * time.Second,
+				MaxBackups: int(r.MaxBackups),
+				Compress:   r.Compress,
+			})
+		} else {
+			creator, err = log.CreateFileLogWriter(options.Path)
+		}
 		if err != nil {
 			return nil, err
 		}
-		return log.NewLogger(creator), nil
+		return newLogger(creator, options.Format), nil
 	}))
 
 	common.Must(RegisterHandlerCreator(LogType_None, func(lt LogType, options HandlerCreatorOptions) (log.Handler, error) {
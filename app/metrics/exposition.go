@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+type metricFamily struct {
+	kind    string
+	help    string
+	samples []string
+}
+
+// exposition collects metrics and writes them in the Prometheus text format.
+type exposition struct {
+	families map[string]*metricFamily
+}
+
+func newExposition() *exposition {
+	return &exposition{families: make(map[string]*metricFamily)}
+}
+
+// add records a sample of the named metric. labels are pairs of label names
+// and values.
+func (e *exposition) add(name string, kind string, help string, value int64, labels ...string) {
+	family, found := e.families[name]
+	if !found {
+		family = &metricFamily{kind: kind, help: help}
+		e.families[name] = family
+	}
+
+	sb := new(strings.Builder)
+	sb.WriteString(name)
+	if len(labels) > 0 {
+		sb.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(labels[i])
+			sb.WriteString(`="`)
+			labelValueEscaper.WriteString(sb, labels[i+1])
+			sb.WriteByte('"')
+		}
+		sb.WriteByte('}')
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(strconv.FormatInt(value, 10))
+	family.samples = append(family.samples, sb.String())
+}
+
+func (e *exposition) WriteTo(w io.Writer) (int64, error) {
+	names := make([]string, 0, len(e.families))
+	for name := range e.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sb := new(strings.Builder)
+	for _, name := range names {
+		family := e.families[name]
+		sort.Strings(family.samples)
+		sb.WriteString("# HELP " + name + " " + family.help + "\n")
+		sb.WriteString("# TYPE " + name + " " + family.kind + "\n")
+		for _, sample := range family.samples {
+			sb.WriteString(sample)
+			sb.WriteByte('\n')
+		}
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
@@ -0,0 +1,198 @@
+package metrics
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/app/observatory"
+	"github.com/v2fly/v2ray-core/v5/app/stats"
+	statscmd "github.com/v2fly/v2ray-core/v5/app/stats/command"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/features/extension"
+	feature_stats "github.com/v2fly/v2ray-core/v5/features/stats"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+const defaultPath = "/metrics"
+
+// Metrics serves the stats counters, runtime statistics and observatory
+// results over HTTP in the Prometheus text format.
+type Metrics struct {
+	access   sync.Mutex
+	config   *Config
+	listener net.Listener
+
+	stats       feature_stats.Manager
+	statsServer statscmd.StatsServiceServer
+
+	instance *core.Instance
+	ctx      context.Context
+}
+
+// New creates a new metrics exporter.
+func New(ctx context.Context, config *Config) (*Metrics, error) {
+	m := &Metrics{
+		config:   config,
+		instance: core.MustFromContext(ctx),
+		ctx:      ctx,
+	}
+	if err := core.RequireFeatures(ctx, func(sm feature_stats.Manager) {
+		m.init(sm)
+	}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Metrics) init(sm feature_stats.Manager) {
+	m.stats = sm
+	m.statsServer = statscmd.NewStatsServer(sm)
+}
+
+// Type implements common.HasType.
+func (*Metrics) Type() interface{} {
+	return (*Metrics)(nil)
+}
+
+// Start implements common.Runnable.
+func (m *Metrics) Start() error {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	address := net.ParseAddress(m.config.ListenAddr)
+	var listener net.Listener
+	var err error
+	switch {
+	case address.Family().IsIP():
+		listener, err = internet.ListenSystem(m.ctx, &net.TCPAddr{IP: address.IP(), Port: int(m.config.ListenPort)}, nil)
+	case strings.EqualFold(address.Domain(), "localhost"):
+		listener, err = internet.ListenSystem(m.ctx, &net.TCPAddr{IP: net.IP{127, 0, 0, 1}, Port: int(m.config.ListenPort)}, nil)
+	default:
+		return newError("metrics cannot listen on the address: ", address)
+	}
+	if err != nil {
+		return newError("metrics cannot listen on the port ", m.config.ListenPort).Base(err)
+	}
+	m.listener = listener
+
+	path := m.config.Path
+	if path == "" {
+		path = defaultPath
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, m)
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			newError("stopped serving metrics").Base(err).AtInfo().WriteToLog()
+		}
+	}()
+	return nil
+}
+
+// Close implements common.Closable.
+func (m *Metrics) Close() error {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	if m.listener != nil {
+		return m.listener.Close()
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e := newExposition()
+	m.collectCounters(e)
+	m.collectSysStats(r.Context(), e)
+	m.collectObservation(r.Context(), e)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.WriteTo(w)
+}
+
+func (m *Metrics) collectCounters(e *exposition) {
+	manager, ok := m.stats.(*stats.Manager)
+	if !ok {
+		return
+	}
+	manager.VisitCounters(func(name string, c feature_stats.Counter) bool {
+		// Traffic counters are named like "inbound>>>tag>>>traffic>>>uplink".
+		parts := strings.Split(name, ">>>")
+		if len(parts) == 4 && parts[2] == "traffic" {
+			switch parts[0] {
+			case "inbound", "outbound":
+				e.add("v2ray_"+parts[0]+"_traffic_bytes_total", "counter", "Traffic of "+parts[0]+" handlers in bytes.", c.Value(),
+					"tag", parts[1], "direction", parts[3])
+				return true
+			case "user":
+				e.add("v2ray_user_traffic_bytes_total", "counter", "Traffic of users in bytes.", c.Value(),
+					"user", parts[1], "direction", parts[3])
+				return true
+			}
+		}
+		e.add("v2ray_counter", "untyped", "Other stats counters.", c.Value(), "name", name)
+		return true
+	})
+}
+
+func (m *Metrics) collectSysStats(ctx context.Context, e *exposition) {
+	if m.statsServer == nil {
+		return
+	}
+	s, err := m.statsServer.GetSysStats(ctx, &statscmd.SysStatsRequest{})
+	if err != nil {
+		return
+	}
+	e.add("v2ray_uptime_seconds", "gauge", "Time since V2Ray started in seconds.", int64(s.Uptime))
+	e.add("v2ray_goroutines", "gauge", "Number of goroutines.", int64(s.NumGoroutine))
+	e.add("v2ray_gc_cycles_total", "counter", "Number of completed GC cycles.", int64(s.NumGC))
+	e.add("v2ray_gc_pause_nanoseconds_total", "counter", "Total GC pause time in nanoseconds.", int64(s.PauseTotalNs))
+	e.add("v2ray_memory_alloc_bytes", "gauge", "Bytes of allocated heap objects.", int64(s.Alloc))
+	e.add("v2ray_memory_alloc_bytes_total", "counter", "Cumulative bytes allocated for heap objects.", int64(s.TotalAlloc))
+	e.add("v2ray_memory_sys_bytes", "gauge", "Bytes of memory obtained from the OS.", int64(s.Sys))
+	e.add("v2ray_memory_mallocs_total", "counter", "Cumulative count of heap objects allocated.", int64(s.Mallocs))
+	e.add("v2ray_memory_frees_total", "counter", "Cumulative count of heap objects freed.", int64(s.Frees))
+	e.add("v2ray_memory_live_objects", "gauge", "Number of live heap objects.", int64(s.LiveObjects))
+}
+
+func (m *Metrics) collectObservation(ctx context.Context, e *exposition) {
+	if m.instance == nil {
+		return
+	}
+	o, ok := m.instance.GetFeature(extension.ObservatoryType()).(extension.Observatory)
+	if !ok {
+		return
+	}
+	msg, err := o.GetObservation(ctx)
+	if err != nil {
+		newError("failed to get observation").Base(err).AtDebug().WriteToLog()
+		return
+	}
+	result, ok := msg.(*observatory.ObservationResult)
+	if !ok {
+		return
+	}
+	for _, status := range result.Status {
+		alive := int64(0)
+		if status.Alive {
+			alive = 1
+		}
+		e.add("v2ray_observatory_alive", "gauge", "Whether the outbound is alive according to the observatory.", alive,
+			"outbound", status.OutboundTag)
+		e.add("v2ray_observatory_delay_milliseconds", "gauge", "Delay of the outbound measured by the observatory.", status.Delay,
+			"outbound", status.OutboundTag)
+	}
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return New(ctx, config.(*Config))
+	}))
+}
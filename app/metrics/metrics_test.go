@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/app/stats"
+	"github.com/v2fly/v2ray-core/v5/common"
+)
+
+func TestServeMetrics(t *testing.T) {
+	m, err := stats.NewManager(context.Background(), &stats.Config{})
+	common.Must(err)
+	for name, value := range map[string]int64{
+		"inbound>>>api>>>traffic>>>uplink":         1,
+		"outbound>>>direct>>>traffic>>>downlink":   2,
+		"user>>>love@v2fly.org>>>traffic>>>uplink": 3,
+		"custom \"counter\"":                       4,
+	} {
+		c, err := m.RegisterCounter(name)
+		common.Must(err)
+		c.Set(value)
+	}
+
+	service := &Metrics{config: &Config{}}
+	service.init(m)
+
+	recorder := httptest.NewRecorder()
+	service.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatal("unexpected status code: ", recorder.Code)
+	}
+	body := recorder.Body.String()
+	for _, line := range []string{
+		"# TYPE v2ray_inbound_traffic_bytes_total counter",
+		`v2ray_inbound_traffic_bytes_total{tag="api",direction="uplink"} 1`,
+		`v2ray_outbound_traffic_bytes_total{tag="direct",direction="downlink"} 2`,
+		`v2ray_user_traffic_bytes_total{user="love@v2fly.org",direction="uplink"} 3`,
+		`v2ray_counter{name="custom \"counter\""} 4`,
+		"# TYPE v2ray_goroutines gauge",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Error("missing line: ", line, "\n", body)
+		}
+	}
+}
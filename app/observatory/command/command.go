@@ -25,6 +25,11 @@ type service struct {
 	observatory extension.Observatory
 }
 
+// NewObservatoryServer creates an ObservatoryService server backed by the given observatory.
+func NewObservatoryServer(observatory extension.Observatory) ObservatoryServiceServer {
+	return &service{observatory: observatory}
+}
+
 func (s *service) GetOutboundStatus(ctx context.Context, request *GetOutboundStatusRequest) (*GetOutboundStatusResponse, error) {
 	var result proto.Message
 	if request.Tag == "" {
@@ -0,0 +1,249 @@
+package filestorage
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/platform/filesystem"
+	"github.com/v2fly/v2ray-core/v5/features/extension"
+	"github.com/v2fly/v2ray-core/v5/features/extension/storage"
+)
+
+const (
+	// valueTag marks a key that holds a value within its scope.
+	valueTag byte = 'v'
+	// scopeTag marks a key that belongs to a nested scope.
+	scopeTag byte = 's'
+)
+
+// Storage is a persistent key value store backed by a single file.
+// Every modification rewrites the file atomically.
+type Storage struct {
+	access sync.RWMutex
+	path   string
+	values map[string][]byte
+
+	root *scopedStorage
+}
+
+// New loads the storage file named by config, if it exists.
+func New(ctx context.Context, config *Config) (*Storage, error) {
+	if config.Path == "" {
+		return nil, newError("storage file path is not specified")
+	}
+	s := &Storage{
+		path:   config.Path,
+		values: make(map[string][]byte),
+	}
+	s.root = &scopedStorage{storage: s}
+
+	data, err := os.ReadFile(config.Path)
+	switch {
+	case os.IsNotExist(err):
+		return s, nil
+	case err != nil:
+		return nil, newError("failed to read storage file ", config.Path).Base(err)
+	}
+	database := &Database{}
+	if err := proto.Unmarshal(data, database); err != nil {
+		return nil, newError("failed to parse storage file ", config.Path).Base(err)
+	}
+	for _, record := range database.Record {
+		s.values[string(record.Key)] = record.Value
+	}
+	return s, nil
+}
+
+// Type implements common.HasType.
+func (*Storage) Type() interface{} {
+	return extension.PersistentStorageEngineType()
+}
+
+// Start implements common.Runnable.
+func (*Storage) Start() error {
+	return nil
+}
+
+// Close implements common.Closable.
+func (*Storage) Close() error {
+	return nil
+}
+
+// PersistentStorageEngine implements extension.PersistentStorageEngine.
+func (*Storage) PersistentStorageEngine() {}
+
+// ScopedPersistentStorageEngine implements storage.ScopedPersistentStorage.
+func (*Storage) ScopedPersistentStorageEngine() {}
+
+// Put implements storage.ScopedPersistentStorage.
+func (s *Storage) Put(ctx context.Context, key []byte, value []byte) error {
+	return s.root.Put(ctx, key, value)
+}
+
+// Get implements storage.ScopedPersistentStorage.
+func (s *Storage) Get(ctx context.Context, key []byte) ([]byte, error) {
+	return s.root.Get(ctx, key)
+}
+
+// List implements storage.ScopedPersistentStorage.
+func (s *Storage) List(ctx context.Context, keyPrefix []byte) ([][]byte, error) {
+	return s.root.List(ctx, keyPrefix)
+}
+
+// Clear implements storage.ScopedPersistentStorage.
+func (s *Storage) Clear(ctx context.Context) {
+	s.root.Clear(ctx)
+}
+
+// NarrowScope implements storage.ScopedPersistentStorage.
+func (s *Storage) NarrowScope(ctx context.Context, key []byte) (storage.ScopedPersistentStorage, error) {
+	return s.root.NarrowScope(ctx, key)
+}
+
+// DropScope implements storage.ScopedPersistentStorage.
+func (s *Storage) DropScope(ctx context.Context, key []byte) error {
+	return s.root.DropScope(ctx, key)
+}
+
+// modify applies f to the values under lock and writes the result to disk.
+// The in-memory state is rolled back if the file cannot be written.
+func (s *Storage) modify(f func(values map[string][]byte) bool) error {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	values := make(map[string][]byte, len(s.values))
+	for k, v := range s.values {
+		values[k] = v
+	}
+	if !f(values) {
+		return nil
+	}
+	if err := s.flush(values); err != nil {
+		return err
+	}
+	s.values = values
+	return nil
+}
+
+func (s *Storage) flush(values map[string][]byte) error {
+	database := &Database{Record: make([]*Record, 0, len(values))}
+	for k, v := range values {
+		database.Record = append(database.Record, &Record{Key: []byte(k), Value: v})
+	}
+	sort.Slice(database.Record, func(i, j int) bool {
+		return bytes.Compare(database.Record[i].Key, database.Record[j].Key) < 0
+	})
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(database)
+	if err != nil {
+		return newError("failed to encode storage").Base(err)
+	}
+
+	if err := filesystem.WriteFileAtomic(s.path, data); err != nil {
+		return newError("failed to write storage file ", s.path).Base(err)
+	}
+	return nil
+}
+
+// scopedStorage is a view of Storage in which all keys share a common prefix.
+type scopedStorage struct {
+	storage *Storage
+	prefix  []byte
+}
+
+func (s *scopedStorage) valueKey(key []byte) string {
+	k := make([]byte, 0, len(s.prefix)+1+len(key))
+	k = append(k, s.prefix...)
+	k = append(k, valueTag)
+	return string(append(k, key...))
+}
+
+func (s *scopedStorage) scopePrefix(key []byte) []byte {
+	k := make([]byte, 0, len(s.prefix)+1+binary.MaxVarintLen64+len(key))
+	k = append(k, s.prefix...)
+	k = append(k, scopeTag)
+	k = binary.AppendUvarint(k, uint64(len(key)))
+	return append(k, key...)
+}
+
+func (s *scopedStorage) ScopedPersistentStorageEngine() {}
+
+func (s *scopedStorage) Put(ctx context.Context, key []byte, value []byte) error {
+	k := s.valueKey(key)
+	v := append([]byte(nil), value...)
+	return s.storage.modify(func(values map[string][]byte) bool {
+		values[k] = v
+		return true
+	})
+}
+
+func (s *scopedStorage) Get(ctx context.Context, key []byte) ([]byte, error) {
+	s.storage.access.RLock()
+	defer s.storage.access.RUnlock()
+	value, ok := s.storage.values[s.valueKey(key)]
+	if !ok {
+		return nil, newError("unable to find ", string(key))
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (s *scopedStorage) List(ctx context.Context, keyPrefix []byte) ([][]byte, error) {
+	s.storage.access.RLock()
+	defer s.storage.access.RUnlock()
+	prefix := s.valueKey(keyPrefix)
+	trim := len(s.prefix) + 1
+	var ret [][]byte
+	for k := range s.storage.values {
+		if strings.HasPrefix(k, prefix) {
+			ret = append(ret, []byte(k[trim:]))
+		}
+	}
+	return ret, nil
+}
+
+func (s *scopedStorage) Clear(ctx context.Context) {
+	prefix := s.valueKey(nil)
+	err := s.storage.modify(func(values map[string][]byte) bool {
+		return deletePrefix(values, prefix)
+	})
+	if err != nil {
+		newError("failed to clear storage scope").Base(err).AtWarning().WriteToLog()
+	}
+}
+
+func (s *scopedStorage) NarrowScope(ctx context.Context, key []byte) (storage.ScopedPersistentStorage, error) {
+	return &scopedStorage{storage: s.storage, prefix: s.scopePrefix(key)}, nil
+}
+
+func (s *scopedStorage) DropScope(ctx context.Context, key []byte) error {
+	prefix := string(s.scopePrefix(key))
+	return s.storage.modify(func(values map[string][]byte) bool {
+		return deletePrefix(values, prefix)
+	})
+}
+
+func deletePrefix(values map[string][]byte, prefix string) bool {
+	modified := false
+	for k := range values {
+		if strings.HasPrefix(k, prefix) {
+			delete(values, k)
+			modified = true
+		}
+	}
+	return modified
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return New(ctx, config.(*Config))
+	}))
+}
@@ -0,0 +1,76 @@
+package filestorage_test
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	. "github.com/v2fly/v2ray-core/v5/app/persistentstorage/filestorage"
+	"github.com/v2fly/v2ray-core/v5/common"
+)
+
+func TestStorageScopes(t *testing.T) {
+	ctx := context.Background()
+	config := &Config{Path: filepath.Join(t.TempDir(), "storage.db")}
+
+	s, err := New(ctx, config)
+	common.Must(err)
+
+	common.Must(s.Put(ctx, []byte("a"), []byte("root")))
+	scope, err := s.NarrowScope(ctx, []byte("a"))
+	common.Must(err)
+	common.Must(scope.Put(ctx, []byte("a"), []byte("scoped")))
+	common.Must(scope.Put(ctx, []byte("ab"), []byte("scoped2")))
+	nested, err := scope.NarrowScope(ctx, []byte("b"))
+	common.Must(err)
+	common.Must(nested.Put(ctx, []byte("c"), []byte("nested")))
+
+	// Reload from disk to make sure everything was persisted.
+	s, err = New(ctx, config)
+	common.Must(err)
+	scope, err = s.NarrowScope(ctx, []byte("a"))
+	common.Must(err)
+
+	value, err := s.Get(ctx, []byte("a"))
+	common.Must(err)
+	if r := cmp.Diff(string(value), "root"); r != "" {
+		t.Error(r)
+	}
+	value, err = scope.Get(ctx, []byte("a"))
+	common.Must(err)
+	if r := cmp.Diff(string(value), "scoped"); r != "" {
+		t.Error(r)
+	}
+
+	keys, err := scope.List(ctx, []byte("a"))
+	common.Must(err)
+	var names []string
+	for _, k := range keys {
+		names = append(names, string(k))
+	}
+	sort.Strings(names)
+	if r := cmp.Diff(names, []string{"a", "ab"}); r != "" {
+		t.Error(r)
+	}
+
+	scope.Clear(ctx)
+	if _, err := scope.Get(ctx, []byte("a")); err == nil {
+		t.Error("expected value to be cleared")
+	}
+	nested, err = scope.NarrowScope(ctx, []byte("b"))
+	common.Must(err)
+	if _, err := nested.Get(ctx, []byte("c")); err != nil {
+		t.Error("nested scope should survive Clear: ", err)
+	}
+
+	common.Must(s.DropScope(ctx, []byte("a")))
+	if _, err := nested.Get(ctx, []byte("c")); err == nil {
+		t.Error("expected nested scope to be dropped")
+	}
+	if _, err := s.Get(ctx, []byte("a")); err != nil {
+		t.Error("root value should survive DropScope: ", err)
+	}
+}
@@ -0,0 +1,266 @@
+package policy
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+	"github.com/v2fly/v2ray-core/v5/features/policy"
+)
+
+// limit is the effective usage limit of a user.
+type limit struct {
+	quota          uint64
+	quotaWindow    time.Duration
+	uplinkRate     uint64
+	downlinkRate   uint64
+	maxConnections uint32
+	maxIPs         uint32
+}
+
+// toLimit returns nil if the limit does not restrict anything.
+func (l *Policy_Limit) toLimit() *limit {
+	if l == nil || (l.Quota == 0 && l.UplinkRate == 0 && l.DownlinkRate == 0 && l.MaxConnections == 0 && l.MaxIps == 0) {
+		return nil
+	}
+	return &limit{
+		quota:          l.Quota,
+		quotaWindow:    l.QuotaWindow.Duration(),
+		uplinkRate:     l.UplinkRate,
+		downlinkRate:   l.DownlinkRate,
+		maxConnections: l.MaxConnections,
+		maxIPs:         l.MaxIps,
+	}
+}
+
+// rateLimiter is a token bucket that holds up to one second worth of traffic.
+type rateLimiter struct {
+	last   time.Time
+	tokens float64
+}
+
+// reserve takes n bytes from the bucket and returns how long the caller has
+// to wait before sending them.
+func (r *rateLimiter) reserve(now time.Time, rate uint64, n int64) time.Duration {
+	if rate == 0 {
+		return 0
+	}
+	burst := float64(rate)
+	if r.last.IsZero() {
+		r.tokens = burst
+	} else {
+		r.tokens += now.Sub(r.last).Seconds() * burst
+		if r.tokens > burst {
+			r.tokens = burst
+		}
+	}
+	r.last = now
+	r.tokens -= float64(n)
+	if r.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-r.tokens / burst * float64(time.Second))
+}
+
+// full returns true if the bucket has refilled since it was last used.
+func (r *rateLimiter) full(now time.Time, rate uint64) bool {
+	return rate == 0 || r.last.IsZero() || r.tokens+now.Sub(r.last).Seconds()*float64(rate) >= float64(rate)
+}
+
+// userUsage is the usage of a user across all of its connections.
+type userUsage struct {
+	sync.Mutex
+	connections uint32
+	ips         map[string]uint32
+	windowStart time.Time
+	used        uint64
+	exhausted   bool
+	uplink      rateLimiter
+	downlink    rateLimiter
+}
+
+// updateWindow starts a new quota window if the current one has elapsed.
+func (u *userUsage) updateWindow(now time.Time, window time.Duration) {
+	if u.windowStart.IsZero() {
+		u.windowStart = now
+		return
+	}
+	if window > 0 && now.Sub(u.windowStart) >= window {
+		u.windowStart = now
+		u.used = 0
+		u.exhausted = false
+	}
+}
+
+// idle returns true if the usage has nothing to carry over to the next connection of the user:
+// no traffic counted against the quota in the current window, and no rate limit to catch up with.
+func (u *userUsage) idle(now time.Time, l *limit) bool {
+	if l == nil {
+		return true
+	}
+	if l.quota > 0 && u.used > 0 && (l.quotaWindow == 0 || now.Sub(u.windowStart) < l.quotaWindow) {
+		return false
+	}
+	return u.uplink.full(now, l.uplinkRate) && u.downlink.full(now, l.downlinkRate)
+}
+
+func (u *userUsage) checkQuota(email string, l *limit) error {
+	if l.quota > 0 && u.used >= l.quota {
+		return newError("user ", email, " has exhausted the traffic quota of ", l.quota, " bytes")
+	}
+	return nil
+}
+
+type usageTracker struct {
+	ctx     context.Context
+	manager *Instance
+	email   string
+	level   uint32
+	ip      string
+	usage   *userUsage
+	closed  int32
+	done    *done.Instance
+}
+
+func (t *usageTracker) account(n int64, uplink bool) error {
+	l := t.manager.limitFor(t.email, t.level)
+	if l == nil {
+		return nil
+	}
+	now := time.Now()
+
+	u := t.usage
+	u.Lock()
+	u.updateWindow(now, l.quotaWindow)
+	if err := u.checkQuota(t.email, l); err != nil {
+		u.Unlock()
+		return err
+	}
+	u.used += uint64(n)
+	if l.quota > 0 && u.used >= l.quota && !u.exhausted {
+		u.exhausted = true
+		newError("user ", t.email, " has exhausted the traffic quota of ", l.quota, " bytes").AtWarning().WriteToLog()
+	}
+	var wait time.Duration
+	if uplink {
+		wait = u.uplink.reserve(now, l.uplinkRate, n)
+	} else {
+		wait = u.downlink.reserve(now, l.downlinkRate, n)
+	}
+	u.Unlock()
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-t.ctx.Done():
+			return newError("connection of user ", t.email, " closed").Base(t.ctx.Err())
+		case <-t.done.Wait():
+			return newError("connection of user ", t.email, " closed")
+		}
+	}
+	return nil
+}
+
+// Uplink implements policy.UsageTracker.
+func (t *usageTracker) Uplink(n int64) error {
+	return t.account(n, true)
+}
+
+// Downlink implements policy.UsageTracker.
+func (t *usageTracker) Downlink(n int64) error {
+	return t.account(n, false)
+}
+
+// Close implements common.Closable.
+func (t *usageTracker) Close() error {
+	if atomic.CompareAndSwapInt32(&t.closed, 0, 1) {
+		t.done.Close()
+		t.manager.release(t)
+	}
+	return nil
+}
+
+func (m *Instance) limitFor(email string, level uint32) *limit {
+	m.access.RLock()
+	defer m.access.RUnlock()
+	if l, found := m.userLimits[email]; found {
+		return l
+	}
+	return m.levelLimits[level]
+}
+
+// lockUsage returns the locked usage of a user. usageAccess is held while locking it, so that it cannot be
+// forgotten by release in between.
+func (m *Instance) lockUsage(email string) *userUsage {
+	m.usageAccess.Lock()
+	defer m.usageAccess.Unlock()
+	u, found := m.usages[email]
+	if !found {
+		u = &userUsage{ips: make(map[string]uint32)}
+		m.usages[email] = u
+	}
+	u.Lock()
+	return u
+}
+
+// release removes the connection of t from the usage of its user, and forgets the usage once it is idle.
+func (m *Instance) release(t *usageTracker) {
+	m.usageAccess.Lock()
+	defer m.usageAccess.Unlock()
+
+	u := t.usage
+	u.Lock()
+	defer u.Unlock()
+	u.connections--
+	if t.ip != "" {
+		u.ips[t.ip]--
+		if u.ips[t.ip] == 0 {
+			delete(u.ips, t.ip)
+		}
+	}
+	if u.connections == 0 && u.idle(time.Now(), m.limitFor(t.email, t.level)) && m.usages[t.email] == u {
+		delete(m.usages, t.email)
+	}
+}
+
+// TrackUser implements policy.UserLimiter.
+func (m *Instance) TrackUser(ctx context.Context, user *protocol.MemoryUser, ip string) (policy.UsageTracker, error) {
+	if user == nil || user.Email == "" {
+		return nil, nil
+	}
+	l := m.limitFor(user.Email, user.Level)
+	if l == nil {
+		return nil, nil
+	}
+
+	u := m.lockUsage(user.Email)
+	defer u.Unlock()
+	u.updateWindow(time.Now(), l.quotaWindow)
+	if err := u.checkQuota(user.Email, l); err != nil {
+		return nil, err
+	}
+	if l.maxConnections > 0 && u.connections >= l.maxConnections {
+		return nil, newError("user ", user.Email, " has reached the limit of ", l.maxConnections, " connections")
+	}
+	if _, found := u.ips[ip]; ip != "" && !found && l.maxIPs > 0 && uint32(len(u.ips)) >= l.maxIPs {
+		return nil, newError("user ", user.Email, " is already connected from ", len(u.ips), " IPs, refusing ", ip)
+	}
+	u.connections++
+	if ip != "" {
+		u.ips[ip]++
+	}
+	return &usageTracker{
+		ctx:     ctx,
+		manager: m,
+		email:   user.Email,
+		level:   user.Level,
+		ip:      ip,
+		usage:   u,
+		done:    done.New(),
+	}, nil
+}
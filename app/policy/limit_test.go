@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+)
+
+func TestRateLimitWaitEndsOnClose(t *testing.T) {
+	manager, err := New(context.Background(), &Config{
+		User: map[string]*Policy_Limit{
+			"slow@v2fly.org": {
+				UplinkRate: 1,
+			},
+		},
+	})
+	common.Must(err)
+
+	expectAborted := func(ctx context.Context, abort func(tracker io.Closer)) {
+		tracker, err := manager.TrackUser(ctx, &protocol.MemoryUser{Email: "slow@v2fly.org"}, "")
+		common.Must(err)
+		defer tracker.Close()
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- tracker.Uplink(3600)
+		}()
+		time.Sleep(100 * time.Millisecond)
+		abort(tracker)
+
+		select {
+		case err := <-errCh:
+			if err == nil {
+				t.Error("expect waiting to be aborted")
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("waiting is not aborted")
+		}
+	}
+
+	expectAborted(context.Background(), func(tracker io.Closer) { tracker.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	expectAborted(ctx, func(io.Closer) { cancel() })
+}
+
+func TestIdleUsageForgotten(t *testing.T) {
+	manager, err := New(context.Background(), &Config{
+		User: map[string]*Policy_Limit{
+			"device@v2fly.org": {
+				MaxIps: 1,
+			},
+			"quota@v2fly.org": {
+				Quota: 10,
+			},
+		},
+	})
+	common.Must(err)
+
+	usages := func() int {
+		manager.usageAccess.Lock()
+		defer manager.usageAccess.Unlock()
+		return len(manager.usages)
+	}
+
+	device, err := manager.TrackUser(context.Background(), &protocol.MemoryUser{Email: "device@v2fly.org"}, "10.0.0.1")
+	common.Must(err)
+	quota, err := manager.TrackUser(context.Background(), &protocol.MemoryUser{Email: "quota@v2fly.org"}, "")
+	common.Must(err)
+	common.Must(quota.Uplink(4))
+	if n := usages(); n != 2 {
+		t.Error("expect 2 usages, but got ", n)
+	}
+
+	common.Must(device.Close())
+	common.Must(quota.Close())
+	if n := usages(); n != 1 {
+		t.Error("expect only the quota usage to be kept, but got ", n)
+	}
+
+	quota, err = manager.TrackUser(context.Background(), &protocol.MemoryUser{Email: "quota@v2fly.org"}, "")
+	common.Must(err)
+	if err := quota.Uplink(8); err != nil {
+		t.Error(err)
+	}
+	if err := quota.Uplink(1); err == nil {
+		t.Error("expect the quota to be kept across connections")
+	}
+	common.Must(quota.Close())
+}
@@ -2,6 +2,7 @@ package policy
 
 import (
 	"context"
+	"sync"
 
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/features/policy"
@@ -9,27 +10,61 @@ import (
 
 // Instance is an instance of Policy manager.
 type Instance struct {
-	levels map[uint32]*Policy
-	system *SystemPolicy
+	access      sync.RWMutex
+	levels      map[uint32]*Policy
+	system      *SystemPolicy
+	levelLimits map[uint32]*limit
+	userLimits  map[string]*limit
+
+	usageAccess sync.Mutex
+	usages      map[string]*userUsage
 }
 
 // New creates new Policy manager instance.
 func New(ctx context.Context, config *Config) (*Instance, error) {
 	m := &Instance{
-		levels: make(map[uint32]*Policy),
-		system: config.System,
+		levels:      make(map[uint32]*Policy),
+		system:      config.System,
+		levelLimits: make(map[uint32]*limit),
+		userLimits:  make(map[string]*limit),
+		usages:      make(map[string]*userUsage),
 	}
 	if len(config.Level) > 0 {
 		for lv, p := range config.Level {
 			pp := defaultPolicy()
 			pp.overrideWith(p)
 			m.levels[lv] = pp
+			if l := pp.Limit.toLimit(); l != nil {
+				m.levelLimits[lv] = l
+			}
 		}
 	}
+	for email, l := range config.User {
+		m.userLimits[email] = l.toLimit()
+	}
 
 	return m, nil
 }
 
+// ReloadConfig implements features.ConfigReloader.
+func (m *Instance) ReloadConfig(config interface{}) error {
+	c, ok := config.(*Config)
+	if !ok {
+		return newError("unexpected config type")
+	}
+	n, err := New(context.Background(), c)
+	if err != nil {
+		return err
+	}
+	m.access.Lock()
+	defer m.access.Unlock()
+	m.levels = n.levels
+	m.system = n.system
+	m.levelLimits = n.levelLimits
+	m.userLimits = n.userLimits
+	return nil
+}
+
 // Type implements common.HasType.
 func (*Instance) Type() interface{} {
 	return policy.ManagerType()
@@ -37,6 +72,8 @@ func (*Instance) Type() interface{} {
 
 // ForLevel implements policy.Manager.
 func (m *Instance) ForLevel(level uint32) policy.Session {
+	m.access.RLock()
+	defer m.access.RUnlock()
 	if p, ok := m.levels[level]; ok {
 		return p.ToCorePolicy()
 	}
@@ -45,6 +82,8 @@ func (m *Instance) ForLevel(level uint32) policy.Session {
 
 // ForSystem implements policy.Manager.
 func (m *Instance) ForSystem() policy.System {
+	m.access.RLock()
+	defer m.access.RUnlock()
 	if m.system == nil {
 		return policy.System{}
 	}
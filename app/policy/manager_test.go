@@ -7,6 +7,7 @@ import (
 
 	. "github.com/v2fly/v2ray-core/v5/app/policy"
 	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/features/policy"
 )
 
@@ -43,3 +44,76 @@ func TestPolicy(t *testing.T) {
 		}
 	}
 }
+
+func TestUserLimit(t *testing.T) {
+	manager, err := New(context.Background(), &Config{
+		Level: map[uint32]*Policy{
+			0: {
+				Limit: &Policy_Limit{
+					MaxConnections: 1,
+				},
+			},
+		},
+		User: map[string]*Policy_Limit{
+			"quota@v2fly.org": {
+				Quota: 10,
+			},
+			"unlimited@v2fly.org": {},
+			"device@v2fly.org": {
+				MaxIps: 1,
+			},
+		},
+	})
+	common.Must(err)
+
+	levelUser := &protocol.MemoryUser{Email: "level@v2fly.org"}
+	tracker, err := manager.TrackUser(context.Background(), levelUser, "")
+	common.Must(err)
+	if _, err := manager.TrackUser(context.Background(), levelUser, ""); err == nil {
+		t.Error("expect connection limit to be enforced")
+	}
+	common.Must(tracker.Close())
+	common.Must(tracker.Close())
+	tracker, err = manager.TrackUser(context.Background(), levelUser, "")
+	common.Must(err)
+	common.Must(tracker.Close())
+
+	if tracker, err := manager.TrackUser(context.Background(), &protocol.MemoryUser{Email: "unlimited@v2fly.org"}, ""); err != nil || tracker != nil {
+		t.Error("expect no limit, but got ", tracker, err)
+	}
+
+	deviceUser := &protocol.MemoryUser{Email: "device@v2fly.org"}
+	tracker, err = manager.TrackUser(context.Background(), deviceUser, "10.0.0.1")
+	common.Must(err)
+	another, err := manager.TrackUser(context.Background(), deviceUser, "10.0.0.1")
+	common.Must(err)
+	if _, err := manager.TrackUser(context.Background(), deviceUser, "10.0.0.2"); err == nil {
+		t.Error("expect IP limit to be enforced")
+	}
+	common.Must(tracker.Close())
+	common.Must(another.Close())
+	tracker, err = manager.TrackUser(context.Background(), deviceUser, "10.0.0.2")
+	common.Must(err)
+	common.Must(tracker.Close())
+
+	quotaUser := &protocol.MemoryUser{Email: "quota@v2fly.org"}
+	tracker, err = manager.TrackUser(context.Background(), quotaUser, "")
+	common.Must(err)
+	common.Must(tracker.Uplink(6))
+	common.Must(tracker.Downlink(6))
+	if err := tracker.Uplink(1); err == nil {
+		t.Error("expect quota to be exhausted")
+	}
+	if _, err := manager.TrackUser(context.Background(), quotaUser, ""); err == nil {
+		t.Error("expect new connections to be refused")
+	}
+
+	common.Must(manager.ReloadConfig(&Config{
+		User: map[string]*Policy_Limit{
+			"quota@v2fly.org": {
+				Quota: 20,
+			},
+		},
+	}))
+	common.Must(tracker.Uplink(1))
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 
 	grpc "google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 
 	core "github.com/v2fly/v2ray-core/v5"
 	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/platform/filesystem"
 	"github.com/v2fly/v2ray-core/v5/common/serial"
 	"github.com/v2fly/v2ray-core/v5/features/inbound"
 	"github.com/v2fly/v2ray-core/v5/features/outbound"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/v2jsonpb"
 	"github.com/v2fly/v2ray-core/v5/proxy"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
 )
 
 // InboundOperation is the interface for operations that applies to inbound handlers.
@@ -19,6 +24,13 @@ type InboundOperation interface {
 	ApplyInbound(context.Context, inbound.Handler) error
 }
 
+// InboundConfigOperation is the interface for inbound operations that can be
+// recorded in the config of the inbound handler they are applied to.
+type InboundConfigOperation interface {
+	// ApplyInboundConfig applies this operation to the given inbound handler config.
+	ApplyInboundConfig(*core.InboundHandlerConfig) error
+}
+
 // OutboundOperation is the interface for operations that applies to outbound handlers.
 type OutboundOperation interface {
 	// ApplyOutbound applies this operation to the given outbound handler.
@@ -50,6 +62,15 @@ func (op *AddUserOperation) ApplyInbound(ctx context.Context, handler inbound.Ha
 	return um.AddUser(ctx, mUser)
 }
 
+// ApplyInboundConfig implements InboundConfigOperation.
+func (op *AddUserOperation) ApplyInboundConfig(config *core.InboundHandlerConfig) error {
+	return updateUsers(config, func(users protoreflect.List) error {
+		removeUser(users, op.User.Email)
+		users.Append(protoreflect.ValueOfMessage(proto.Clone(op.User).ProtoReflect()))
+		return nil
+	})
+}
+
 // ApplyInbound implements InboundOperation.
 func (op *RemoveUserOperation) ApplyInbound(ctx context.Context, handler inbound.Handler) error {
 	p, err := getInbound(handler)
@@ -63,22 +84,51 @@ func (op *RemoveUserOperation) ApplyInbound(ctx context.Context, handler inbound
 	return um.RemoveUser(ctx, op.Email)
 }
 
+// ApplyInboundConfig implements InboundConfigOperation.
+func (op *RemoveUserOperation) ApplyInboundConfig(config *core.InboundHandlerConfig) error {
+	return updateUsers(config, func(users protoreflect.List) error {
+		if !removeUser(users, op.Email) {
+			return newError("user not found: ", op.Email)
+		}
+		return nil
+	})
+}
+
 type handlerServer struct {
 	s   *core.Instance
 	ihm inbound.Manager
 	ohm outbound.Manager
+
+	runningConfigPath string
+}
+
+// NewHandlerServer creates a HandlerService server operating on the given V2Ray instance.
+func NewHandlerServer(v *core.Instance, config *Config) HandlerServiceServer {
+	hs := &handlerServer{
+		s:                 v,
+		runningConfigPath: config.GetRunningConfigPath(),
+	}
+	common.Must(v.RequireFeatures(func(im inbound.Manager, om outbound.Manager) {
+		hs.ihm = im
+		hs.ohm = om
+	}))
+	return hs
 }
 
 func (s *handlerServer) AddInbound(ctx context.Context, request *AddInboundRequest) (*AddInboundResponse, error) {
 	if err := core.AddInboundHandler(s.s, request.Inbound); err != nil {
 		return nil, err
 	}
-
+	s.saveRunningConfig()
 	return &AddInboundResponse{}, nil
 }
 
 func (s *handlerServer) RemoveInbound(ctx context.Context, request *RemoveInboundRequest) (*RemoveInboundResponse, error) {
-	return &RemoveInboundResponse{}, s.ihm.RemoveHandler(ctx, request.Tag)
+	if err := core.RemoveInboundHandler(s.s, request.Tag); err != nil {
+		return nil, err
+	}
+	s.saveRunningConfig()
+	return &RemoveInboundResponse{}, nil
 }
 
 func (s *handlerServer) AlterInbound(ctx context.Context, request *AlterInboundRequest) (*AlterInboundResponse, error) {
@@ -96,18 +146,32 @@ func (s *handlerServer) AlterInbound(ctx context.Context, request *AlterInboundR
 		return nil, newError("failed to get handler: ", request.Tag).Base(err)
 	}
 
-	return &AlterInboundResponse{}, operation.ApplyInbound(ctx, handler)
+	if err := operation.ApplyInbound(ctx, handler); err != nil {
+		return nil, err
+	}
+	if op, ok := operation.(InboundConfigOperation); ok {
+		if err := core.UpdateInboundHandlerConfig(s.s, request.Tag, op.ApplyInboundConfig); err != nil {
+			newError("failed to record operation on inbound ", request.Tag).Base(err).AtWarning().WriteToLog()
+		}
+		s.saveRunningConfig()
+	}
+	return &AlterInboundResponse{}, nil
 }
 
 func (s *handlerServer) AddOutbound(ctx context.Context, request *AddOutboundRequest) (*AddOutboundResponse, error) {
 	if err := core.AddOutboundHandler(s.s, request.Outbound); err != nil {
 		return nil, err
 	}
+	s.saveRunningConfig()
 	return &AddOutboundResponse{}, nil
 }
 
 func (s *handlerServer) RemoveOutbound(ctx context.Context, request *RemoveOutboundRequest) (*RemoveOutboundResponse, error) {
-	return &RemoveOutboundResponse{}, s.ohm.RemoveHandler(ctx, request.Tag)
+	if err := core.RemoveOutboundHandler(s.s, request.Tag); err != nil {
+		return nil, err
+	}
+	s.saveRunningConfig()
+	return &RemoveOutboundResponse{}, nil
 }
 
 func (s *handlerServer) AlterOutbound(ctx context.Context, request *AlterOutboundRequest) (*AlterOutboundResponse, error) {
@@ -124,26 +188,70 @@ func (s *handlerServer) AlterOutbound(ctx context.Context, request *AlterOutboun
 	return &AlterOutboundResponse{}, operation.ApplyOutbound(ctx, handler)
 }
 
+func (s *handlerServer) GetRunningConfig(ctx context.Context, request *GetRunningConfigRequest) (*GetRunningConfigResponse, error) {
+	config := s.s.RunningConfig()
+	jsonConfig, err := v2jsonpb.DumpV2JsonPb(config)
+	if err != nil {
+		return nil, newError("failed to encode running config").Base(err)
+	}
+	return &GetRunningConfigResponse{
+		Config:     config,
+		JsonConfig: jsonConfig,
+	}, nil
+}
+
+func (s *handlerServer) ReloadConfig(ctx context.Context, request *ReloadConfigRequest) (*ReloadConfigResponse, error) {
+	changes, err := s.s.ReloadConfig()
+	if len(changes) > 0 {
+		s.saveRunningConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ReloadConfigResponse{Change: changes}, nil
+}
+
+func (s *handlerServer) ListCertificates(ctx context.Context, request *ListCertificatesRequest) (*ListCertificatesResponse, error) {
+	return &ListCertificatesResponse{Certificate: tls.CertificateStatuses()}, nil
+}
+
+func (s *handlerServer) ReloadCertificates(ctx context.Context, request *ReloadCertificatesRequest) (*ReloadCertificatesResponse, error) {
+	if err := tls.ReloadCertificates(); err != nil {
+		return nil, err
+	}
+	return &ReloadCertificatesResponse{Certificate: tls.CertificateStatuses()}, nil
+}
+
+// saveRunningConfig writes the running config to the configured path, if any.
+// Failures are logged, as the change itself has already been applied.
+func (s *handlerServer) saveRunningConfig() {
+	if s.runningConfigPath == "" {
+		return
+	}
+	jsonConfig, err := v2jsonpb.DumpV2JsonPb(s.s.RunningConfig())
+	if err != nil {
+		newError("failed to encode running config").Base(err).AtError().WriteToLog()
+		return
+	}
+	if err := filesystem.WriteFileAtomic(s.runningConfigPath, jsonConfig); err != nil {
+		newError("failed to save running config to ", s.runningConfigPath).Base(err).AtError().WriteToLog()
+	}
+}
+
 func (s *handlerServer) mustEmbedUnimplementedHandlerServiceServer() {}
 
 type service struct {
-	v *core.Instance
+	v      *core.Instance
+	config *Config
 }
 
 func (s *service) Register(server *grpc.Server) {
-	hs := &handlerServer{
-		s: s.v,
-	}
-	common.Must(s.v.RequireFeatures(func(im inbound.Manager, om outbound.Manager) {
-		hs.ihm = im
-		hs.ohm = om
-	}))
-	RegisterHandlerServiceServer(server, hs)
+	RegisterHandlerServiceServer(server, NewHandlerServer(s.v, s.config))
 }
 
 func init() {
 	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, cfg interface{}) (interface{}, error) {
 		s := core.MustFromContext(ctx)
-		return &service{v: s}, nil
+		return &service{v: s, config: cfg.(*Config)}, nil
 	}))
 }
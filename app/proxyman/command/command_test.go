@@ -0,0 +1,80 @@
+package command_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/app/dispatcher"
+	"github.com/v2fly/v2ray-core/v5/app/policy"
+	"github.com/v2fly/v2ray-core/v5/app/proxyman"
+	. "github.com/v2fly/v2ray-core/v5/app/proxyman/command"
+	_ "github.com/v2fly/v2ray-core/v5/app/proxyman/inbound"
+	_ "github.com/v2fly/v2ray-core/v5/app/proxyman/outbound"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/cmdarg"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/v2jsonpb"
+	"github.com/v2fly/v2ray-core/v5/proxy/blackhole"
+	"github.com/v2fly/v2ray-core/v5/proxy/freedom"
+)
+
+func TestRunningConfigLoadsBack(t *testing.T) {
+	v, err := core.New(&core.Config{
+		App: []*anypb.Any{
+			serial.ToTypedMessage(&dispatcher.Config{}),
+			serial.ToTypedMessage(&proxyman.InboundConfig{}),
+			serial.ToTypedMessage(&proxyman.OutboundConfig{}),
+			serial.ToTypedMessage(&policy.Config{}),
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				Tag:           "direct",
+				ProxySettings: serial.ToTypedMessage(&freedom.Config{}),
+			},
+		},
+	})
+	common.Must(err)
+
+	path := filepath.Join(t.TempDir(), "running.v2pbjson")
+	server := NewHandlerServer(v, &Config{RunningConfigPath: path})
+	common.Must2(server.AddOutbound(context.Background(), &AddOutboundRequest{
+		Outbound: &core.OutboundHandlerConfig{
+			Tag:           "block",
+			ProxySettings: serial.ToTypedMessage(&blackhole.Config{}),
+		},
+	}))
+
+	response, err := server.GetRunningConfig(context.Background(), &GetRunningConfigRequest{})
+	common.Must(err)
+	if len(response.Config.Outbound) != 2 {
+		t.Fatal("unexpected outbounds: ", response.Config.Outbound)
+	}
+
+	// Both the saved file and the response load back in the v2jsonpb format.
+	// The file is loaded like `run -c running.v2pbjson` does, which picks the format by its extension.
+	saved, err := core.LoadConfig(core.FormatAuto, cmdarg.Arg{path})
+	common.Must(err)
+	if r := cmp.Diff(saved, response.Config, protocmp.Transform()); r != "" {
+		t.Error(r)
+	}
+	loaded, err := core.LoadConfig(v2jsonpb.FormatProtobufV2JSONPB, response.JsonConfig)
+	common.Must(err)
+	if r := cmp.Diff(loaded, response.Config, protocmp.Transform()); r != "" {
+		t.Error(r)
+	}
+
+	// An instance started from the saved config runs the same config.
+	restarted, err := core.New(saved)
+	common.Must(err)
+	common.Must(restarted.Start())
+	defer restarted.Close()
+	if r := cmp.Diff(restarted.RunningConfig(), response.Config, protocmp.Transform()); r != "" {
+		t.Error(r)
+	}
+}
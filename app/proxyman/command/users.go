@@ -0,0 +1,53 @@
+package command
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+)
+
+var userMessageName = (&protocol.User{}).ProtoReflect().Descriptor().FullName()
+
+// updateUsers calls update with the user list in the proxy settings of an
+// inbound handler config, and stores the modified settings back.
+// The user list is located by looking for the repeated protocol.User field
+// of the proxy config, so any protocol following this convention is supported.
+func updateUsers(config *core.InboundHandlerConfig, update func(protoreflect.List) error) error {
+	settings, err := serial.GetInstanceOf(config.ProxySettings)
+	if err != nil {
+		return newError("failed to decode proxy settings").Base(err)
+	}
+	message := settings.(protoreflect.ProtoMessage).ProtoReflect()
+
+	fields := message.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.IsList() && field.Message() != nil && field.Message().FullName() == userMessageName {
+			if err := update(message.Mutable(field).List()); err != nil {
+				return err
+			}
+			config.ProxySettings = serial.ToTypedMessage(settings)
+			return nil
+		}
+	}
+	return newError("proxy settings ", message.Descriptor().FullName(), " has no user list")
+}
+
+// removeUser removes all users with the given email from the list.
+func removeUser(users protoreflect.List, email string) bool {
+	removed := false
+	kept := 0
+	for i := 0; i < users.Len(); i++ {
+		user := users.Get(i)
+		if user.Message().Interface().(*protocol.User).Email == email {
+			removed = true
+			continue
+		}
+		users.Set(kept, user)
+		kept++
+	}
+	users.Truncate(kept)
+	return removed
+}
@@ -0,0 +1,42 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	. "github.com/v2fly/v2ray-core/v5/app/proxyman/command"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/proxy/vmess"
+	"github.com/v2fly/v2ray-core/v5/proxy/vmess/inbound"
+)
+
+func TestUserOperationsUpdateConfig(t *testing.T) {
+	user := func(email string) *protocol.User {
+		return &protocol.User{
+			Email:   email,
+			Account: serial.ToTypedMessage(&vmess.Account{Id: "b831381d-6324-4d53-ad4f-8cda48b30811"}),
+		}
+	}
+	config := &core.InboundHandlerConfig{
+		Tag:           "in",
+		ProxySettings: serial.ToTypedMessage(&inbound.Config{User: []*protocol.User{user("a@v2fly.org")}}),
+	}
+
+	common.Must((&AddUserOperation{User: user("b@v2fly.org")}).ApplyInboundConfig(config))
+	common.Must((&AddUserOperation{User: user("a@v2fly.org")}).ApplyInboundConfig(config))
+	common.Must((&RemoveUserOperation{Email: "b@v2fly.org"}).ApplyInboundConfig(config))
+	if err := (&RemoveUserOperation{Email: "c@v2fly.org"}).ApplyInboundConfig(config); err == nil {
+		t.Error("expected error when removing unknown user")
+	}
+
+	settings, err := serial.GetInstanceOf(config.ProxySettings)
+	common.Must(err)
+	if r := cmp.Diff(settings.(*inbound.Config).User, []*protocol.User{user("a@v2fly.org")}, protocmp.Transform()); r != "" {
+		t.Error(r)
+	}
+}
@@ -54,6 +54,24 @@ func getTProxyType(s *internet.MemoryStreamConfig) internet.SocketConfig_TProxyM
 	return s.SocketSettings.Tproxy
 }
 
+// setTransportPath records the path that conn is accepted on, so that routing can match it,
+// and the user of the path, which the proxy replaces if it authenticates users itself.
+func setTransportPath(inbound *session.Inbound, content *session.Content, conn internet.Connection) {
+	pathConn, ok := conn.(internet.PathConnection)
+	if !ok {
+		return
+	}
+	inbound.Path = pathConn.Path()
+	inbound.PathTag = pathConn.PathTag()
+	if user := pathConn.PathUser(); user != nil {
+		inbound.User = user
+	}
+	content.SetAttribute("transportPath", inbound.Path)
+	if inbound.PathTag != "" {
+		content.SetAttribute("transportPathTag", inbound.PathTag)
+	}
+}
+
 func (w *tcpWorker) callback(conn internet.Connection) {
 	ctx, cancel := context.WithCancel(w.ctx)
 	sid := session.NewID()
@@ -78,13 +96,15 @@ func (w *tcpWorker) callback(conn internet.Connection) {
 			})
 		}
 	}
-	ctx = session.ContextWithInbound(ctx, &session.Inbound{
+	inbound := &session.Inbound{
 		Source:  net.DestinationFromAddr(conn.RemoteAddr()),
 		Gateway: net.TCPDestination(w.address, w.port),
 		Tag:     w.tag,
 		Conn:    conn,
-	})
+	}
+	ctx = session.ContextWithInbound(ctx, inbound)
 	content := new(session.Content)
+	setTransportPath(inbound, content, conn)
 	if w.sniffingConfig != nil {
 		content.SniffingRequest.Enabled = w.sniffingConfig.Enabled
 		content.SniffingRequest.OverrideDestinationForProtocol = w.sniffingConfig.DestinationOverride
@@ -446,13 +466,15 @@ func (w *dsWorker) callback(conn internet.Connection) {
 	sid := session.NewID()
 	ctx = session.ContextWithID(ctx, sid)
 
-	ctx = session.ContextWithInbound(ctx, &session.Inbound{
+	inbound := &session.Inbound{
 		Source:  net.DestinationFromAddr(conn.RemoteAddr()),
 		Gateway: net.UnixDestination(w.address),
 		Tag:     w.tag,
 		Conn:    conn,
-	})
+	}
+	ctx = session.ContextWithInbound(ctx, inbound)
 	content := new(session.Content)
+	setTransportPath(inbound, content, conn)
 	if w.sniffingConfig != nil {
 		content.SniffingRequest.Enabled = w.sniffingConfig.Enabled
 		content.SniffingRequest.OverrideDestinationForProtocol = w.sniffingConfig.DestinationOverride
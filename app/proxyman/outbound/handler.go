@@ -2,6 +2,7 @@ package outbound
 
 import (
 	"context"
+	"time"
 
 	core "github.com/v2fly/v2ray-core/v5"
 	"github.com/v2fly/v2ray-core/v5/app/proxyman"
@@ -111,6 +112,10 @@ func NewHandler(ctx context.Context, config *core.OutboundHandlerConfig) (outbou
 		if config.Concurrency < 1 || config.Concurrency > 1024 {
 			return nil, newError("invalid mux concurrency: ", config.Concurrency).AtWarning()
 		}
+		maxReuse := config.MaxReuse
+		if maxReuse == 0 {
+			maxReuse = 128
+		}
 		h.mux = &mux.ClientManager{
 			Enabled: h.senderSettings.MultiplexSettings.Enabled,
 			Picker: &mux.IncrementalWorkerPicker{
@@ -120,9 +125,12 @@ func NewHandler(ctx context.Context, config *core.OutboundHandlerConfig) (outbou
 					h,
 					mux.ClientStrategy{
 						MaxConcurrency: config.Concurrency,
-						MaxConnection:  128,
+						MaxConnection:  maxReuse,
+						MaxBytes:       config.MaxBytes,
+						IdleTimeout:    time.Duration(config.IdleTimeout) * time.Second,
 					},
 				),
+				MaxWorkers: config.MaxConnections,
 			},
 		}
 	}
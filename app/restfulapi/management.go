@@ -0,0 +1,330 @@
+package restfulapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	observatorycmd "github.com/v2fly/v2ray-core/v5/app/observatory/command"
+	proxymancmd "github.com/v2fly/v2ray-core/v5/app/proxyman/command"
+	routercmd "github.com/v2fly/v2ray-core/v5/app/router/command"
+	statscmd "github.com/v2fly/v2ray-core/v5/app/stats/command"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/features/extension"
+)
+
+// maxRequestBodySize limits the size of a JSON encoded request message.
+const maxRequestBodySize = 1 << 20
+
+var (
+	unmarshalOptions = protojson.UnmarshalOptions{}
+	marshalOptions   = protojson.MarshalOptions{EmitUnpopulated: true}
+)
+
+// decodeMessage reads the protojson encoded request body into message.
+// An empty body leaves message untouched.
+func decodeMessage(w http.ResponseWriter, r *http.Request, message proto.Message) bool {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	if err == nil && len(body) > 0 {
+		err = unmarshalOptions.Unmarshal(body, message)
+	}
+	if err != nil {
+		renderError(w, r, http.StatusBadRequest, newError("malformed request body").Base(err))
+		return false
+	}
+	return true
+}
+
+// renderMessage writes the result of a service call as protojson.
+func renderMessage(w http.ResponseWriter, r *http.Request, message proto.Message, err error) {
+	if err != nil {
+		renderError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	data, err := marshalOptions.Marshal(message)
+	if err != nil {
+		renderError(w, r, http.StatusInternalServerError, newError("failed to encode response").Base(err))
+		return
+	}
+	render.JSON(w, r, json.RawMessage(data))
+}
+
+func renderError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	render.Status(r, status)
+	render.JSON(w, r, render.M{"error": err.Error()})
+}
+
+// queryBool parses an optional boolean query parameter.
+func queryBool(w http.ResponseWriter, r *http.Request, key string) (bool, bool) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return false, true
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.JSON(w, r, render.M{})
+		return false, false
+	}
+	return b, true
+}
+
+// urlParam returns the unescaped value of a path parameter.
+func urlParam(w http.ResponseWriter, r *http.Request, key string) (string, bool) {
+	value, err := url.PathUnescape(chi.URLParam(r, key))
+	if err != nil || validate.Var(value, "required,min=1,max=255") != nil {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.JSON(w, r, render.M{})
+		return "", false
+	}
+	return value, true
+}
+
+// queryReset returns the "reset" query parameter. Resetting counters changes the instance,
+// so it is refused if the auth token is not set.
+func (rs *restfulService) queryReset(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	reset, ok := queryBool(w, r, "reset")
+	if !ok {
+		return false, false
+	}
+	if reset && rs.config.AuthToken == "" {
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, render.M{})
+		return false, false
+	}
+	return reset, true
+}
+
+func (rs *restfulService) queryStats(w http.ResponseWriter, r *http.Request) {
+	regexp, ok := queryBool(w, r, "regexp")
+	if !ok {
+		return
+	}
+	reset, ok := rs.queryReset(w, r)
+	if !ok {
+		return
+	}
+	response, err := rs.statsServer.QueryStats(r.Context(), &statscmd.QueryStatsRequest{
+		Patterns: r.URL.Query()["pattern"],
+		Regexp:   regexp,
+		Reset_:   reset,
+	})
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) getStats(w http.ResponseWriter, r *http.Request) {
+	name, ok := urlParam(w, r, "name")
+	if !ok {
+		return
+	}
+	reset, ok := rs.queryReset(w, r)
+	if !ok {
+		return
+	}
+	if rs.stats.GetCounter(name) == nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, render.M{})
+		return
+	}
+	response, err := rs.statsServer.GetStats(r.Context(), &statscmd.GetStatsRequest{
+		Name:   name,
+		Reset_: reset,
+	})
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) sysStats(w http.ResponseWriter, r *http.Request) {
+	response, err := rs.statsServer.GetSysStats(r.Context(), &statscmd.SysStatsRequest{})
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) userOnlineIPs(w http.ResponseWriter, r *http.Request) {
+	email, ok := urlParam(w, r, "email")
+	if !ok {
+		return
+	}
+	response, err := rs.statsServer.GetUserOnlineIPs(r.Context(), &statscmd.GetUserOnlineIPsRequest{Email: email})
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) addInbound(w http.ResponseWriter, r *http.Request) {
+	request := &proxymancmd.AddInboundRequest{}
+	if !decodeMessage(w, r, request) {
+		return
+	}
+	response, err := rs.handlerServer.AddInbound(r.Context(), request)
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) removeInbound(w http.ResponseWriter, r *http.Request) {
+	tag, ok := urlParam(w, r, "tag")
+	if !ok {
+		return
+	}
+	response, err := rs.handlerServer.RemoveInbound(r.Context(), &proxymancmd.RemoveInboundRequest{Tag: tag})
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) alterInbound(w http.ResponseWriter, r *http.Request) {
+	tag, ok := urlParam(w, r, "tag")
+	if !ok {
+		return
+	}
+	request := &proxymancmd.AlterInboundRequest{}
+	if !decodeMessage(w, r, request) {
+		return
+	}
+	request.Tag = tag
+	response, err := rs.handlerServer.AlterInbound(r.Context(), request)
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) addInboundUser(w http.ResponseWriter, r *http.Request) {
+	tag, ok := urlParam(w, r, "tag")
+	if !ok {
+		return
+	}
+	user := &protocol.User{}
+	if !decodeMessage(w, r, user) {
+		return
+	}
+	response, err := rs.handlerServer.AlterInbound(r.Context(), &proxymancmd.AlterInboundRequest{
+		Tag:       tag,
+		Operation: serial.ToTypedMessage(&proxymancmd.AddUserOperation{User: user}),
+	})
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) removeInboundUser(w http.ResponseWriter, r *http.Request) {
+	tag, ok := urlParam(w, r, "tag")
+	if !ok {
+		return
+	}
+	email, ok := urlParam(w, r, "email")
+	if !ok {
+		return
+	}
+	response, err := rs.handlerServer.AlterInbound(r.Context(), &proxymancmd.AlterInboundRequest{
+		Tag:       tag,
+		Operation: serial.ToTypedMessage(&proxymancmd.RemoveUserOperation{Email: email}),
+	})
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) addOutbound(w http.ResponseWriter, r *http.Request) {
+	request := &proxymancmd.AddOutboundRequest{}
+	if !decodeMessage(w, r, request) {
+		return
+	}
+	response, err := rs.handlerServer.AddOutbound(r.Context(), request)
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) removeOutbound(w http.ResponseWriter, r *http.Request) {
+	tag, ok := urlParam(w, r, "tag")
+	if !ok {
+		return
+	}
+	response, err := rs.handlerServer.RemoveOutbound(r.Context(), &proxymancmd.RemoveOutboundRequest{Tag: tag})
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) alterOutbound(w http.ResponseWriter, r *http.Request) {
+	tag, ok := urlParam(w, r, "tag")
+	if !ok {
+		return
+	}
+	request := &proxymancmd.AlterOutboundRequest{}
+	if !decodeMessage(w, r, request) {
+		return
+	}
+	request.Tag = tag
+	response, err := rs.handlerServer.AlterOutbound(r.Context(), request)
+	renderMessage(w, r, response, err)
+}
+
+// runningConfig responds with the running config in the v2jsonpb format, see Config.RunningConfigPath.
+func (rs *restfulService) runningConfig(w http.ResponseWriter, r *http.Request) {
+	response, err := rs.handlerServer.GetRunningConfig(r.Context(), &proxymancmd.GetRunningConfigRequest{})
+	if err != nil {
+		renderError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	render.JSON(w, r, json.RawMessage(response.JsonConfig))
+}
+
+func (rs *restfulService) reloadConfig(w http.ResponseWriter, r *http.Request) {
+	response, err := rs.handlerServer.ReloadConfig(r.Context(), &proxymancmd.ReloadConfigRequest{})
+	if err != nil {
+		renderError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	renderMessage(w, r, response, nil)
+}
+
+func (rs *restfulService) certificates(w http.ResponseWriter, r *http.Request) {
+	response, err := rs.handlerServer.ListCertificates(r.Context(), &proxymancmd.ListCertificatesRequest{})
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) reloadCertificates(w http.ResponseWriter, r *http.Request) {
+	response, err := rs.handlerServer.ReloadCertificates(r.Context(), &proxymancmd.ReloadCertificatesRequest{})
+	if err != nil {
+		renderError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	renderMessage(w, r, response, nil)
+}
+
+func (rs *restfulService) testRoute(w http.ResponseWriter, r *http.Request) {
+	request := &routercmd.TestRouteRequest{}
+	if !decodeMessage(w, r, request) {
+		return
+	}
+	response, err := rs.routingServer.TestRoute(r.Context(), request)
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) balancerInfo(w http.ResponseWriter, r *http.Request) {
+	tag, ok := urlParam(w, r, "tag")
+	if !ok {
+		return
+	}
+	response, err := rs.routingServer.GetBalancerInfo(r.Context(), &routercmd.GetBalancerInfoRequest{Tag: tag})
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) overrideBalancer(w http.ResponseWriter, r *http.Request) {
+	tag, ok := urlParam(w, r, "tag")
+	if !ok {
+		return
+	}
+	request := &routercmd.OverrideBalancerTargetRequest{}
+	if !decodeMessage(w, r, request) {
+		return
+	}
+	request.BalancerTag = tag
+	response, err := rs.routingServer.OverrideBalancerTarget(r.Context(), request)
+	renderMessage(w, r, response, err)
+}
+
+func (rs *restfulService) observatoryStatus(w http.ResponseWriter, r *http.Request) {
+	observatory, ok := rs.instance.GetFeature(extension.ObservatoryType()).(extension.Observatory)
+	if !ok {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, render.M{})
+		return
+	}
+	response, err := observatorycmd.NewObservatoryServer(observatory).GetOutboundStatus(r.Context(), &observatorycmd.GetOutboundStatusRequest{
+		Tag: r.URL.Query().Get("tag"),
+	})
+	renderMessage(w, r, response, err)
+}
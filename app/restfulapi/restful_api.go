@@ -57,7 +57,7 @@ func (rs *restfulService) TokenAuthMiddleware(next http.Handler) http.Handler {
 		text := strings.SplitN(header, " ", 2)
 
 		hasInvalidHeader := text[0] != "Bearer"
-		hasInvalidSecret := len(text) != 2 || text[1] != rs.config.AuthToken
+		hasInvalidSecret := rs.config.AuthToken == "" || len(text) != 2 || text[1] != rs.config.AuthToken
 		if hasInvalidHeader || hasInvalidSecret {
 			render.Status(r, http.StatusUnauthorized)
 			render.JSON(w, r, render.M{})
@@ -68,15 +68,57 @@ func (rs *restfulService) TokenAuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func (rs *restfulService) start() error {
+func (rs *restfulService) router() http.Handler {
 	r := chi.NewRouter()
 	r.Use(middleware.Heartbeat("/ping"))
 
 	validate = validator.New()
 	r.Route("/v1", func(r chi.Router) {
+		if rs.config.AuthToken != "" {
+			r.Use(rs.TokenAuthMiddleware)
+		}
+
 		r.Get("/{bound_type}/{tag}/stats", rs.tagStats)
+
+		r.Get("/stats", rs.queryStats)
+		r.Get("/stats/{name}", rs.getStats)
+		r.Get("/sys/stats", rs.sysStats)
+		r.Post("/routing/test", rs.testRoute)
+		r.Get("/balancers/{tag}", rs.balancerInfo)
+		r.Get("/observatory", rs.observatoryStatus)
+
+		// Requests that change the instance or expose users and credentials always require the token,
+		// and are refused if it is not set.
+		r.Group(func(r chi.Router) {
+			if rs.config.AuthToken == "" {
+				r.Use(rs.TokenAuthMiddleware)
+			}
+
+			r.Get("/config", rs.runningConfig)
+			r.Get("/certificates", rs.certificates)
+			r.Get("/users/{email}/online", rs.userOnlineIPs)
+
+			r.Post("/inbounds", rs.addInbound)
+			r.Delete("/inbounds/{tag}", rs.removeInbound)
+			r.Post("/inbounds/{tag}/operations", rs.alterInbound)
+			r.Post("/inbounds/{tag}/users", rs.addInboundUser)
+			r.Delete("/inbounds/{tag}/users/{email}", rs.removeInboundUser)
+
+			r.Post("/outbounds", rs.addOutbound)
+			r.Delete("/outbounds/{tag}", rs.removeOutbound)
+			r.Post("/outbounds/{tag}/operations", rs.alterOutbound)
+			r.Post("/config/reload", rs.reloadConfig)
+			r.Post("/certificates/reload", rs.reloadCertificates)
+
+			r.Put("/balancers/{tag}/override", rs.overrideBalancer)
+		})
 	})
 	r.Get("/version", rs.version)
+	return r
+}
+
+func (rs *restfulService) start() error {
+	r := rs.router()
 
 	var listener net.Listener
 	var err error
@@ -94,6 +136,10 @@ func (rs *restfulService) start() error {
 		return newError("restful api cannot listen on the port ", rs.config.ListenPort).Base(err)
 	}
 
+	if rs.config.AuthToken == "" {
+		newError("auth token is not set, requests that change the instance or expose users are refused").AtWarning().WriteToLog()
+	}
+
 	go func() {
 		err := http.Serve(listener, r)
 		if err != nil {
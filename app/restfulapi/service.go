@@ -6,7 +6,11 @@ import (
 	"sync"
 
 	core "github.com/v2fly/v2ray-core/v5"
+	proxymancmd "github.com/v2fly/v2ray-core/v5/app/proxyman/command"
+	routercmd "github.com/v2fly/v2ray-core/v5/app/router/command"
+	statscmd "github.com/v2fly/v2ray-core/v5/app/stats/command"
 	"github.com/v2fly/v2ray-core/v5/features"
+	"github.com/v2fly/v2ray-core/v5/features/routing"
 	feature_stats "github.com/v2fly/v2ray-core/v5/features/stats"
 )
 
@@ -19,7 +23,12 @@ type restfulService struct {
 
 	stats feature_stats.Manager
 
-	ctx context.Context
+	statsServer   statscmd.StatsServiceServer
+	handlerServer proxymancmd.HandlerServiceServer
+	routingServer routercmd.RoutingServiceServer
+
+	instance *core.Instance
+	ctx      context.Context
 }
 
 func (rs *restfulService) Type() interface{} {
@@ -41,18 +50,22 @@ func (rs *restfulService) Close() error {
 	return nil
 }
 
-func (rs *restfulService) init(config *Config, stats feature_stats.Manager) {
+func (rs *restfulService) init(config *Config, stats feature_stats.Manager, router routing.Router) {
 	rs.stats = stats
 	rs.config = config
+	rs.statsServer = statscmd.NewStatsServer(stats)
+	rs.routingServer = routercmd.NewRoutingServer(router, nil)
 }
 
 func newRestfulService(ctx context.Context, config *Config) (features.Feature, error) {
 	r := new(restfulService)
 	r.ctx = ctx
-	if err := core.RequireFeatures(ctx, func(stats feature_stats.Manager) {
-		r.init(config, stats)
+	r.instance = core.MustFromContext(ctx)
+	if err := core.RequireFeatures(ctx, func(stats feature_stats.Manager, router routing.Router) {
+		r.init(config, stats, router)
 	}); err != nil {
 		return nil, err
 	}
+	r.handlerServer = proxymancmd.NewHandlerServer(r.instance, &proxymancmd.Config{RunningConfigPath: config.RunningConfigPath})
 	return r, nil
 }
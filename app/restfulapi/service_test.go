@@ -1,10 +1,17 @@
 package restfulapi
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/v2fly/v2ray-core/v5/app/stats"
+	"github.com/v2fly/v2ray-core/v5/common"
 )
 
 func TestTypeReturnAnonymousType(t *testing.T) {
@@ -12,3 +19,94 @@ func TestTypeReturnAnonymousType(t *testing.T) {
 	serviceType := service.Type()
 	assert.Empty(t, reflect.TypeOf(serviceType).Name(), "must return anonymous type")
 }
+
+func TestStatsEndpoints(t *testing.T) {
+	m, err := stats.NewManager(context.Background(), &stats.Config{})
+	common.Must(err)
+	c, err := m.RegisterCounter("inbound>>>api>>>traffic>>>uplink")
+	common.Must(err)
+	c.Set(42)
+	_, err = m.RegisterCounter("inbound>>>api>>>traffic>>>downlink")
+	common.Must(err)
+
+	service := &restfulService{}
+	service.init(&Config{AuthToken: "secret"}, m, nil)
+	handler := service.router()
+
+	request := func(target string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request("/v1/stats", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, request("/v1/stats", "wrong").Code)
+
+	rec := request("/v1/stats?pattern=uplink", "secret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"stat":[{"name":"inbound>>>api>>>traffic>>>uplink","value":"42"}]}`, rec.Body.String())
+
+	rec = request("/v1/stats/"+url.PathEscape("inbound>>>api>>>traffic>>>uplink")+"?reset=true", "secret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"stat":{"name":"inbound>>>api>>>traffic>>>uplink","value":"42"}}`, rec.Body.String())
+	assert.Equal(t, int64(0), c.Value())
+
+	assert.Equal(t, http.StatusNotFound, request("/v1/stats/unknown", "secret").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, request("/v1/stats?reset=maybe", "secret").Code)
+
+	rec = request("/v1/inbounds/api/stats", "secret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"uplink":0,"downlink":0}`, rec.Body.String())
+}
+
+func TestWriteEndpointsRequireToken(t *testing.T) {
+	m, err := stats.NewManager(context.Background(), &stats.Config{})
+	common.Must(err)
+	_, err = m.RegisterCounter("inbound>>>api>>>traffic>>>uplink")
+	common.Must(err)
+
+	service := &restfulService{}
+	service.init(&Config{}, m, nil)
+	handler := service.router()
+
+	request := func(method string, target string, token string) int {
+		req := httptest.NewRequest(method, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/v1/stats", ""))
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/v1/stats?reset=true", ""))
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/v1/stats/"+url.PathEscape("inbound>>>api>>>traffic>>>uplink")+"?reset=1", ""))
+
+	for _, route := range []struct {
+		method string
+		target string
+	}{
+		{http.MethodPost, "/v1/inbounds"},
+		{http.MethodDelete, "/v1/inbounds/api"},
+		{http.MethodPost, "/v1/inbounds/api/operations"},
+		{http.MethodPost, "/v1/inbounds/api/users"},
+		{http.MethodDelete, "/v1/inbounds/api/users/a@v2fly.org"},
+		{http.MethodPost, "/v1/outbounds"},
+		{http.MethodDelete, "/v1/outbounds/direct"},
+		{http.MethodPost, "/v1/outbounds/direct/operations"},
+		{http.MethodPost, "/v1/config/reload"},
+		{http.MethodPost, "/v1/certificates/reload"},
+		{http.MethodPut, "/v1/balancers/b/override"},
+		{http.MethodGet, "/v1/config"},
+		{http.MethodGet, "/v1/certificates"},
+		{http.MethodGet, "/v1/users/a@v2fly.org/online"},
+	} {
+		assert.Equal(t, http.StatusUnauthorized, request(route.method, route.target, ""), route.target)
+		assert.Equal(t, http.StatusUnauthorized, request(route.method, route.target, "any"), route.target)
+	}
+}
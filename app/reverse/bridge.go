@@ -6,6 +6,8 @@ import (
 
 	"google.golang.org/protobuf/proto"
 
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/mux"
 	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/common/session"
@@ -21,6 +23,7 @@ type Bridge struct {
 	dispatcher  routing.Dispatcher
 	tag         string
 	domain      string
+	identity    string
 	workers     []*BridgeWorker
 	monitorTask *task.Periodic
 }
@@ -39,6 +42,7 @@ func NewBridge(ctx context.Context, config *BridgeConfig, dispatcher routing.Dis
 		dispatcher: dispatcher,
 		tag:        config.Tag,
 		domain:     config.Domain,
+		identity:   config.Identity,
 	}
 	b.monitorTask = &task.Periodic{
 		Execute:  b.monitor,
@@ -75,7 +79,7 @@ func (b *Bridge) monitor() error {
 	}
 
 	if numWorker == 0 || numConnections/numWorker > 16 {
-		worker, err := NewBridgeWorker(b.ctx, b.domain, b.tag, b.dispatcher)
+		worker, err := NewBridgeWorker(b.ctx, b.domain, b.tag, b.identity, b.dispatcher)
 		if err != nil {
 			newError("failed to create bridge worker").Base(err).AtWarning().WriteToLog()
 			return nil
@@ -98,10 +102,13 @@ type BridgeWorker struct {
 	tag        string
 	worker     *mux.ServerWorker
 	dispatcher routing.Dispatcher
+	identity   string
 	state      Control_State
 }
 
-func NewBridgeWorker(ctx context.Context, domain string, tag string, d routing.Dispatcher) (*BridgeWorker, error) {
+// NewBridgeWorker creates a BridgeWorker connecting to the portal of the domain.
+// If identity is not empty, it is reported to the portal to identify this bridge.
+func NewBridgeWorker(ctx context.Context, domain string, tag string, identity string, d routing.Dispatcher) (*BridgeWorker, error) {
 	bridgeCtx := session.ContextWithInbound(ctx, &session.Inbound{
 		Tag: tag,
 	})
@@ -117,6 +124,7 @@ func NewBridgeWorker(ctx context.Context, domain string, tag string, d routing.D
 	w := &BridgeWorker{
 		dispatcher: d,
 		tag:        tag,
+		identity:   identity,
 	}
 
 	worker, err := mux.NewServerWorker(ctx, w, link)
@@ -149,6 +157,17 @@ func (w *BridgeWorker) Connections() uint32 {
 }
 
 func (w *BridgeWorker) handleInternalConn(link transport.Link) {
+	if w.identity != "" {
+		msg := &Control{
+			Identity: w.identity,
+		}
+		b, err := proto.Marshal(msg)
+		common.Must(err)
+		if err := link.Writer.WriteMultiBuffer(buf.MergeBytes(nil, b)); err != nil {
+			newError("failed to send bridge identity").Base(err).WriteToLog()
+		}
+	}
+
 	go func() {
 		reader := link.Reader
 		for {
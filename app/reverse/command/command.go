@@ -0,0 +1,51 @@
+//go:build !confonly
+// +build !confonly
+
+package command
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/app/reverse"
+	"github.com/v2fly/v2ray-core/v5/common"
+)
+
+type service struct {
+	UnimplementedReverseServiceServer
+	v *core.Instance
+}
+
+// NewReverseServer creates a ReverseService server that reports the portals of the given instance.
+func NewReverseServer(v *core.Instance) ReverseServiceServer {
+	return &service{v: v}
+}
+
+func (s *service) GetPortalStatus(ctx context.Context, request *GetPortalStatusRequest) (*GetPortalStatusResponse, error) {
+	// Reverse is optional in the configuration, so it is looked up on request instead of required on creation.
+	r, ok := s.v.GetFeature((*reverse.Reverse)(nil)).(*reverse.Reverse)
+	if !ok {
+		return nil, newError("reverse proxy is not configured")
+	}
+	portals := r.GetPortalStatus(request.Tag)
+	if request.Tag != "" && len(portals) == 0 {
+		return nil, newError("portal not found: ", request.Tag)
+	}
+	return &GetPortalStatusResponse{
+		Portals: portals,
+	}, nil
+}
+
+func (s *service) Register(server *grpc.Server) {
+	RegisterReverseServiceServer(server, s)
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, cfg interface{}) (interface{}, error) {
+		return NewReverseServer(core.MustFromContext(ctx)), nil
+	}))
+}
@@ -0,0 +1,75 @@
+package reverse
+
+import (
+	"context"
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/mux"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/transport"
+	"github.com/v2fly/v2ray-core/v5/transport/pipe"
+)
+
+func newTestPortalWorker(t *testing.T, identity string) *PortalWorker {
+	uplinkReader, uplinkWriter := pipe.New()
+	downlinkReader, downlinkWriter := pipe.New()
+	t.Cleanup(func() {
+		common.Interrupt(uplinkReader)
+		common.Interrupt(downlinkWriter)
+	})
+	client, err := mux.NewClientWorker(transport.Link{Reader: downlinkReader, Writer: uplinkWriter}, mux.ClientStrategy{
+		MaxConcurrency: 16,
+		MaxConnection:  256,
+	})
+	common.Must(err)
+	worker, err := NewPortalWorker(context.Background(), client)
+	common.Must(err)
+	worker.identity.Store(identity)
+	return worker
+}
+
+func TestStaticPickerStrategies(t *testing.T) {
+	picker, err := NewStaticMuxPicker()
+	common.Must(err)
+	workers := []*PortalWorker{
+		newTestPortalWorker(t, "a"),
+		newTestPortalWorker(t, "b"),
+		newTestPortalWorker(t, "b"),
+	}
+	for _, w := range workers {
+		picker.AddWorker(w)
+	}
+
+	picked := make(map[*mux.ClientWorker]bool)
+	for i := uint32(0); i < 3; i++ {
+		client, err := picker.pickRoundRobin("", i)
+		common.Must(err)
+		picked[client] = true
+	}
+	if len(picked) != 3 {
+		t.Error("round robin picked ", len(picked), " workers")
+	}
+
+	for i := uint32(0); i < 4; i++ {
+		client, err := picker.pickRoundRobin("b", i)
+		common.Must(err)
+		if client == workers[0].client {
+			t.Error("picked bridge with another identity")
+		}
+	}
+	if _, err := picker.pickLeastConnections("c"); err == nil {
+		t.Error("expected error for unknown identity, but nil")
+	}
+
+	source := net.ParseAddress("192.0.2.1")
+	sticky, err := picker.pickSticky("", source)
+	common.Must(err)
+	for i := 0; i < 4; i++ {
+		client, err := picker.pickSticky("", source)
+		common.Must(err)
+		if client != sticky {
+			t.Error("sticky picked a different worker for the same source")
+		}
+	}
+}
@@ -2,7 +2,10 @@ package reverse
 
 import (
 	"context"
+	"hash/fnv"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/protobuf/proto"
@@ -19,15 +22,24 @@ import (
 )
 
 type Portal struct {
-	ctx    context.Context
-	ohm    outbound.Manager
-	tag    string
-	domain string
-	picker *StaticMuxPicker
-	client *mux.ClientManager
+	ctx            context.Context
+	ohm            outbound.Manager
+	tag            string
+	domain         string
+	strategy       PortalConfig_Strategy
+	bridgeIdentity string
+	picker         *StaticMuxPicker
+	// next is the counter for round robin.
+	next uint32
 }
 
 func NewPortal(ctx context.Context, config *PortalConfig, ohm outbound.Manager) (*Portal, error) {
+	return newPortal(ctx, config, ohm, nil)
+}
+
+// newPortal creates a Portal that picks bridges from picker, which is shared by the portals of the same domain.
+// A new picker is created if picker is nil.
+func newPortal(ctx context.Context, config *PortalConfig, ohm outbound.Manager, picker *StaticMuxPicker) (*Portal, error) {
 	if config.Tag == "" {
 		return nil, newError("portal tag is empty")
 	}
@@ -36,20 +48,21 @@ func NewPortal(ctx context.Context, config *PortalConfig, ohm outbound.Manager)
 		return nil, newError("portal domain is empty")
 	}
 
-	picker, err := NewStaticMuxPicker()
-	if err != nil {
-		return nil, err
+	if picker == nil {
+		var err error
+		if picker, err = NewStaticMuxPicker(); err != nil {
+			return nil, err
+		}
 	}
 
 	return &Portal{
-		ctx:    ctx,
-		ohm:    ohm,
-		tag:    config.Tag,
-		domain: config.Domain,
-		picker: picker,
-		client: &mux.ClientManager{
-			Picker: picker,
-		},
+		ctx:            ctx,
+		ohm:            ohm,
+		tag:            config.Tag,
+		domain:         config.Domain,
+		strategy:       config.Strategy,
+		bridgeIdentity: config.BridgeIdentity,
+		picker:         picker,
 	}, nil
 }
 
@@ -85,7 +98,46 @@ func (p *Portal) HandleConnection(ctx context.Context, link *transport.Link) err
 		return nil
 	}
 
-	return p.client.Dispatch(ctx, link)
+	for i := 0; i < 16; i++ {
+		worker, err := p.pick(ctx)
+		if err != nil {
+			return err
+		}
+		if worker.Dispatch(ctx, link) {
+			return nil
+		}
+	}
+	return newError("unable to find an available bridge").AtWarning()
+}
+
+func (p *Portal) pick(ctx context.Context) (*mux.ClientWorker, error) {
+	switch p.strategy {
+	case PortalConfig_ROUND_ROBIN:
+		return p.picker.pickRoundRobin(p.bridgeIdentity, atomic.AddUint32(&p.next, 1))
+	case PortalConfig_STICKY_SOURCE:
+		var source net.Address
+		if inbound := session.InboundFromContext(ctx); inbound != nil && inbound.Source.IsValid() {
+			source = inbound.Source.Address
+		}
+		return p.picker.pickSticky(p.bridgeIdentity, source)
+	default:
+		return p.picker.pickLeastConnections(p.bridgeIdentity)
+	}
+}
+
+// Status returns the bridges connected to this portal.
+func (p *Portal) Status() *PortalStatus {
+	status := &PortalStatus{
+		Tag:    p.tag,
+		Domain: p.domain,
+	}
+	for _, w := range p.picker.getWorkers() {
+		if w.Closed() || (p.bridgeIdentity != "" && w.Identity() != p.bridgeIdentity) {
+			continue
+		}
+		status.Bridges = append(status.Bridges, w.Status())
+	}
+	return status
 }
 
 type Outbound struct {
@@ -146,7 +198,14 @@ func (p *StaticMuxPicker) cleanup() error {
 	return nil
 }
 
+// PickAvailable implements mux.WorkerPicker. It picks the bridge with fewest active connections.
 func (p *StaticMuxPicker) PickAvailable() (*mux.ClientWorker, error) {
+	return p.pickLeastConnections("")
+}
+
+// candidates returns the workers of bridges with the identity that take new connections.
+// If all of them are draining, those not full are returned.
+func (p *StaticMuxPicker) candidates(identity string) ([]*PortalWorker, error) {
 	p.access.Lock()
 	defer p.access.Unlock()
 
@@ -154,38 +213,84 @@ func (p *StaticMuxPicker) PickAvailable() (*mux.ClientWorker, error) {
 		return nil, newError("empty worker list")
 	}
 
-	minIdx := -1
-	var minConn uint32 = 9999
-	for i, w := range p.workers {
-		if w.draining {
+	var active, notFull []*PortalWorker
+	for _, w := range p.workers {
+		if identity != "" && w.Identity() != identity {
 			continue
 		}
-		if w.client.Closed() {
-			continue
+		if !w.draining && !w.client.Closed() {
+			active = append(active, w)
 		}
-		if w.client.ActiveConnections() < minConn {
-			minConn = w.client.ActiveConnections()
-			minIdx = i
+		if !w.IsFull() {
+			notFull = append(notFull, w)
 		}
 	}
 
-	if minIdx == -1 {
-		for i, w := range p.workers {
-			if w.IsFull() {
-				continue
-			}
-			if w.client.ActiveConnections() < minConn {
-				minConn = w.client.ActiveConnections()
-				minIdx = i
-			}
+	if len(active) > 0 {
+		return active, nil
+	}
+	if len(notFull) > 0 {
+		return notFull, nil
+	}
+	if identity != "" {
+		return nil, newError("no mux client worker available for bridge ", identity)
+	}
+	return nil, newError("no mux client worker available")
+}
+
+func (p *StaticMuxPicker) pickLeastConnections(identity string) (*mux.ClientWorker, error) {
+	workers, err := p.candidates(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	picked := workers[0]
+	for _, w := range workers[1:] {
+		if w.client.ActiveConnections() < picked.client.ActiveConnections() {
+			picked = w
 		}
 	}
+	return picked.client, nil
+}
 
-	if minIdx != -1 {
-		return p.workers[minIdx].client, nil
+func (p *StaticMuxPicker) pickRoundRobin(identity string, counter uint32) (*mux.ClientWorker, error) {
+	workers, err := p.candidates(identity)
+	if err != nil {
+		return nil, err
 	}
+	return workers[counter%uint32(len(workers))].client, nil
+}
 
-	return nil, newError("no mux client worker available")
+// pickSticky picks a bridge by rendezvous hashing of the source and the bridges,
+// so that a source stays with its bridge when other bridges come and go.
+func (p *StaticMuxPicker) pickSticky(identity string, source net.Address) (*mux.ClientWorker, error) {
+	workers, err := p.candidates(identity)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return workers[0].client, nil
+	}
+
+	var picked *PortalWorker
+	var maxScore uint64
+	for _, w := range workers {
+		h := fnv.New64a()
+		h.Write([]byte(source.String()))
+		h.Write([]byte(strconv.FormatUint(uint64(w.id), 10)))
+		if score := h.Sum64(); picked == nil || score > maxScore {
+			picked = w
+			maxScore = score
+		}
+	}
+	return picked.client, nil
+}
+
+func (p *StaticMuxPicker) getWorkers() []*PortalWorker {
+	p.access.Lock()
+	defer p.access.Unlock()
+
+	return append([]*PortalWorker(nil), p.workers...)
 }
 
 func (p *StaticMuxPicker) AddWorker(worker *PortalWorker) {
@@ -195,12 +300,19 @@ func (p *StaticMuxPicker) AddWorker(worker *PortalWorker) {
 	p.workers = append(p.workers, worker)
 }
 
+var portalWorkerID uint32
+
 type PortalWorker struct {
 	client   *mux.ClientWorker
 	control  *task.Periodic
 	writer   buf.Writer
 	reader   buf.Reader
 	draining bool
+
+	id       uint32
+	source   net.Destination
+	created  time.Time
+	identity atomic.Value
 }
 
 func NewPortalWorker(ctx context.Context, client *mux.ClientWorker) (*PortalWorker, error) {
@@ -219,18 +331,67 @@ func NewPortalWorker(ctx context.Context, client *mux.ClientWorker) (*PortalWork
 		return nil, newError("unable to dispatch control connection")
 	}
 	w := &PortalWorker{
-		client: client,
-		reader: downlinkReader,
-		writer: uplinkWriter,
+		client:  client,
+		reader:  downlinkReader,
+		writer:  uplinkWriter,
+		id:      atomic.AddUint32(&portalWorkerID, 1),
+		created: time.Now(),
+	}
+	w.identity.Store("")
+	if inbound := session.InboundFromContext(ctx); inbound != nil {
+		w.source = inbound.Source
 	}
 	w.control = &task.Periodic{
 		Execute:  w.heartbeat,
 		Interval: time.Second * 2,
 	}
 	w.control.Start()
+	go w.readControl()
 	return w, nil
 }
 
+// readControl reads the control messages from the bridge.
+func (w *PortalWorker) readControl() {
+	for {
+		mb, err := w.reader.ReadMultiBuffer()
+		if err != nil {
+			return
+		}
+		for _, b := range mb {
+			var ctl Control
+			if err := proto.Unmarshal(b.Bytes(), &ctl); err != nil {
+				newError("failed to parse proto message").Base(err).WriteToLog()
+				continue
+			}
+			if ctl.Identity != "" {
+				w.identity.Store(ctl.Identity)
+			}
+		}
+		buf.ReleaseMulti(mb)
+	}
+}
+
+// Identity returns the identity reported by the bridge, or empty if not reported.
+func (w *PortalWorker) Identity() string {
+	return w.identity.Load().(string)
+}
+
+// Status returns the status of the bridge connection.
+func (w *PortalWorker) Status() *BridgeStatus {
+	status := &BridgeStatus{
+		Identity:          w.Identity(),
+		ActiveConnections: w.client.ActiveConnections(),
+		TotalConnections:  w.client.TotalConnections(),
+		Uptime:            int64(time.Since(w.created) / time.Second),
+		TransferredBytes:  w.client.TransferredBytes(),
+		Draining:          w.draining,
+	}
+	if w.source.IsValid() {
+		status.Source = w.source.NetAddr()
+	}
+	return status
+}
+
 func (w *PortalWorker) heartbeat() error {
 	if w.client.Closed() {
 		return newError("client worker stopped")
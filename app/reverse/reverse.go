@@ -51,8 +51,18 @@ func (r *Reverse) Init(ctx context.Context, config *Config, d routing.Dispatcher
 		r.bridges = append(r.bridges, b)
 	}
 
+	// Portals of the same domain share the bridges connected to any of them.
+	pickers := make(map[string]*StaticMuxPicker)
 	for _, pConfig := range config.PortalConfig {
-		p, err := NewPortal(ctx, pConfig, ohm)
+		picker, found := pickers[pConfig.Domain]
+		if !found {
+			var err error
+			if picker, err = NewStaticMuxPicker(); err != nil {
+				return err
+			}
+			pickers[pConfig.Domain] = picker
+		}
+		p, err := newPortal(ctx, pConfig, ohm, picker)
 		if err != nil {
 			return err
 		}
@@ -66,6 +76,17 @@ func (r *Reverse) Type() interface{} {
 	return (*Reverse)(nil)
 }
 
+// GetPortalStatus returns the status of the portal with the tag, or of all portals if tag is empty.
+func (r *Reverse) GetPortalStatus(tag string) []*PortalStatus {
+	var status []*PortalStatus
+	for _, p := range r.portals {
+		if tag == "" || p.tag == tag {
+			status = append(status, p.Status())
+		}
+	}
+	return status
+}
+
 func (r *Reverse) Start() error {
 	for _, b := range r.bridges {
 		if err := b.Start(); err != nil {
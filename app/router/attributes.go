@@ -0,0 +1,268 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.starlark.net/starlark"
+)
+
+// attributes exposes the attributes of a connection to starlark as a read-only mapping,
+// without copying them into a starlark.Dict.
+type attributes map[string]string
+
+var (
+	_ starlark.IterableMapping = attributes(nil)
+	_ starlark.HasAttrs        = attributes(nil)
+)
+
+func (a attributes) keys() []string {
+	keys := make([]string, 0, len(a))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (a attributes) String() string {
+	sb := new(strings.Builder)
+	sb.WriteByte('{')
+	for i, key := range a.keys() {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(starlark.String(key).String())
+		sb.WriteString(": ")
+		sb.WriteString(starlark.String(a[key]).String())
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func (attributes) Type() string {
+	return "attrs"
+}
+
+func (attributes) Freeze() {}
+
+func (a attributes) Truth() starlark.Bool {
+	return len(a) > 0
+}
+
+func (attributes) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: attrs")
+}
+
+func (a attributes) Len() int {
+	return len(a)
+}
+
+// Get implements starlark.Mapping.
+func (a attributes) Get(k starlark.Value) (starlark.Value, bool, error) {
+	key, ok := starlark.AsString(k)
+	if !ok {
+		return nil, false, nil
+	}
+	value, found := a[key]
+	if !found {
+		return nil, false, nil
+	}
+	return starlark.String(value), true, nil
+}
+
+// Iterate implements starlark.Iterable.
+func (a attributes) Iterate() starlark.Iterator {
+	return a.keyTuple().Iterate()
+}
+
+// Items implements starlark.IterableMapping.
+func (a attributes) Items() []starlark.Tuple {
+	items := make([]starlark.Tuple, 0, len(a))
+	for _, key := range a.keys() {
+		items = append(items, starlark.Tuple{starlark.String(key), starlark.String(a[key])})
+	}
+	return items
+}
+
+func (a attributes) keyTuple() starlark.Tuple {
+	keys := make(starlark.Tuple, 0, len(a))
+	for _, key := range a.keys() {
+		keys = append(keys, starlark.String(key))
+	}
+	return keys
+}
+
+var attributesMethods = map[string]*starlark.Builtin{
+	"get":  starlark.NewBuiltin("get", attributesGet),
+	"keys": starlark.NewBuiltin("keys", attributesKeys),
+}
+
+// Attr implements starlark.HasAttrs.
+func (a attributes) Attr(name string) (starlark.Value, error) {
+	method, found := attributesMethods[name]
+	if !found {
+		return nil, nil
+	}
+	return method.BindReceiver(a), nil
+}
+
+// AttrNames implements starlark.HasAttrs.
+func (attributes) AttrNames() []string {
+	return []string{"get", "keys"}
+}
+
+func attributesGet(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var key string
+	var defaultValue starlark.Value = starlark.None
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &key, &defaultValue); err != nil {
+		return nil, err
+	}
+	if value, found := b.Receiver().(attributes)[key]; found {
+		return starlark.String(value), nil
+	}
+	return defaultValue, nil
+}
+
+func attributesKeys(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	return starlark.NewList(b.Receiver().(attributes).keyTuple()), nil
+}
+
+// maxCachedPatterns limits the number of compiled patterns kept by a matcher, in case they are built from attributes.
+const maxCachedPatterns = 64
+
+// attributeHelpers are the functions available to attribute rules besides the starlark builtins.
+type attributeHelpers struct {
+	access  sync.Mutex
+	regexps map[string]*regexp.Regexp
+	cidrs   map[string]*net.IPNet
+}
+
+func newAttributeHelpers() *attributeHelpers {
+	return &attributeHelpers{
+		regexps: make(map[string]*regexp.Regexp),
+		cidrs:   make(map[string]*net.IPNet),
+	}
+}
+
+func (h *attributeHelpers) predeclared() starlark.StringDict {
+	return starlark.StringDict{
+		"regex_match":   starlark.NewBuiltin("regex_match", h.regexMatch),
+		"cidr_contains": starlark.NewBuiltin("cidr_contains", h.cidrContains),
+	}
+}
+
+func (h *attributeHelpers) regexp(pattern string) (*regexp.Regexp, error) {
+	h.access.Lock()
+	defer h.access.Unlock()
+
+	if re, found := h.regexps[pattern]; found {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(h.regexps) < maxCachedPatterns {
+		h.regexps[pattern] = re
+	}
+	return re, nil
+}
+
+func (h *attributeHelpers) cidr(cidr string) (*net.IPNet, error) {
+	h.access.Lock()
+	defer h.access.Unlock()
+
+	if ipNet, found := h.cidrs[cidr]; found {
+		return ipNet, nil
+	}
+	var ipNet *net.IPNet
+	if strings.Contains(cidr, "/") {
+		var err error
+		if _, ipNet, err = net.ParseCIDR(cidr); err != nil {
+			return nil, err
+		}
+	} else {
+		ip := net.ParseIP(cidr)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP: %s", cidr)
+		}
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+			bits = 8 * net.IPv4len
+		}
+		ipNet = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+	}
+	if len(h.cidrs) < maxCachedPatterns {
+		h.cidrs[cidr] = ipNet
+	}
+	return ipNet, nil
+}
+
+// regexMatch implements regex_match(pattern, s), which reports whether s contains any match of the regular expression.
+func (h *attributeHelpers) regexMatch(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, s string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &pattern, &s); err != nil {
+		return nil, err
+	}
+	re, err := h.regexp(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlark.Bool(re.MatchString(s)), nil
+}
+
+// cidrContains implements cidr_contains(cidr, ip), which reports whether ip is in cidr.
+// cidr may also be a list of CIDRs. ip may be a list of IPs separated by commas, as in X-Forwarded-For, in which case the first one is checked.
+func (h *attributeHelpers) cidrContains(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var cidrs starlark.Value
+	var s string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &cidrs, &s); err != nil {
+		return nil, err
+	}
+	if i := strings.IndexByte(s, ','); i >= 0 {
+		s = s[:i]
+	}
+	ip := net.ParseIP(strings.TrimSpace(s))
+	if ip == nil {
+		return starlark.False, nil
+	}
+
+	var list []string
+	switch v := cidrs.(type) {
+	case starlark.String:
+		list = append(list, string(v))
+	case starlark.Iterable:
+		iter := v.Iterate()
+		defer iter.Done()
+		var x starlark.Value
+		for iter.Next(&x) {
+			cidr, ok := starlark.AsString(x)
+			if !ok {
+				return nil, fmt.Errorf("%s: got %s in CIDR list, want string", b.Name(), x.Type())
+			}
+			list = append(list, cidr)
+		}
+	default:
+		return nil, fmt.Errorf("%s: got %s for CIDR, want string or list", b.Name(), cidrs.Type())
+	}
+
+	for _, cidr := range list {
+		ipNet, err := h.cidr(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		if ipNet.Contains(ip) {
+			return starlark.True, nil
+		}
+	}
+	return starlark.False, nil
+}
@@ -9,6 +9,7 @@ import (
 	"google.golang.org/grpc"
 
 	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/app/router"
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/features/routing"
 	"github.com/v2fly/v2ray-core/v5/features/stats"
@@ -55,6 +56,77 @@ func (s *routingServer) OverrideBalancerTarget(ctx context.Context, request *Ove
 	return nil, newError("unsupported router implementation")
 }
 
+// ruleManager is implemented by routers whose rules can be changed at runtime.
+type ruleManager interface {
+	AddRule(config *router.RoutingRule, prepend bool) error
+	RemoveRule(ruleTag string) error
+	ReplaceRules(configs []*router.RoutingRule) error
+	ListRules() []*router.RoutingRule
+	AddBalancer(config *router.BalancingRule) error
+	RemoveBalancer(tag string) error
+}
+
+func (s *routingServer) ruleManager() (ruleManager, error) {
+	if rm, ok := s.router.(ruleManager); ok {
+		return rm, nil
+	}
+	return nil, newError("unsupported router implementation")
+}
+
+func (s *routingServer) AddRule(ctx context.Context, request *AddRuleRequest) (*AddRuleResponse, error) {
+	if request.Rule == nil {
+		return nil, newError("rule is not specified")
+	}
+	rm, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &AddRuleResponse{}, rm.AddRule(request.Rule, request.Prepend)
+}
+
+func (s *routingServer) RemoveRule(ctx context.Context, request *RemoveRuleRequest) (*RemoveRuleResponse, error) {
+	rm, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &RemoveRuleResponse{}, rm.RemoveRule(request.RuleTag)
+}
+
+func (s *routingServer) ReplaceRules(ctx context.Context, request *ReplaceRulesRequest) (*ReplaceRulesResponse, error) {
+	rm, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &ReplaceRulesResponse{}, rm.ReplaceRules(request.Rule)
+}
+
+func (s *routingServer) ListRules(ctx context.Context, request *ListRulesRequest) (*ListRulesResponse, error) {
+	rm, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &ListRulesResponse{Rule: rm.ListRules()}, nil
+}
+
+func (s *routingServer) AddBalancer(ctx context.Context, request *AddBalancerRequest) (*AddBalancerResponse, error) {
+	if request.Balancer == nil {
+		return nil, newError("balancer is not specified")
+	}
+	rm, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &AddBalancerResponse{}, rm.AddBalancer(request.Balancer)
+}
+
+func (s *routingServer) RemoveBalancer(ctx context.Context, request *RemoveBalancerRequest) (*RemoveBalancerResponse, error) {
+	rm, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &RemoveBalancerResponse{}, rm.RemoveBalancer(request.Tag)
+}
+
 // NewRoutingServer creates a statistics service with statistics manager.
 func NewRoutingServer(router routing.Router, routingStats stats.Channel) RoutingServiceServer {
 	return &routingServer{
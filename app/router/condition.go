@@ -1,13 +1,16 @@
 package router
 
 import (
+	"strconv"
 	"strings"
+	"time"
 
 	"go.starlark.net/starlark"
 	"go.starlark.net/syntax"
 
 	"github.com/v2fly/v2ray-core/v5/app/router/routercommon"
 	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/process"
 	"github.com/v2fly/v2ray-core/v5/common/strmatcher"
 	"github.com/v2fly/v2ray-core/v5/features/routing"
 )
@@ -274,45 +277,43 @@ func (m *ProtocolMatcher) Apply(ctx routing.Context) bool {
 	return false
 }
 
+// AttributeMatcher matches the attributes of a connection with a starlark expression.
+// The expression is compiled once into a function of attrs, which is then called for each connection.
 type AttributeMatcher struct {
-	program *starlark.Program
+	predicate starlark.Value
 }
 
 func NewAttributeMatcher(code string) (*AttributeMatcher, error) {
-	starFile, err := syntax.Parse("attr.star", "satisfied=("+code+")", 0)
+	starFile, err := syntax.Parse("attr.star", "def satisfied(attrs):\n    return ("+code+")\n", 0)
 	if err != nil {
 		return nil, newError("attr rule").Base(err)
 	}
-	p, err := starlark.FileProgram(starFile, func(name string) bool {
-		return name == "attrs"
-	})
+	predeclared := newAttributeHelpers().predeclared()
+	p, err := starlark.FileProgram(starFile, predeclared.Has)
 	if err != nil {
 		return nil, err
 	}
+	globals, err := p.Init(&starlark.Thread{Name: "matcher"}, predeclared)
+	if err != nil {
+		return nil, newError("attr rule").Base(err)
+	}
+	globals.Freeze()
 	return &AttributeMatcher{
-		program: p,
+		predicate: globals["satisfied"],
 	}, nil
 }
 
 // Match implements attributes matching.
 func (m *AttributeMatcher) Match(attrs map[string]string) bool {
-	attrsDict := new(starlark.Dict)
-	for key, value := range attrs {
-		attrsDict.SetKey(starlark.String(key), starlark.String(value))
-	}
-
-	predefined := make(starlark.StringDict)
-	predefined["attrs"] = attrsDict
-
 	thread := &starlark.Thread{
 		Name: "matcher",
 	}
-	results, err := m.program.Init(thread, predefined)
+	satisfied, err := starlark.Call(thread, m.predicate, starlark.Tuple{attributes(attrs)}, nil)
 	if err != nil {
 		newError("attr matcher").Base(err).WriteToLog()
+		return false
 	}
-	satisfied := results["satisfied"]
-	return satisfied != nil && bool(satisfied.Truth())
+	return bool(satisfied.Truth())
 }
 
 // Apply implements Condition.
@@ -323,3 +324,256 @@ func (m *AttributeMatcher) Apply(ctx routing.Context) bool {
 	}
 	return m.Match(attributes)
 }
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+const minutesPerDay = 24 * 60
+
+// timeRange is a daily time range on some days of a week. start and end are minutes since midnight.
+// If end is not after start, the range ends on the next day.
+type timeRange struct {
+	days  [7]bool
+	start int
+	end   int
+}
+
+func (r *timeRange) match(t time.Time) bool {
+	weekday := t.Weekday()
+	minute := t.Hour()*60 + t.Minute()
+	if r.start < r.end {
+		return r.days[weekday] && minute >= r.start && minute < r.end
+	}
+	yesterday := (weekday + 6) % 7
+	return (r.days[weekday] && minute >= r.start) || (r.days[yesterday] && minute < r.end)
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	day, found := weekdayNames[strings.ToLower(s)]
+	if !found {
+		return 0, newError("invalid weekday: ", s)
+	}
+	return day, nil
+}
+
+// parseDays parses days like "Mon-Fri" or "Sat,Sun".
+func parseDays(s string, days *[7]bool) error {
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := parseWeekday(from)
+		if err != nil {
+			return err
+		}
+		last := first
+		if isRange {
+			last, err = parseWeekday(to)
+			if err != nil {
+				return err
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseClock parses time of day like "09:30" into minutes since midnight.
+func parseClock(s string) (int, error) {
+	hour, minute, found := strings.Cut(s, ":")
+	if !found {
+		return 0, newError("invalid time of day: ", s)
+	}
+	h, err := strconv.Atoi(hour)
+	if err != nil || h < 0 || h > 24 {
+		return 0, newError("invalid time of day: ", s)
+	}
+	m, err := strconv.Atoi(minute)
+	if err != nil || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, newError("invalid time of day: ", s)
+	}
+	return h*60 + m, nil
+}
+
+// parseTimeRange parses a time range like "Mon-Fri 09:00-18:00". Either the days or the time of day may be omitted.
+func parseTimeRange(s string) (*timeRange, error) {
+	r := &timeRange{
+		end: minutesPerDay,
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, newError("invalid time range: ", s)
+	}
+	clock := fields[len(fields)-1]
+	if len(fields) == 2 || !strings.Contains(clock, ":") {
+		if err := parseDays(fields[0], &r.days); err != nil {
+			return nil, err
+		}
+		if len(fields) == 1 {
+			return r, nil
+		}
+	} else {
+		r.days = [7]bool{true, true, true, true, true, true, true}
+	}
+
+	start, end, found := strings.Cut(clock, "-")
+	if !found {
+		return nil, newError("invalid time range: ", s)
+	}
+	var err error
+	if r.start, err = parseClock(start); err != nil {
+		return nil, err
+	}
+	if r.end, err = parseClock(end); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// parseTimeZone parses an IANA time zone name or a fixed offset like "+08:00".
+func parseTimeZone(s string) (*time.Location, error) {
+	if s == "" {
+		return time.Local, nil
+	}
+	if s[0] == '+' || s[0] == '-' {
+		t, err := time.Parse("-07:00", s)
+		if err != nil {
+			return nil, newError("invalid time zone offset: ", s).Base(err)
+		}
+		_, offset := t.Zone()
+		return time.FixedZone(s, offset), nil
+	}
+	location, err := time.LoadLocation(s)
+	if err != nil {
+		return nil, newError("unknown time zone: ", s).Base(err)
+	}
+	return location, nil
+}
+
+// TimeMatcher matches the current time against weekly time ranges.
+type TimeMatcher struct {
+	ranges   []*timeRange
+	location *time.Location
+}
+
+func NewTimeMatcher(ranges []string, timeZone string) (*TimeMatcher, error) {
+	location, err := parseTimeZone(timeZone)
+	if err != nil {
+		return nil, err
+	}
+	m := &TimeMatcher{
+		location: location,
+	}
+	for _, s := range ranges {
+		r, err := parseTimeRange(s)
+		if err != nil {
+			return nil, err
+		}
+		m.ranges = append(m.ranges, r)
+	}
+	return m, nil
+}
+
+// Match returns true if t is in any of the time ranges.
+func (m *TimeMatcher) Match(t time.Time) bool {
+	t = t.In(m.location)
+	for _, r := range m.ranges {
+		if r.match(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply implements Condition.
+func (m *TimeMatcher) Apply(ctx routing.Context) bool {
+	return m.Match(time.Now())
+}
+
+// ProcessMatcher matches the local process that originates the connection by its name, or by the path of its executable.
+type ProcessMatcher struct {
+	names map[string]bool
+	paths map[string]bool
+}
+
+func NewProcessMatcher(names []string) *ProcessMatcher {
+	m := &ProcessMatcher{
+		names: make(map[string]bool),
+		paths: make(map[string]bool),
+	}
+	for _, name := range names {
+		switch {
+		case len(name) == 0:
+		case strings.Contains(name, "/"):
+			m.paths[name] = true
+		default:
+			m.names[name] = true
+		}
+	}
+	return m
+}
+
+// Match reports whether the process matches.
+func (m *ProcessMatcher) Match(info *process.Info) bool {
+	if info == nil {
+		return false
+	}
+	return (len(info.Name) > 0 && m.names[info.Name]) || (len(info.Path) > 0 && m.paths[info.Path])
+}
+
+// Apply implements Condition.
+func (m *ProcessMatcher) Apply(ctx routing.Context) bool {
+	processCtx, ok := ctx.(routing.ProcessContext)
+	if !ok {
+		return false
+	}
+	return m.Match(processCtx.GetProcess())
+}
+
+// ProcessOwnerMatcher matches the local process that originates the connection by its user or group.
+type ProcessOwnerMatcher struct {
+	ids   map[uint32]bool
+	group bool
+}
+
+func NewProcessOwnerMatcher(ids []uint32, group bool) *ProcessOwnerMatcher {
+	m := &ProcessOwnerMatcher{
+		ids:   make(map[uint32]bool, len(ids)),
+		group: group,
+	}
+	for _, id := range ids {
+		m.ids[id] = true
+	}
+	return m
+}
+
+// Match reports whether the process matches.
+func (m *ProcessOwnerMatcher) Match(info *process.Info) bool {
+	if info == nil {
+		return false
+	}
+	if m.group {
+		// GID is unknown if the process is not accessible.
+		return info.PID != 0 && m.ids[info.GID]
+	}
+	return m.ids[info.UID]
+}
+
+// Apply implements Condition.
+func (m *ProcessOwnerMatcher) Apply(ctx routing.Context) bool {
+	processCtx, ok := ctx.(routing.ProcessContext)
+	if !ok {
+		return false
+	}
+	return m.Match(processCtx.GetProcess())
+}
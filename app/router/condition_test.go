@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"google.golang.org/protobuf/proto"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/common/platform/filesystem"
+	"github.com/v2fly/v2ray-core/v5/common/process"
 	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/common/protocol/http"
 	"github.com/v2fly/v2ray-core/v5/common/session"
@@ -335,6 +337,95 @@ func TestRoutingRule(t *testing.T) {
 					input:  withContent(&session.Content{Protocol: "http/1.1", Attributes: map[string]string{":path": "/test/1"}}),
 					output: true,
 				},
+				{
+					input:  withContent(&session.Content{Protocol: "http/1.1", Attributes: map[string]string{":method": "GET"}}),
+					output: false,
+				},
+			},
+		},
+		{
+			rule: &router.RoutingRule{
+				Attributes: "attrs.get(':method') == 'POST' and regex_match('^/api/v[0-9]+/', attrs.get(':path', ''))",
+			},
+			test: []ruleTest{
+				{
+					input:  withContent(&session.Content{Attributes: map[string]string{":method": "POST", ":path": "/api/v2/users"}}),
+					output: true,
+				},
+				{
+					input:  withContent(&session.Content{Attributes: map[string]string{":method": "POST", ":path": "/static/api/v2"}}),
+					output: false,
+				},
+				{
+					input:  withContent(&session.Content{Attributes: map[string]string{":method": "GET", ":path": "/api/v2/users"}}),
+					output: false,
+				},
+			},
+		},
+		{
+			rule: &router.RoutingRule{
+				Attributes: "'x-forwarded-for' in attrs and cidr_contains(['10.0.0.0/8', '2001:db8::/32'], attrs['x-forwarded-for'])",
+			},
+			test: []ruleTest{
+				{
+					input:  withContent(&session.Content{Attributes: map[string]string{"x-forwarded-for": "10.1.2.3, 192.168.1.1"}}),
+					output: true,
+				},
+				{
+					input:  withContent(&session.Content{Attributes: map[string]string{"x-forwarded-for": "2001:db8::1"}}),
+					output: true,
+				},
+				{
+					input:  withContent(&session.Content{Attributes: map[string]string{"x-forwarded-for": "192.168.1.1"}}),
+					output: false,
+				},
+				{
+					input:  withContent(&session.Content{Attributes: map[string]string{"user-agent": "curl"}}),
+					output: false,
+				},
+			},
+		},
+		{
+			rule: &router.RoutingRule{
+				ProcessName: []string{"curl", "/usr/lib/firefox/firefox"},
+			},
+			test: []ruleTest{
+				{
+					input:  withInbound(&session.Inbound{Process: &process.Info{PID: 100, Name: "curl", Path: "/usr/bin/curl"}}),
+					output: true,
+				},
+				{
+					input:  withInbound(&session.Inbound{Process: &process.Info{PID: 101, Name: "firefox", Path: "/usr/lib/firefox/firefox"}}),
+					output: true,
+				},
+				{
+					input:  withInbound(&session.Inbound{Process: &process.Info{PID: 102, Name: "firefox", Path: "/opt/firefox/firefox"}}),
+					output: false,
+				},
+				{
+					input:  withBackground(),
+					output: false,
+				},
+			},
+		},
+		{
+			rule: &router.RoutingRule{
+				ProcessUid: []uint32{1000},
+				ProcessGid: []uint32{100},
+			},
+			test: []ruleTest{
+				{
+					input:  withInbound(&session.Inbound{Process: &process.Info{PID: 100, UID: 1000, GID: 100}}),
+					output: true,
+				},
+				{
+					input:  withInbound(&session.Inbound{Process: &process.Info{PID: 100, UID: 1000, GID: 1000}}),
+					output: false,
+				},
+				{
+					input:  withInbound(&session.Inbound{Process: &process.Info{UID: 1000}}),
+					output: false,
+				},
 			},
 		},
 	}
@@ -502,6 +593,23 @@ func BenchmarkDomainMatcher(b *testing.B) {
 	}
 }
 
+func BenchmarkAttributeMatcher(b *testing.B) {
+	matcher, err := router.NewAttributeMatcher("attrs[':method'] == 'GET' and regex_match('^/api/', attrs[':path'])")
+	common.Must(err)
+
+	attrs := map[string]string{
+		":method":    "GET",
+		":path":      "/api/v1/users",
+		"host":       "v2fly.org",
+		"user-agent": "curl/7.79.1",
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = matcher.Match(attrs)
+	}
+}
+
 func BenchmarkMultiGeoIPMatcher(b *testing.B) {
 	var geoips []*routercommon.GeoIP
 
@@ -552,3 +660,89 @@ func BenchmarkMultiGeoIPMatcher(b *testing.B) {
 		_ = matcher.Apply(ctx)
 	}
 }
+
+func TestTimeMatcher(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	cases := []struct {
+		ranges   []string
+		timeZone string
+		time     time.Time
+		output   bool
+	}{
+		{
+			ranges: []string{"Mon-Fri 09:00-18:00"},
+			time:   time.Date(2022, 8, 1, 9, 0, 0, 0, time.Local), // Monday
+			output: true,
+		},
+		{
+			ranges: []string{"Mon-Fri 09:00-18:00"},
+			time:   time.Date(2022, 8, 1, 18, 0, 0, 0, time.Local),
+			output: false,
+		},
+		{
+			ranges: []string{"Mon-Fri 09:00-18:00"},
+			time:   time.Date(2022, 8, 6, 10, 0, 0, 0, time.Local), // Saturday
+			output: false,
+		},
+		{
+			ranges: []string{"Mon-Fri 09:00-18:00", "sat,sun"},
+			time:   time.Date(2022, 8, 6, 10, 0, 0, 0, time.Local),
+			output: true,
+		},
+		{
+			ranges: []string{"Fri 22:00-06:00"},
+			time:   time.Date(2022, 8, 6, 5, 59, 0, 0, time.Local), // Saturday morning
+			output: true,
+		},
+		{
+			ranges: []string{"Fri 22:00-06:00"},
+			time:   time.Date(2022, 8, 5, 5, 0, 0, 0, time.Local), // Friday morning
+			output: false,
+		},
+		{
+			ranges: []string{"Sat-Mon"},
+			time:   time.Date(2022, 8, 1, 23, 0, 0, 0, time.Local),
+			output: true,
+		},
+		{
+			ranges: []string{"00:00-08:00"},
+			time:   time.Date(2022, 8, 3, 7, 0, 0, 0, time.Local),
+			output: true,
+		},
+		{
+			ranges:   []string{"Mon-Fri 09:00-18:00"},
+			timeZone: "+08:00",
+			time:     time.Date(2022, 8, 1, 9, 30, 0, 0, shanghai),
+			output:   true,
+		},
+		{
+			ranges:   []string{"Mon-Fri 09:00-18:00"},
+			timeZone: "+08:00",
+			time:     time.Date(2022, 8, 1, 9, 30, 0, 0, time.UTC), // 17:30 in +08:00
+			output:   true,
+		},
+		{
+			ranges:   []string{"Mon-Fri 09:00-18:00"},
+			timeZone: "UTC",
+			time:     time.Date(2022, 8, 1, 9, 30, 0, 0, shanghai), // Monday 01:30 in UTC
+			output:   false,
+		},
+	}
+
+	for _, test := range cases {
+		matcher, err := router.NewTimeMatcher(test.ranges, test.timeZone)
+		common.Must(err)
+		if actual := matcher.Match(test.time); actual != test.output {
+			t.Error("ranges: ", test.ranges, ", time: ", test.time, ", expect: ", test.output, ", actual: ", actual)
+		}
+	}
+
+	for _, invalid := range []string{"", "Mon-Fri 09:00", "Mon-Xyz", "25:00-26:00", "Mon 09:00-18:00 extra"} {
+		if _, err := router.NewTimeMatcher([]string{invalid}, ""); err == nil {
+			t.Error("expect error for ", invalid)
+		}
+	}
+	if _, err := router.NewTimeMatcher([]string{"Mon"}, "Nowhere/Unknown"); err == nil {
+		t.Error("expect error for unknown time zone")
+	}
+}
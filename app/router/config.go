@@ -19,8 +19,11 @@ import (
 
 type Rule struct {
 	Tag       string
+	RuleTag   string
 	Balancer  *Balancer
 	Condition Condition
+
+	config *RoutingRule
 }
 
 func (r *Rule) GetTag() (string, error) {
@@ -122,6 +125,26 @@ func (rr *RoutingRule) BuildCondition() (Condition, error) {
 		conds.Add(cond)
 	}
 
+	if len(rr.TimeRange) > 0 {
+		cond, err := NewTimeMatcher(rr.TimeRange, rr.TimeZone)
+		if err != nil {
+			return nil, newError("failed to build time condition").Base(err)
+		}
+		conds.Add(cond)
+	}
+
+	if len(rr.ProcessName) > 0 {
+		conds.Add(NewProcessMatcher(rr.ProcessName))
+	}
+
+	if len(rr.ProcessUid) > 0 {
+		conds.Add(NewProcessOwnerMatcher(rr.ProcessUid, false))
+	}
+
+	if len(rr.ProcessGid) > 0 {
+		conds.Add(NewProcessOwnerMatcher(rr.ProcessGid, true))
+	}
+
 	if conds.Len() == 0 {
 		return nil, newError("this rule has no effective fields").AtWarning()
 	}
@@ -4,6 +4,8 @@ package router
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 
 	core "github.com/v2fly/v2ray-core/v5"
 	"github.com/v2fly/v2ray-core/v5/common"
@@ -18,10 +20,23 @@ import (
 
 // Router is an implementation of routing.Router.
 type Router struct {
+	dns dns.Client
+
+	ctx        context.Context
+	ohm        outbound.Manager
+	dispatcher routing.Dispatcher
+
+	// table is replaced as a whole whenever rules or balancers change, so
+	// that routing decisions in progress keep using a consistent view.
+	table       atomic.Pointer[routingTable]
+	tableAccess sync.Mutex
+}
+
+// routingTable is an immutable set of rules and the balancers they refer to.
+type routingTable struct {
 	domainStrategy DomainStrategy
 	rules          []*Rule
 	balancers      map[string]*Balancer
-	dns            dns.Client
 }
 
 // Route is an implementation of routing.Route.
@@ -33,41 +48,101 @@ type Route struct {
 
 // Init initializes the Router.
 func (r *Router) Init(ctx context.Context, config *Config, d dns.Client, ohm outbound.Manager, dispatcher routing.Dispatcher) error {
-	r.domainStrategy = config.DomainStrategy
 	r.dns = d
+	r.ctx = ctx
+	r.ohm = ohm
+	r.dispatcher = dispatcher
+
+	table, err := r.buildTable(config)
+	if err != nil {
+		return err
+	}
+	r.table.Store(table)
+
+	return nil
+}
+
+// ReloadConfig implements features.ConfigReloader.
+func (r *Router) ReloadConfig(config interface{}) error {
+	var c *Config
+	switch config := config.(type) {
+	case *Config:
+		c = config
+	case *SimplifiedConfig:
+		var err error
+		if c, err = config.toConfig(cfgcommon.NewConfigureLoadingContext(r.ctx)); err != nil {
+			return err
+		}
+	default:
+		return newError("unexpected config type")
+	}
+	return r.updateTable(func(*routingTable) (*routingTable, error) {
+		return r.buildTable(c)
+	})
+}
 
-	r.balancers = make(map[string]*Balancer, len(config.BalancingRule))
+func (r *Router) buildTable(config *Config) (*routingTable, error) {
+	table := &routingTable{
+		domainStrategy: config.DomainStrategy,
+		balancers:      make(map[string]*Balancer, len(config.BalancingRule)),
+	}
 	for _, rule := range config.BalancingRule {
-		balancer, err := rule.Build(ohm, dispatcher)
+		balancer, err := r.buildBalancer(rule)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		balancer.InjectContext(ctx)
-		r.balancers[rule.Tag] = balancer
+		table.balancers[rule.Tag] = balancer
+	}
+
+	rules, err := buildRules(config.Rule, table.balancers)
+	if err != nil {
+		return nil, err
 	}
+	table.rules = rules
+	return table, nil
+}
 
-	r.rules = make([]*Rule, 0, len(config.Rule))
-	for _, rule := range config.Rule {
+func (r *Router) buildBalancer(config *BalancingRule) (*Balancer, error) {
+	balancer, err := config.Build(r.ohm, r.dispatcher)
+	if err != nil {
+		return nil, err
+	}
+	balancer.InjectContext(r.ctx)
+	return balancer, nil
+}
+
+// buildRules builds the matchers of the given rules. Rule tags must be unique.
+func buildRules(configs []*RoutingRule, balancers map[string]*Balancer) ([]*Rule, error) {
+	rules := make([]*Rule, 0, len(configs))
+	ruleTags := make(map[string]bool)
+	for _, rule := range configs {
+		if ruleTag := rule.GetRuleTag(); ruleTag != "" {
+			if ruleTags[ruleTag] {
+				return nil, newError("duplicated rule tag ", ruleTag)
+			}
+			ruleTags[ruleTag] = true
+		}
 		cond, err := rule.BuildCondition()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		rr := &Rule{
 			Condition: cond,
 			Tag:       rule.GetTag(),
+			RuleTag:   rule.GetRuleTag(),
+			config:    rule,
 		}
 		btag := rule.GetBalancingTag()
 		if len(btag) > 0 {
-			brule, found := r.balancers[btag]
+			brule, found := balancers[btag]
 			if !found {
-				return newError("balancer ", btag, " not found")
+				return nil, newError("balancer ", btag, " not found")
 			}
 			rr.Balancer = brule
 		}
-		r.rules = append(r.rules, rr)
+		rules = append(rules, rr)
 	}
-
-	return nil
+	return rules, nil
 }
 
 // PickRoute implements routing.Router.
@@ -88,25 +163,26 @@ func (r *Router) pickRouteInternal(ctx routing.Context) (*Rule, routing.Context,
 	// the DOH remote server maybe a domain name,
 	// this prevents cycle resolving dead loop
 	skipDNSResolve := ctx.GetSkipDNSResolve()
+	table := r.table.Load()
 
-	if r.domainStrategy == DomainStrategy_IpOnDemand && !skipDNSResolve {
+	if table.domainStrategy == DomainStrategy_IpOnDemand && !skipDNSResolve {
 		ctx = routing_dns.ContextWithDNSClient(ctx, r.dns)
 	}
 
-	for _, rule := range r.rules {
+	for _, rule := range table.rules {
 		if rule.Apply(ctx) {
 			return rule, ctx, nil
 		}
 	}
 
-	if r.domainStrategy != DomainStrategy_IpIfNonMatch || len(ctx.GetTargetDomain()) == 0 || skipDNSResolve {
+	if table.domainStrategy != DomainStrategy_IpIfNonMatch || len(ctx.GetTargetDomain()) == 0 || skipDNSResolve {
 		return nil, ctx, common.ErrNoClue
 	}
 
 	ctx = routing_dns.ContextWithDNSClient(ctx, r.dns)
 
 	// Try applying rules again if we have IPs.
-	for _, rule := range r.rules {
+	for _, rule := range table.rules {
 		if rule.Apply(ctx) {
 			return rule, ctx, nil
 		}
@@ -153,112 +229,124 @@ func init() {
 
 	common.Must(common.RegisterConfig((*SimplifiedConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
 		ctx = cfgcommon.NewConfigureLoadingContext(ctx)
+		fullConfig, err := config.(*SimplifiedConfig).toConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return common.CreateObject(ctx, fullConfig)
+	}))
+}
 
-		geoloadername := platform.NewEnvFlag("v2ray.conf.geoloader").GetValue(func() string {
-			return "memconservative"
-		})
+// toConfig converts the simplified config to a full Config. ctx must be a
+// configure loading context.
+func (c *SimplifiedConfig) toConfig(ctx context.Context) (*Config, error) {
+	geoloadername := platform.NewEnvFlag("v2ray.conf.geoloader").GetValue(func() string {
+		return "memconservative"
+	})
+
+	if loader, err := geodata.GetGeoDataLoader(geoloadername); err == nil {
+		cfgcommon.SetGeoDataLoader(ctx, loader)
+	} else {
+		return nil, newError("unable to create geo data loader ").Base(err)
+	}
 
-		if loader, err := geodata.GetGeoDataLoader(geoloadername); err == nil {
-			cfgcommon.SetGeoDataLoader(ctx, loader)
-		} else {
-			return nil, newError("unable to create geo data loader ").Base(err)
-		}
+	cfgEnv := cfgcommon.GetConfigureLoadingEnvironment(ctx)
+	geoLoader := cfgEnv.GetGeoLoader()
 
-		cfgEnv := cfgcommon.GetConfigureLoadingEnvironment(ctx)
-		geoLoader := cfgEnv.GetGeoLoader()
-
-		simplifiedConfig := config.(*SimplifiedConfig)
-
-		var routingRules []*RoutingRule
-
-		for _, v := range simplifiedConfig.Rule {
-			rule := new(RoutingRule)
-
-			for _, geo := range v.Geoip {
-				if geo.Code != "" {
-					filepath := "geoip.dat"
-					if geo.FilePath != "" {
-						filepath = geo.FilePath
-					} else {
-						geo.CountryCode = geo.Code
-					}
-					var err error
-					geo.Cidr, err = geoLoader.LoadIP(filepath, geo.Code)
-					if err != nil {
-						return nil, newError("unable to load geoip").Base(err)
-					}
+	var routingRules []*RoutingRule
+
+	for _, v := range c.Rule {
+		rule := new(RoutingRule)
+
+		for _, geo := range v.Geoip {
+			if geo.Code != "" {
+				filepath := "geoip.dat"
+				if geo.FilePath != "" {
+					filepath = geo.FilePath
+				} else {
+					geo.CountryCode = geo.Code
 				}
-			}
-			rule.Geoip = v.Geoip
-
-			for _, geo := range v.SourceGeoip {
-				if geo.Code != "" {
-					filepath := "geoip.dat"
-					if geo.FilePath != "" {
-						filepath = geo.FilePath
-					} else {
-						geo.CountryCode = geo.Code
-					}
-					var err error
-					geo.Cidr, err = geoLoader.LoadIP(filepath, geo.Code)
-					if err != nil {
-						return nil, newError("unable to load geoip").Base(err)
-					}
+				var err error
+				geo.Cidr, err = geoLoader.LoadIP(filepath, geo.Code)
+				if err != nil {
+					return nil, newError("unable to load geoip").Base(err)
 				}
 			}
-			rule.SourceGeoip = v.SourceGeoip
-
-			for _, geo := range v.GeoDomain {
-				if geo.Code != "" {
-					filepath := "geosite.dat"
-					if geo.FilePath != "" {
-						filepath = geo.FilePath
-					}
-					var err error
-					geo.Domain, err = geoLoader.LoadGeoSiteWithAttr(filepath, geo.Code)
-					if err != nil {
-						return nil, newError("unable to load geodomain").Base(err)
-					}
+		}
+		rule.Geoip = v.Geoip
+
+		for _, geo := range v.SourceGeoip {
+			if geo.Code != "" {
+				filepath := "geoip.dat"
+				if geo.FilePath != "" {
+					filepath = geo.FilePath
+				} else {
+					geo.CountryCode = geo.Code
 				}
-			}
-			if v.PortList != "" {
-				portList := &cfgcommon.PortList{}
-				err := portList.UnmarshalText(v.PortList)
+				var err error
+				geo.Cidr, err = geoLoader.LoadIP(filepath, geo.Code)
 				if err != nil {
-					return nil, err
+					return nil, newError("unable to load geoip").Base(err)
 				}
-				rule.PortList = portList.Build()
 			}
-			if v.SourcePortList != "" {
-				portList := &cfgcommon.PortList{}
-				err := portList.UnmarshalText(v.SourcePortList)
+		}
+		rule.SourceGeoip = v.SourceGeoip
+
+		for _, geo := range v.GeoDomain {
+			if geo.Code != "" {
+				filepath := "geosite.dat"
+				if geo.FilePath != "" {
+					filepath = geo.FilePath
+				}
+				var err error
+				geo.Domain, err = geoLoader.LoadGeoSiteWithAttr(filepath, geo.Code)
 				if err != nil {
-					return nil, err
+					return nil, newError("unable to load geodomain").Base(err)
 				}
-				rule.SourcePortList = portList.Build()
 			}
-			rule.Domain = v.Domain
-			rule.GeoDomain = v.GeoDomain
-			rule.Networks = v.Networks.GetNetwork()
-			rule.Protocol = v.Protocol
-			rule.Attributes = v.Attributes
-			rule.UserEmail = v.UserEmail
-			rule.InboundTag = v.InboundTag
-			rule.DomainMatcher = v.DomainMatcher
-			switch s := v.TargetTag.(type) {
-			case *SimplifiedRoutingRule_Tag:
-				rule.TargetTag = &RoutingRule_Tag{s.Tag}
-			case *SimplifiedRoutingRule_BalancingTag:
-				rule.TargetTag = &RoutingRule_BalancingTag{s.BalancingTag}
+		}
+		if v.PortList != "" {
+			portList := &cfgcommon.PortList{}
+			err := portList.UnmarshalText(v.PortList)
+			if err != nil {
+				return nil, err
+			}
+			rule.PortList = portList.Build()
+		}
+		if v.SourcePortList != "" {
+			portList := &cfgcommon.PortList{}
+			err := portList.UnmarshalText(v.SourcePortList)
+			if err != nil {
+				return nil, err
 			}
-			routingRules = append(routingRules, rule)
+			rule.SourcePortList = portList.Build()
 		}
-
-		fullConfig := &Config{
-			DomainStrategy: simplifiedConfig.DomainStrategy,
-			Rule:           routingRules,
-			BalancingRule:  simplifiedConfig.BalancingRule,
+		rule.Domain = v.Domain
+		rule.GeoDomain = v.GeoDomain
+		rule.Networks = v.Networks.GetNetwork()
+		rule.Protocol = v.Protocol
+		rule.Attributes = v.Attributes
+		rule.UserEmail = v.UserEmail
+		rule.InboundTag = v.InboundTag
+		rule.DomainMatcher = v.DomainMatcher
+		rule.RuleTag = v.RuleTag
+		rule.TimeRange = v.TimeRange
+		rule.TimeZone = v.TimeZone
+		rule.ProcessName = v.ProcessName
+		rule.ProcessUid = v.ProcessUid
+		rule.ProcessGid = v.ProcessGid
+		switch s := v.TargetTag.(type) {
+		case *SimplifiedRoutingRule_Tag:
+			rule.TargetTag = &RoutingRule_Tag{s.Tag}
+		case *SimplifiedRoutingRule_BalancingTag:
+			rule.TargetTag = &RoutingRule_BalancingTag{s.BalancingTag}
 		}
-		return common.CreateObject(ctx, fullConfig)
-	}))
+		routingRules = append(routingRules, rule)
+	}
+
+	return &Config{
+		DomainStrategy: c.DomainStrategy,
+		Rule:           routingRules,
+		BalancingRule:  c.BalancingRule,
+	}, nil
 }
@@ -247,3 +247,84 @@ func TestIPIfNonMatchIP(t *testing.T) {
 		t.Error("expect tag 'test', bug actually ", tag)
 	}
 }
+
+func TestRuleManagement(t *testing.T) {
+	config := &Config{
+		Rule: []*RoutingRule{
+			{
+				TargetTag: &RoutingRule_Tag{
+					Tag: "direct",
+				},
+				Networks: []net.Network{net.Network_TCP},
+				RuleTag:  "default",
+			},
+		},
+	}
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	mockDNS := mocks.NewDNSClient(mockCtl)
+	mockOhm := mocks.NewOutboundManager(mockCtl)
+	mockHs := mocks.NewOutboundHandlerSelector(mockCtl)
+
+	r := new(Router)
+	common.Must(r.Init(context.TODO(), config, mockDNS, &mockOutboundManager{
+		Manager:         mockOhm,
+		HandlerSelector: mockHs,
+	}, nil))
+
+	pick := func() string {
+		ctx := session.ContextWithOutbound(context.Background(), &session.Outbound{Target: net.TCPDestination(net.DomainAddress("v2fly.org"), 80)})
+		route, err := r.PickRoute(routing_session.AsRoutingContext(ctx))
+		common.Must(err)
+		return route.GetOutboundTag()
+	}
+
+	block := &RoutingRule{
+		TargetTag: &RoutingRule_Tag{
+			Tag: "block",
+		},
+		Domain:  []*routercommon.Domain{{Type: routercommon.Domain_RootDomain, Value: "v2fly.org"}},
+		RuleTag: "block",
+	}
+	common.Must(r.AddRule(block, false))
+	if tag := pick(); tag != "direct" {
+		t.Error("expect tag 'direct', but actually ", tag)
+	}
+	if err := r.AddRule(block, true); err == nil {
+		t.Error("expect error on duplicated rule tag")
+	}
+	common.Must(r.RemoveRule("block"))
+	common.Must(r.AddRule(block, true))
+	if tag := pick(); tag != "block" {
+		t.Error("expect tag 'block', but actually ", tag)
+	}
+	if rules := r.ListRules(); len(rules) != 2 || rules[0].RuleTag != "block" || rules[1].RuleTag != "default" {
+		t.Error("unexpected rules ", rules)
+	}
+
+	if err := r.AddRule(&RoutingRule{
+		TargetTag: &RoutingRule_BalancingTag{BalancingTag: "balance"},
+	}, true); err == nil {
+		t.Error("expect error on missing balancer")
+	}
+	common.Must(r.AddBalancer(&BalancingRule{
+		Tag:              "balance",
+		OutboundSelector: []string{"test-"},
+	}))
+	common.Must(r.ReplaceRules([]*RoutingRule{
+		{
+			TargetTag: &RoutingRule_BalancingTag{BalancingTag: "balance"},
+			Networks:  []net.Network{net.Network_TCP},
+		},
+	}))
+	if err := r.RemoveBalancer("balance"); err == nil {
+		t.Error("expect error on removing a balancer in use")
+	}
+	common.Must(r.ReplaceRules(nil))
+	common.Must(r.RemoveBalancer("balance"))
+	if len(r.ListRules()) != 0 {
+		t.Error("expect no rules")
+	}
+}
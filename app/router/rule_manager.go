@@ -0,0 +1,133 @@
+package router
+
+import (
+	"google.golang.org/protobuf/proto"
+)
+
+// updateTable replaces the routing table with the result of update.
+// Updates are serialized, while routing keeps using the old table until the
+// new one is in place.
+func (r *Router) updateTable(update func(table *routingTable) (*routingTable, error)) error {
+	r.tableAccess.Lock()
+	defer r.tableAccess.Unlock()
+
+	table, err := update(r.table.Load())
+	if err != nil {
+		return err
+	}
+	r.table.Store(table)
+	return nil
+}
+
+// AddRule adds a routing rule. The rule is placed before all existing rules
+// if prepend is true, and after them otherwise.
+func (r *Router) AddRule(config *RoutingRule, prepend bool) error {
+	config = proto.Clone(config).(*RoutingRule)
+	return r.updateTable(func(table *routingTable) (*routingTable, error) {
+		if ruleTag := config.GetRuleTag(); ruleTag != "" {
+			for _, rule := range table.rules {
+				if rule.RuleTag == ruleTag {
+					return nil, newError("rule ", ruleTag, " already exists")
+				}
+			}
+		}
+		built, err := buildRules([]*RoutingRule{config}, table.balancers)
+		if err != nil {
+			return nil, err
+		}
+		rules := make([]*Rule, 0, len(table.rules)+1)
+		if prepend {
+			rules = append(append(rules, built...), table.rules...)
+		} else {
+			rules = append(append(rules, table.rules...), built...)
+		}
+		return &routingTable{rules: rules, balancers: table.balancers}, nil
+	})
+}
+
+// RemoveRule removes the routing rule with the given rule tag.
+func (r *Router) RemoveRule(ruleTag string) error {
+	if ruleTag == "" {
+		return newError("empty rule tag")
+	}
+	return r.updateTable(func(table *routingTable) (*routingTable, error) {
+		for i, rule := range table.rules {
+			if rule.RuleTag == ruleTag {
+				rules := make([]*Rule, 0, len(table.rules)-1)
+				rules = append(append(rules, table.rules[:i]...), table.rules[i+1:]...)
+				return &routingTable{rules: rules, balancers: table.balancers}, nil
+			}
+		}
+		return nil, newError("rule ", ruleTag, " not found")
+	})
+}
+
+// ReplaceRules replaces all routing rules. Existing rules are kept if any of
+// the new rules is invalid.
+func (r *Router) ReplaceRules(configs []*RoutingRule) error {
+	cloned := make([]*RoutingRule, 0, len(configs))
+	for _, config := range configs {
+		cloned = append(cloned, proto.Clone(config).(*RoutingRule))
+	}
+	return r.updateTable(func(table *routingTable) (*routingTable, error) {
+		rules, err := buildRules(cloned, table.balancers)
+		if err != nil {
+			return nil, err
+		}
+		return &routingTable{rules: rules, balancers: table.balancers}, nil
+	})
+}
+
+// ListRules returns the routing rules in effect.
+func (r *Router) ListRules() []*RoutingRule {
+	rules := r.table.Load().rules
+	configs := make([]*RoutingRule, 0, len(rules))
+	for _, rule := range rules {
+		configs = append(configs, proto.Clone(rule.config).(*RoutingRule))
+	}
+	return configs
+}
+
+// AddBalancer adds a balancer that rules can refer to.
+func (r *Router) AddBalancer(config *BalancingRule) error {
+	if config.GetTag() == "" {
+		return newError("empty balancer tag")
+	}
+	return r.updateTable(func(table *routingTable) (*routingTable, error) {
+		if _, found := table.balancers[config.Tag]; found {
+			return nil, newError("balancer ", config.Tag, " already exists")
+		}
+		balancer, err := r.buildBalancer(config)
+		if err != nil {
+			return nil, err
+		}
+		balancers := make(map[string]*Balancer, len(table.balancers)+1)
+		for tag, b := range table.balancers {
+			balancers[tag] = b
+		}
+		balancers[config.Tag] = balancer
+		return &routingTable{rules: table.rules, balancers: balancers}, nil
+	})
+}
+
+// RemoveBalancer removes a balancer that is not referred to by any rule.
+func (r *Router) RemoveBalancer(tag string) error {
+	return r.updateTable(func(table *routingTable) (*routingTable, error) {
+		balancer, found := table.balancers[tag]
+		if !found {
+			return nil, newError("balancer ", tag, " not found")
+		}
+		for _, rule := range table.rules {
+			if rule.Balancer == balancer {
+				return nil, newError("balancer ", tag, " is in use by rules")
+			}
+		}
+		balancers := make(map[string]*Balancer, len(table.balancers))
+		for t, b := range table.balancers {
+			if t != tag {
+				balancers[t] = b
+			}
+		}
+		return &routingTable{rules: table.rules, balancers: balancers}, nil
+	})
+}
@@ -5,6 +5,7 @@ package command
 import (
 	"context"
 	"runtime"
+	"sort"
 	"time"
 
 	grpc "google.golang.org/grpc"
@@ -113,6 +114,28 @@ func (s *statsServer) GetSysStats(ctx context.Context, request *SysStatsRequest)
 	return response, nil
 }
 
+func (s *statsServer) GetUserOnlineIPs(ctx context.Context, request *GetUserOnlineIPsRequest) (*GetUserOnlineIPsResponse, error) {
+	if request.Email == "" {
+		return nil, newError("user email is not specified")
+	}
+	tracker, ok := s.stats.(feature_stats.OnlineTracker)
+	if !ok {
+		return nil, newError("GetUserOnlineIPs only works its own stats.Manager.")
+	}
+
+	response := &GetUserOnlineIPsResponse{}
+	for ip, since := range tracker.GetOnlineIPs(request.Email) {
+		response.Ip = append(response.Ip, &OnlineIP{
+			Ip:    ip,
+			Since: since.Unix(),
+		})
+	}
+	sort.Slice(response.Ip, func(i, j int) bool {
+		return response.Ip[i].Ip < response.Ip[j].Ip
+	})
+	return response, nil
+}
+
 func (s *statsServer) mustEmbedUnimplementedStatsServiceServer() {}
 
 type service struct {
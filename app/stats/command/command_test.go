@@ -90,3 +90,32 @@ func TestQueryStats(t *testing.T) {
 		t.Error(r)
 	}
 }
+
+func TestGetUserOnlineIPs(t *testing.T) {
+	m, err := stats.NewManager(context.Background(), &stats.Config{})
+	common.Must(err)
+
+	m.AddOnlineIP("test@v2fly.org", "10.0.0.2")
+	m.AddOnlineIP("test@v2fly.org", "10.0.0.1")
+	m.AddOnlineIP("test@v2fly.org", "10.0.0.1")
+	m.RemoveOnlineIP("test@v2fly.org", "10.0.0.1")
+	m.RemoveOnlineIP("test@v2fly.org", "10.0.0.2")
+
+	s := NewStatsServer(m)
+	resp, err := s.GetUserOnlineIPs(context.Background(), &GetUserOnlineIPsRequest{Email: "test@v2fly.org"})
+	common.Must(err)
+	if len(resp.Ip) != 1 || resp.Ip[0].Ip != "10.0.0.1" || resp.Ip[0].Since == 0 {
+		t.Error("unexpected online IPs: ", resp.Ip)
+	}
+
+	m.RemoveOnlineIP("test@v2fly.org", "10.0.0.1")
+	resp, err = s.GetUserOnlineIPs(context.Background(), &GetUserOnlineIPsRequest{Email: "test@v2fly.org"})
+	common.Must(err)
+	if len(resp.Ip) != 0 {
+		t.Error("expect user to be offline, but got ", resp.Ip)
+	}
+
+	if _, err := s.GetUserOnlineIPs(context.Background(), &GetUserOnlineIPsRequest{}); err == nil {
+		t.Error("expect error for empty email")
+	}
+}
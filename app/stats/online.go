@@ -0,0 +1,62 @@
+package stats
+
+import "time"
+
+// onlineIP is a source IP a user is connected from.
+type onlineIP struct {
+	connections int
+	since       time.Time
+}
+
+// AddOnlineIP implements stats.OnlineTracker.
+func (m *Manager) AddOnlineIP(email string, ip string) {
+	m.onlineAccess.Lock()
+	defer m.onlineAccess.Unlock()
+
+	ips, found := m.online[email]
+	if !found {
+		ips = make(map[string]*onlineIP)
+		m.online[email] = ips
+	}
+	entry, found := ips[ip]
+	if !found {
+		entry = &onlineIP{since: time.Now()}
+		ips[ip] = entry
+	}
+	entry.connections++
+}
+
+// RemoveOnlineIP implements stats.OnlineTracker.
+func (m *Manager) RemoveOnlineIP(email string, ip string) {
+	m.onlineAccess.Lock()
+	defer m.onlineAccess.Unlock()
+
+	ips, found := m.online[email]
+	if !found {
+		return
+	}
+	entry, found := ips[ip]
+	if !found {
+		return
+	}
+	entry.connections--
+	if entry.connections > 0 {
+		return
+	}
+	delete(ips, ip)
+	if len(ips) == 0 {
+		delete(m.online, email)
+	}
+}
+
+// GetOnlineIPs implements stats.OnlineTracker.
+func (m *Manager) GetOnlineIPs(email string) map[string]time.Time {
+	m.onlineAccess.Lock()
+	defer m.onlineAccess.Unlock()
+
+	ips := make(map[string]time.Time, len(m.online[email]))
+	for ip, entry := range m.online[email] {
+		ips[ip] = entry.since
+	}
+	return ips
+}
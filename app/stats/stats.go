@@ -17,6 +17,9 @@ type Manager struct {
 	counters map[string]*Counter
 	channels map[string]*Channel
 	running  bool
+
+	onlineAccess sync.Mutex
+	online       map[string]map[string]*onlineIP
 }
 
 // NewManager creates an instance of Statistics Manager.
@@ -24,6 +27,7 @@ func NewManager(ctx context.Context, config *Config) (*Manager, error) {
 	m := &Manager{
 		counters: make(map[string]*Counter),
 		channels: make(map[string]*Channel),
+		online:   make(map[string]map[string]*onlineIP),
 	}
 
 	return m, nil
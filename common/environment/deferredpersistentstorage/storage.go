@@ -0,0 +1,74 @@
+package deferredpersistentstorage
+
+import (
+	"context"
+
+	"github.com/v2fly/v2ray-core/v5/features/extension/storage"
+)
+
+// Resolver returns the storage that operations should be forwarded to.
+type Resolver func(ctx context.Context) (storage.ScopedPersistentStorage, error)
+
+// NewDeferredPersistentStorage creates a ScopedPersistentStorage that looks up
+// its backing storage on each operation. This allows environments to be
+// created before the storage engine itself is configured.
+func NewDeferredPersistentStorage(resolver Resolver) storage.ScopedPersistentStorage {
+	return &deferredPersistentStorage{resolver: resolver}
+}
+
+type deferredPersistentStorage struct {
+	resolver Resolver
+}
+
+func (d *deferredPersistentStorage) ScopedPersistentStorageEngine() {}
+
+func (d *deferredPersistentStorage) Put(ctx context.Context, key []byte, value []byte) error {
+	s, err := d.resolver(ctx)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, key, value)
+}
+
+func (d *deferredPersistentStorage) Get(ctx context.Context, key []byte) ([]byte, error) {
+	s, err := d.resolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, key)
+}
+
+func (d *deferredPersistentStorage) List(ctx context.Context, keyPrefix []byte) ([][]byte, error) {
+	s, err := d.resolver(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.List(ctx, keyPrefix)
+}
+
+func (d *deferredPersistentStorage) Clear(ctx context.Context) {
+	s, err := d.resolver(ctx)
+	if err != nil {
+		return
+	}
+	s.Clear(ctx)
+}
+
+func (d *deferredPersistentStorage) NarrowScope(ctx context.Context, key []byte) (storage.ScopedPersistentStorage, error) {
+	scopeKey := append([]byte(nil), key...)
+	return &deferredPersistentStorage{resolver: func(ctx context.Context) (storage.ScopedPersistentStorage, error) {
+		s, err := d.resolver(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return s.NarrowScope(ctx, scopeKey)
+	}}, nil
+}
+
+func (d *deferredPersistentStorage) DropScope(ctx context.Context, key []byte) error {
+	s, err := d.resolver(ctx)
+	if err != nil {
+		return err
+	}
+	return s.DropScope(ctx, key)
+}
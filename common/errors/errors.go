@@ -59,15 +59,24 @@ func (err *Error) Error() string {
 		builder.WriteString(": ")
 	}
 
-	msg := serial.Concat(err.message...)
-	builder.WriteString(msg)
+	builder.WriteString(err.Message())
+	return builder.String()
+}
 
+// Message returns the message of this error and its inner errors, without
+// the prefixes and the package path.
+func (err *Error) Message() string {
+	msg := serial.Concat(err.message...)
 	if err.inner != nil {
-		builder.WriteString(" > ")
-		builder.WriteString(err.inner.Error())
+		msg += " > " + err.inner.Error()
 	}
+	return msg
+}
 
-	return builder.String()
+// PkgPath returns the path of the package this error originates from,
+// relative to the module root.
+func (err *Error) PkgPath() string {
+	return err.pkgPath()
 }
 
 // Inner implements hasInnerError.Inner()
@@ -27,6 +27,11 @@ type AccessMessage struct {
 	Reason interface{}
 	Email  string
 	Detour string
+
+	// The fields below are only included in structured logs.
+	InboundTag string
+	Network    string
+	Domain     string
 }
 
 func (m *AccessMessage) String() string {
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+)
+
+// hasPkgPath is implemented by log contents that know the package they originate from.
+type hasPkgPath interface {
+	PkgPath() string
+}
+
+// hasMessage is implemented by log contents that have a message apart from their decorations.
+type hasMessage interface {
+	Message() string
+}
+
+type accessRecord struct {
+	Time        string `json:"time"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	Network     string `json:"network,omitempty"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason,omitempty"`
+	Email       string `json:"email,omitempty"`
+	InboundTag  string `json:"inbound,omitempty"`
+	OutboundTag string `json:"outbound,omitempty"`
+	Domain      string `json:"domain,omitempty"`
+}
+
+type generalRecord struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Package string `json:"package,omitempty"`
+	Message string `json:"message"`
+}
+
+type otherRecord struct {
+	Time    string `json:"time"`
+	Message string `json:"message"`
+}
+
+// FormatJSON returns the message as a single line JSON object.
+func FormatJSON(msg Message) string {
+	now := time.Now().Format(time.RFC3339Nano)
+
+	var record interface{}
+	switch msg := msg.(type) {
+	case *AccessMessage:
+		record = &accessRecord{
+			Time:        now,
+			Source:      serial.ToString(msg.From),
+			Destination: serial.ToString(msg.To),
+			Network:     msg.Network,
+			Status:      string(msg.Status),
+			Reason:      serial.ToString(msg.Reason),
+			Email:       msg.Email,
+			InboundTag:  msg.InboundTag,
+			OutboundTag: msg.Detour,
+			Domain:      msg.Domain,
+		}
+	case *GeneralMessage:
+		r := &generalRecord{
+			Time:    now,
+			Level:   msg.Severity.String(),
+			Message: serial.ToString(msg.Content),
+		}
+		if content, ok := msg.Content.(hasPkgPath); ok {
+			r.Package = content.PkgPath()
+		}
+		if content, ok := msg.Content.(hasMessage); ok {
+			r.Message = content.Message()
+		}
+		record = r
+	default:
+		record = &otherRecord{
+			Time:    now,
+			Message: msg.String(),
+		}
+	}
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		return msg.String()
+	}
+	return string(b)
+}
@@ -1,10 +1,13 @@
 package log_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/errors"
 	"github.com/v2fly/v2ray-core/v5/common/log"
 	"github.com/v2fly/v2ray-core/v5/common/net"
 )
@@ -31,3 +34,46 @@ func TestLogRecord(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+func TestFormatJSON(t *testing.T) {
+	access := log.FormatJSON(&log.AccessMessage{
+		From:       net.TCPDestination(net.ParseAddress("10.0.0.1"), 1234),
+		To:         net.TCPDestination(net.ParseAddress("v2fly.org"), 443),
+		Status:     log.AccessAccepted,
+		Email:      "love@v2fly.org",
+		Detour:     "direct",
+		InboundTag: "in",
+		Network:    "tcp",
+		Domain:     "v2fly.org",
+	})
+	var record map[string]string
+	common.Must(json.Unmarshal([]byte(access), &record))
+	delete(record, "time")
+	if diff := cmp.Diff(map[string]string{
+		"source":      "tcp:10.0.0.1:1234",
+		"destination": "tcp:v2fly.org:443",
+		"network":     "tcp",
+		"status":      "accepted",
+		"email":       "love@v2fly.org",
+		"inbound":     "in",
+		"outbound":    "direct",
+		"domain":      "v2fly.org",
+	}, record); diff != "" {
+		t.Error(diff)
+	}
+
+	general := log.FormatJSON(&log.GeneralMessage{
+		Severity: log.Severity_Warning,
+		Content:  errors.New("test message").Base(errors.New("inner")).WithPathObj(testLogger{}),
+	})
+	record = nil
+	common.Must(json.Unmarshal([]byte(general), &record))
+	delete(record, "time")
+	if diff := cmp.Diff(map[string]string{
+		"level":   "Warning",
+		"package": "common/log_test",
+		"message": "test message > inner",
+	}, record); diff != "" {
+		t.Error(diff)
+	}
+}
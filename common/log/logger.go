@@ -20,20 +20,50 @@ type Writer interface {
 // WriterCreator is a function to create LogWriters.
 type WriterCreator func() Writer
 
+// Reopener is implemented by handlers that can replace their writers, for example to open a log file
+// again after it is moved away by an external log rotation tool.
+type Reopener interface {
+	// Reopen makes the handler write to a newly created writer. Messages that are not written yet are kept.
+	Reopen() error
+}
+
 type generalLogger struct {
 	creator WriterCreator
+	format  func(Message) string
 	buffer  chan Message
 	access  *semaphore.Instance
 	done    *done.Instance
+	reopen  chan struct{}
 }
 
 // NewLogger returns a generic log handler that can handle all type of messages.
 func NewLogger(logWriterCreator WriterCreator) Handler {
 	return &generalLogger{
 		creator: logWriterCreator,
+		format:  Message.String,
 		buffer:  make(chan Message, 16),
 		access:  semaphore.New(1),
 		done:    done.New(),
+		reopen:  make(chan struct{}, 1),
+	}
+}
+
+// NewJSONLogger returns a log handler that writes each message as a JSON object on its own line.
+// The timestamp is part of the object, so writers created by this package don't prefix it.
+func NewJSONLogger(logWriterCreator WriterCreator) Handler {
+	return &generalLogger{
+		creator: func() Writer {
+			writer := logWriterCreator()
+			if w, ok := writer.(interface{ setFlags(int) }); ok {
+				w.setFlags(0)
+			}
+			return writer
+		},
+		format: FormatJSON,
+		buffer: make(chan Message, 16),
+		access: semaphore.New(1),
+		done:   done.New(),
+		reopen: make(chan struct{}, 1),
 	}
 }
 
@@ -44,18 +74,34 @@ func (l *generalLogger) run() {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
 
+	// The writer created below is new anyway.
+	select {
+	case <-l.reopen:
+	default:
+	}
+
 	logger := l.creator()
 	if logger == nil {
 		return
 	}
-	defer logger.Close()
+	defer func() {
+		if logger != nil {
+			logger.Close()
+		}
+	}()
 
 	for {
 		select {
 		case <-l.done.Wait():
 			return
+		case <-l.reopen:
+			logger.Close()
+			logger = l.creator()
+			if logger == nil {
+				return
+			}
 		case msg := <-l.buffer:
-			logger.Write(msg.String() + platform.LineSeparator())
+			logger.Write(l.format(msg) + platform.LineSeparator())
 			dataWritten = true
 		case <-ticker.C:
 			if !dataWritten {
@@ -79,6 +125,15 @@ func (l *generalLogger) Handle(msg Message) {
 	}
 }
 
+// Reopen implements Reopener.
+func (l *generalLogger) Reopen() error {
+	select {
+	case l.reopen <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
 func (l *generalLogger) Close() error {
 	return l.done.Close()
 }
@@ -96,6 +151,10 @@ func (w *consoleLogWriter) Close() error {
 	return nil
 }
 
+func (w *consoleLogWriter) setFlags(flags int) {
+	w.logger.SetFlags(flags)
+}
+
 type fileLogWriter struct {
 	file   *os.File
 	logger *log.Logger
@@ -110,6 +169,10 @@ func (w *fileLogWriter) Close() error {
 	return w.file.Close()
 }
 
+func (w *fileLogWriter) setFlags(flags int) {
+	w.logger.SetFlags(flags)
+}
+
 // CreateStdoutLogWriter returns a LogWriterCreator that creates LogWriter for stdout.
 func CreateStdoutLogWriter() WriterCreator {
 	return func() Writer {
@@ -3,6 +3,7 @@ package log_test
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -37,3 +38,58 @@ func TestFileLogger(t *testing.T) {
 		t.Fatal("Expect log text contains 'Test Log', but actually: ", string(b))
 	}
 }
+
+func TestFileLoggerReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v2ray.log")
+	creator, err := CreateFileLogWriter(path)
+	common.Must(err)
+	handler := NewLogger(creator)
+	defer common.Close(handler)
+
+	read := func(name string) string {
+		b, err := os.ReadFile(name)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	waitFor := func(name string, text string) {
+		for start := time.Now(); !strings.Contains(read(name), text); time.Sleep(10 * time.Millisecond) {
+			if time.Since(start) > 5*time.Second {
+				t.Fatal("expect ", name, " to contain ", text, ", but actually: ", read(name))
+			}
+		}
+	}
+
+	handler.Handle(&GeneralMessage{Content: "first"})
+	waitFor(path, "first")
+	common.Must(os.Rename(path, path+".1"))
+
+	// Messages handled around Reopen are not dropped.
+	handler.Handle(&GeneralMessage{Content: "second"})
+	common.Must(handler.(Reopener).Reopen())
+	handler.Handle(&GeneralMessage{Content: "third"})
+	for start := time.Now(); !strings.Contains(read(path+".1")+read(path), "third"); time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("message is dropped")
+		}
+	}
+	if logs := read(path+".1") + read(path); !strings.Contains(logs, "second") {
+		t.Error("message is dropped: ", logs)
+	}
+
+	// The file is created again once the writer is replaced.
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("log file is not reopened")
+		}
+	}
+	handler.Handle(&GeneralMessage{Content: "fourth"})
+	waitFor(path, "fourth")
+	if strings.Contains(read(path+".1"), "fourth") {
+		t.Error("expect new messages to be written to the reopened file")
+	}
+}
@@ -0,0 +1,222 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+)
+
+// backupTimeFormat is the suffix of rotated files. It sorts in chronological order.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotationOptions controls how a log file is rotated.
+type RotationOptions struct {
+	// MaxSize is the size in bytes above which the file is rotated. 0 for no limit.
+	MaxSize int64
+	// MaxAge is the duration after which the file is rotated. 0 for no limit.
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files to keep. 0 for keeping all of them.
+	MaxBackups int
+	// Compress indicates whether rotated files are compressed with gzip.
+	Compress bool
+}
+
+// rotatingFile is a log file that is rotated according to RotationOptions.
+// It is shared by all the writers created for the same path.
+type rotatingFile struct {
+	sync.Mutex
+	path     string
+	options  RotationOptions
+	file     *os.File
+	size     int64
+	openTime time.Time
+
+	millAccess sync.Mutex
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	if f.openTime.IsZero() {
+		f.openTime = time.Now()
+	}
+	return nil
+}
+
+func (f *rotatingFile) shouldRotate(n int) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.options.MaxSize > 0 && f.size+int64(n) > f.options.MaxSize {
+		return true
+	}
+	return f.options.MaxAge > 0 && time.Since(f.openTime) >= f.options.MaxAge
+}
+
+func (f *rotatingFile) rotate() error {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	backup := f.path + "." + time.Now().Format(backupTimeFormat)
+	if err := os.Rename(f.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	f.openTime = time.Now()
+	go f.mill(backup)
+	return f.open()
+}
+
+// mill compresses the rotated file and removes the backups over the limit.
+func (f *rotatingFile) mill(backup string) {
+	f.millAccess.Lock()
+	defer f.millAccess.Unlock()
+
+	if f.options.Compress {
+		if err := compressFile(backup); err != nil {
+			Record(&GeneralMessage{
+				Severity: Severity_Warning,
+				Content:  serial.Concat("failed to compress log file ", backup, ": ", err),
+			})
+		}
+	}
+
+	if f.options.MaxBackups <= 0 {
+		return
+	}
+	matches, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return
+	}
+	var backups []string
+	for _, name := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(name, f.path+"."), ".gz")
+		if _, err := time.Parse(backupTimeFormat, suffix); err == nil {
+			backups = append(backups, name)
+		}
+	}
+	if len(backups) <= f.options.MaxBackups {
+		return
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	for _, name := range backups[f.options.MaxBackups:] {
+		os.Remove(name)
+	}
+}
+
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(dst)
+	if _, err := io.Copy(writer, src); err != nil {
+		dst.Close()
+		os.Remove(name + ".gz")
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		dst.Close()
+		os.Remove(name + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
+
+// Write implements io.Writer.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.shouldRotate(len(p)) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// closeFile closes the file handle. The file is opened again on the next write.
+func (f *rotatingFile) closeFile() error {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+type rotatingFileLogWriter struct {
+	file   *rotatingFile
+	logger *log.Logger
+}
+
+func (w *rotatingFileLogWriter) Write(s string) error {
+	w.logger.Print(s)
+	return nil
+}
+
+func (w *rotatingFileLogWriter) Close() error {
+	return w.file.closeFile()
+}
+
+func (w *rotatingFileLogWriter) setFlags(flags int) {
+	w.logger.SetFlags(flags)
+}
+
+// CreateRotatingFileLogWriter returns a LogWriterCreator that creates LogWriter for the given file,
+// which is rotated according to the options.
+func CreateRotatingFileLogWriter(path string, options RotationOptions) (WriterCreator, error) {
+	f := &rotatingFile{
+		path:    path,
+		options: options,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	if err := f.closeFile(); err != nil {
+		return nil, err
+	}
+	return func() Writer {
+		return &rotatingFileLogWriter{
+			file:   f,
+			logger: log.New(f, "", log.Ldate|log.Ltime),
+		}
+	}, nil
+}
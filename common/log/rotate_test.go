@@ -0,0 +1,53 @@
+package log_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	. "github.com/v2fly/v2ray-core/v5/common/log"
+)
+
+func TestRotatingFileLogWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+
+	creator, err := CreateRotatingFileLogWriter(path, RotationOptions{
+		MaxSize:    64,
+		MaxBackups: 2,
+		Compress:   true,
+	})
+	common.Must(err)
+
+	writer := creator()
+	for i := 0; i < 5; i++ {
+		common.Must(writer.Write(strings.Repeat("x", 40)))
+		time.Sleep(10 * time.Millisecond)
+	}
+	common.Must(writer.Close())
+
+	var backups []string
+	for i := 0; i < 20; i++ {
+		backups, err = filepath.Glob(path + ".*.gz")
+		common.Must(err)
+		if len(backups) == 2 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if len(backups) != 2 {
+		t.Fatal("expect 2 compressed backups, but got ", backups)
+	}
+	if uncompressed, _ := filepath.Glob(path + ".*[0-9]"); len(uncompressed) != 0 {
+		t.Error("unexpected uncompressed backups: ", uncompressed)
+	}
+
+	info, err := os.Stat(path)
+	common.Must(err)
+	if info.Size() > 64 {
+		t.Error("expect the log file to be rotated, but its size is ", info.Size())
+	}
+}
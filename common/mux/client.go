@@ -4,12 +4,14 @@ import (
 	"context"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/errors"
 	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/net/packetaddr"
 	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/common/session"
 	"github.com/v2fly/v2ray-core/v5/common/signal/done"
@@ -45,6 +47,8 @@ type WorkerPicker interface {
 
 type IncrementalWorkerPicker struct {
 	Factory ClientWorkerFactory
+	// MaxWorkers limits the number of workers at the same time. Unlimited if 0.
+	MaxWorkers uint32
 
 	access      sync.Mutex
 	workers     []*ClientWorker
@@ -98,6 +102,19 @@ func (p *IncrementalWorkerPicker) pickInternal() (*ClientWorker, bool, error) {
 
 	p.cleanup()
 
+	if p.MaxWorkers > 0 {
+		// Workers that no longer take new connections are closing, and do not count against the limit.
+		active := 0
+		for _, w := range p.workers {
+			if !w.IsClosing() {
+				active++
+			}
+		}
+		if active >= int(p.MaxWorkers) {
+			return nil, false, newError("all ", active, " mux connections are full").AtWarning()
+		}
+	}
+
 	worker, err := p.Factory.Create()
 	if err != nil {
 		return nil, false, err
@@ -176,9 +193,17 @@ func (f *DialingWorkerFactory) Create() (*ClientWorker, error) {
 type ClientStrategy struct {
 	MaxConcurrency uint32
 	MaxConnection  uint32
+	// MaxBytes is the number of bytes that a worker transfers before it stops taking new connections. Unlimited if 0.
+	MaxBytes uint64
+	// IdleTimeout is how long a worker without connections is kept. 16 seconds if 0.
+	IdleTimeout time.Duration
 }
 
 type ClientWorker struct {
+	// bytes is the number of bytes transferred in both directions.
+	// It is accessed atomically, so it comes first for 64-bit alignment.
+	bytes uint64
+
 	sessionManager *SessionManager
 	link           transport.Link
 	done           *done.Instance
@@ -218,8 +243,17 @@ func (m *ClientWorker) Closed() bool {
 	return m.done.Done()
 }
 
+// TransferredBytes returns the number of bytes transferred in both directions.
+func (m *ClientWorker) TransferredBytes() uint64 {
+	return atomic.LoadUint64(&m.bytes)
+}
+
 func (m *ClientWorker) monitor() {
-	timer := time.NewTicker(time.Second * 16)
+	idleTimeout := m.strategy.IdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = time.Second * 16
+	}
+	timer := time.NewTicker(idleTimeout)
 	defer timer.Stop()
 
 	for {
@@ -258,7 +292,12 @@ func fetchInput(ctx context.Context, s *Session, output buf.Writer) {
 		transferType = protocol.TransferTypePacket
 	}
 	s.transferType = transferType
-	writer := NewWriter(s.ID, dest, output, transferType)
+	var writer *Writer
+	if s.packetAddr {
+		writer = NewPacketAddrWriter(s.ID, dest, newGlobalID(ctx), output)
+	} else {
+		writer = NewWriter(s.ID, dest, output, transferType)
+	}
 	defer s.Close()
 	defer writer.Close()
 
@@ -283,6 +322,9 @@ func (m *ClientWorker) IsClosing() bool {
 	if m.strategy.MaxConnection > 0 && sm.Count() >= int(m.strategy.MaxConnection) {
 		return true
 	}
+	if m.strategy.MaxBytes > 0 && m.TransferredBytes() >= m.strategy.MaxBytes {
+		return true
+	}
 	return false
 }
 
@@ -310,7 +352,12 @@ func (m *ClientWorker) Dispatch(ctx context.Context, link *transport.Link) bool
 	}
 	s.input = link.Reader
 	s.output = link.Writer
-	go fetchInput(ctx, s, m.link.Writer)
+	if outbound := session.OutboundFromContext(ctx); outbound != nil {
+		if _, err := packetaddr.GetDestinationSubsetOf(outbound.Target); err == nil {
+			s.packetAddr = true
+		}
+	}
+	go fetchInput(ctx, s, &countingWriter{Writer: m.link.Writer, counter: &m.bytes})
 	return true
 }
 
@@ -342,7 +389,7 @@ func (m *ClientWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.Buffere
 		return buf.Copy(NewStreamReader(reader), buf.Discard)
 	}
 
-	rr := s.NewReader(reader)
+	rr := s.NewReader(reader, meta)
 	err := buf.Copy(rr, s.output)
 	if err != nil && buf.IsWriteError(err) {
 		newError("failed to write to downstream. closing session ", s.ID).Base(err).WriteToLog()
@@ -379,7 +426,7 @@ func (m *ClientWorker) fetchOutput() {
 		common.Must(m.done.Close())
 	}()
 
-	reader := &buf.BufferedReader{Reader: m.link.Reader}
+	reader := &buf.BufferedReader{Reader: &countingReader{Reader: m.link.Reader, counter: &m.bytes}}
 
 	var meta FrameMetadata
 	for {
@@ -412,3 +459,26 @@ func (m *ClientWorker) fetchOutput() {
 		}
 	}
 }
+
+// countingWriter is a buf.Writer that adds the bytes written to a counter.
+type countingWriter struct {
+	buf.Writer
+	counter *uint64
+}
+
+func (w *countingWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	atomic.AddUint64(w.counter, uint64(mb.Len()))
+	return w.Writer.WriteMultiBuffer(mb)
+}
+
+// countingReader is a buf.Reader that adds the bytes read to a counter.
+type countingReader struct {
+	buf.Reader
+	counter *uint64
+}
+
+func (r *countingReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	mb, err := r.Reader.ReadMultiBuffer()
+	atomic.AddUint64(r.counter, uint64(mb.Len()))
+	return mb, err
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/golang/mock/gomock"
 
 	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/errors"
 	"github.com/v2fly/v2ray-core/v5/common/mux"
 	"github.com/v2fly/v2ray-core/v5/common/net"
@@ -115,3 +116,138 @@ func TestClientWorkerClose(t *testing.T) {
 
 	common.Must(w2.Close())
 }
+
+func TestIncrementalPickerMaxWorkers(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	r, w := pipe.New(pipe.WithoutSizeLimit())
+	defer w.Close()
+	worker, err := mux.NewClientWorker(transport.Link{
+		Reader: r,
+		Writer: w,
+	}, mux.ClientStrategy{
+		MaxConcurrency: 1,
+	})
+	common.Must(err)
+
+	factory := mocks.NewMuxClientWorkerFactory(mockCtl)
+	factory.EXPECT().Create().Return(worker, nil).Times(1)
+
+	picker := &mux.IncrementalWorkerPicker{
+		Factory:    factory,
+		MaxWorkers: 1,
+	}
+	manager := &mux.ClientManager{
+		Picker: picker,
+	}
+
+	tr, tw := pipe.New(pipe.WithoutSizeLimit())
+	defer tw.Close()
+	ctx := session.ContextWithOutbound(context.Background(), &session.Outbound{
+		Target: net.TCPDestination(net.DomainAddress("www.v2fly.org"), 80),
+	})
+	common.Must(manager.Dispatch(ctx, &transport.Link{
+		Reader: tr,
+		Writer: tw,
+	}))
+
+	if _, err := picker.PickAvailable(); err == nil {
+		t.Error("expected error, but nil")
+	}
+}
+
+func TestIncrementalPickerMaxWorkersClosing(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	newWorker := func() *mux.ClientWorker {
+		r, w := pipe.New(pipe.WithoutSizeLimit())
+		t.Cleanup(func() { w.Close() })
+		worker, err := mux.NewClientWorker(transport.Link{
+			Reader: r,
+			Writer: w,
+		}, mux.ClientStrategy{
+			MaxConnection: 1,
+		})
+		common.Must(err)
+		return worker
+	}
+	first := newWorker()
+	second := newWorker()
+
+	factory := mocks.NewMuxClientWorkerFactory(mockCtl)
+	gomock.InOrder(
+		factory.EXPECT().Create().Return(first, nil),
+		factory.EXPECT().Create().Return(second, nil),
+	)
+
+	picker := &mux.IncrementalWorkerPicker{
+		Factory:    factory,
+		MaxWorkers: 1,
+	}
+	manager := &mux.ClientManager{
+		Picker: picker,
+	}
+
+	ctx := session.ContextWithOutbound(context.Background(), &session.Outbound{
+		Target: net.TCPDestination(net.DomainAddress("www.v2fly.org"), 80),
+	})
+	dispatch := func() error {
+		tr, tw := pipe.New(pipe.WithoutSizeLimit())
+		t.Cleanup(func() { tw.Close() })
+		return manager.Dispatch(ctx, &transport.Link{
+			Reader: tr,
+			Writer: tw,
+		})
+	}
+
+	// The first session is still open, while its worker is used up.
+	common.Must(dispatch())
+	if !first.IsClosing() || first.Closed() {
+		t.Fatal("expected the first worker to be closing")
+	}
+
+	if err := dispatch(); err != nil {
+		t.Error("expected a new worker, but got ", err)
+	}
+	if first.ActiveConnections() != 1 || second.ActiveConnections() != 1 {
+		t.Error("unexpected sessions: ", first.ActiveConnections(), ", ", second.ActiveConnections())
+	}
+}
+
+func TestClientWorkerMaxBytes(t *testing.T) {
+	r, w := pipe.New(pipe.WithoutSizeLimit())
+	defer w.Close()
+	worker, err := mux.NewClientWorker(transport.Link{
+		Reader: r,
+		Writer: w,
+	}, mux.ClientStrategy{
+		MaxBytes: 64,
+	})
+	common.Must(err)
+
+	tr, tw := pipe.New(pipe.WithoutSizeLimit())
+	defer tw.Close()
+	ctx := session.ContextWithOutbound(context.Background(), &session.Outbound{
+		Target: net.TCPDestination(net.DomainAddress("www.v2fly.org"), 80),
+	})
+	if !worker.Dispatch(ctx, &transport.Link{Reader: tr, Writer: tw}) {
+		t.Fatal("failed to dispatch")
+	}
+	if worker.IsClosing() {
+		t.Error("worker is closing before reaching byte budget")
+	}
+
+	b := buf.New()
+	b.Extend(128)
+	common.Must(tw.WriteMultiBuffer(buf.MultiBuffer{b}))
+	time.Sleep(time.Millisecond * 200)
+
+	if bytes := worker.TransferredBytes(); bytes < 128 {
+		t.Error("transferred bytes: ", bytes)
+	}
+	if !worker.IsClosing() {
+		t.Error("worker is not closing after reaching byte budget")
+	}
+}
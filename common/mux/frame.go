@@ -51,13 +51,27 @@ Frame format
 2 bytes - port
 n bytes - address
 
+8 bytes - global id
+
+Network and address are present in new frames, and in keep frames of UDP sessions with per-packet addressing.
+Global id is optional, and only present in new frames of UDP sessions.
+
 */
 
+// GlobalID identifies a UDP session across Mux connections, so that the server keeps the same UDP mapping for it.
+type GlobalID [8]byte
+
+// IsZero returns true if the GlobalID is not set.
+func (id GlobalID) IsZero() bool {
+	return id == GlobalID{}
+}
+
 type FrameMetadata struct {
 	Target        net.Destination
 	SessionID     uint16
 	Option        bitmask.Byte
 	SessionStatus SessionStatus
+	GlobalID      GlobalID
 }
 
 func (f FrameMetadata) WriteTo(b *buf.Buffer) error {
@@ -70,7 +84,7 @@ func (f FrameMetadata) WriteTo(b *buf.Buffer) error {
 	common.Must(b.WriteByte(byte(f.SessionStatus)))
 	common.Must(b.WriteByte(byte(f.Option)))
 
-	if f.SessionStatus == SessionStatusNew {
+	if f.SessionStatus == SessionStatusNew || (f.SessionStatus == SessionStatusKeep && f.Target.Network == net.Network_UDP) {
 		switch f.Target.Network {
 		case net.Network_TCP:
 			common.Must(b.WriteByte(byte(TargetNetworkTCP)))
@@ -81,6 +95,10 @@ func (f FrameMetadata) WriteTo(b *buf.Buffer) error {
 		if err := addrParser.WriteAddressPort(b, f.Target.Address, f.Target.Port); err != nil {
 			return err
 		}
+
+		if f.SessionStatus == SessionStatusNew && f.Target.Network == net.Network_UDP && !f.GlobalID.IsZero() {
+			common.Must2(b.Write(f.GlobalID[:]))
+		}
 	}
 
 	len1 := b.Len()
@@ -117,9 +135,10 @@ func (f *FrameMetadata) UnmarshalFromBuffer(b *buf.Buffer) error {
 	f.SessionID = binary.BigEndian.Uint16(b.BytesTo(2))
 	f.SessionStatus = SessionStatus(b.Byte(2))
 	f.Option = bitmask.Byte(b.Byte(3))
-	f.Target.Network = net.Network_Unknown
+	f.Target = net.Destination{}
+	f.GlobalID = GlobalID{}
 
-	if f.SessionStatus == SessionStatusNew {
+	if f.SessionStatus == SessionStatusNew || (f.SessionStatus == SessionStatusKeep && b.Len() > 4 && TargetNetwork(b.Byte(4)) == TargetNetworkUDP) {
 		if b.Len() < 8 {
 			return newError("insufficient buffer: ", b.Len())
 		}
@@ -136,6 +155,9 @@ func (f *FrameMetadata) UnmarshalFromBuffer(b *buf.Buffer) error {
 			f.Target = net.TCPDestination(addr, port)
 		case TargetNetworkUDP:
 			f.Target = net.UDPDestination(addr, port)
+			if f.SessionStatus == SessionStatusNew && b.Len() >= int32(len(f.GlobalID)) {
+				copy(f.GlobalID[:], b.BytesTo(int32(len(f.GlobalID))))
+			}
 		default:
 			return newError("unknown network type: ", network)
 		}
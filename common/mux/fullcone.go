@@ -0,0 +1,196 @@
+package mux
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/log"
+	"github.com/v2fly/v2ray-core/v5/common/net/packetaddr"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/features/routing"
+	"github.com/v2fly/v2ray-core/v5/transport"
+)
+
+// globalIDKey keys the GlobalIDs derived from source addresses, so that they do not reveal the addresses.
+var globalIDKey = func() []byte {
+	key := make([]byte, 32)
+	common.Must2(rand.Read(key))
+	return key
+}()
+
+// newGlobalID returns the GlobalID for a UDP session from the inbound source in ctx.
+// Sessions from the same source get the same GlobalID, so that the server keeps their UDP mapping.
+func newGlobalID(ctx context.Context) GlobalID {
+	var id GlobalID
+	if inbound := session.InboundFromContext(ctx); inbound != nil && inbound.Source.IsValid() {
+		h := hmac.New(sha256.New, globalIDKey)
+		h.Write([]byte(inbound.Source.String()))
+		copy(id[:], h.Sum(nil))
+	} else {
+		common.Must2(rand.Read(id[:]))
+	}
+	return id
+}
+
+// fullConeTimeout is how long a fullConeLink is kept without sessions, waiting for the client to reconnect.
+const fullConeTimeout = time.Minute
+
+var fullConeLinks = struct {
+	sync.Mutex
+	links map[GlobalID]*fullConeLink
+}{
+	links: make(map[GlobalID]*fullConeLink),
+}
+
+// fullConeLink is a UDP link with per-packet addressing, shared by the sessions with the same GlobalID.
+// Responses are sent to the session attached most recently.
+type fullConeLink struct {
+	id     GlobalID
+	link   *transport.Link
+	access sync.Mutex
+	writer *Writer
+	timer  *time.Timer
+}
+
+// getFullConeLink returns the fullConeLink of the GlobalID, dispatching a new one if there is none.
+func getFullConeLink(ctx context.Context, dispatcher routing.Dispatcher, id GlobalID) (*fullConeLink, error) {
+	fullConeLinks.Lock()
+	l, found := fullConeLinks.links[id]
+	fullConeLinks.Unlock()
+	if found {
+		return l, nil
+	}
+
+	// The link outlives the Mux connection, so it must not be canceled with it.
+	dispatchCtx := session.ContextWithID(context.Background(), session.IDFromContext(ctx))
+	if inbound := session.InboundFromContext(ctx); inbound != nil {
+		dispatchCtx = session.ContextWithInbound(dispatchCtx, inbound)
+	}
+	if content := session.ContentFromContext(ctx); content != nil {
+		dispatchCtx = session.ContextWithContent(dispatchCtx, content)
+	}
+	if msg := log.AccessMessageFromContext(ctx); msg != nil {
+		dispatchCtx = log.ContextWithAccessMessage(dispatchCtx, msg)
+	}
+	link, err := dispatcher.Dispatch(dispatchCtx, packetaddr.PacketAddrDestination())
+	if err != nil {
+		return nil, err
+	}
+
+	fullConeLinks.Lock()
+	defer fullConeLinks.Unlock()
+	if l, found := fullConeLinks.links[id]; found {
+		common.Interrupt(link.Reader)
+		common.Interrupt(link.Writer)
+		return l, nil
+	}
+	l = &fullConeLink{
+		id:   id,
+		link: link,
+	}
+	fullConeLinks.links[id] = l
+	go l.run()
+	return l, nil
+}
+
+func (l *fullConeLink) run() {
+	defer l.close()
+
+	for {
+		mb, err := l.link.Reader.ReadMultiBuffer()
+		if err != nil {
+			return
+		}
+		l.access.Lock()
+		writer := l.writer
+		l.access.Unlock()
+		if writer == nil {
+			buf.ReleaseMulti(mb)
+			continue
+		}
+		if err := writer.WriteMultiBuffer(mb); err != nil {
+			newError("failed to write UDP response to session ", writer.id).Base(err).WriteToLog()
+		}
+	}
+}
+
+// attach sends the responses to the session of writer from now on.
+func (l *fullConeLink) attach(writer *Writer) {
+	l.access.Lock()
+	defer l.access.Unlock()
+
+	l.writer = writer
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+}
+
+// detach stops sending responses to the session of writer. The link is closed if no session attaches to it in time.
+func (l *fullConeLink) detach(writer *Writer) {
+	l.access.Lock()
+	defer l.access.Unlock()
+
+	if l.writer != writer {
+		return
+	}
+	l.writer = nil
+	if l.timer == nil {
+		l.timer = time.AfterFunc(fullConeTimeout, l.expire)
+	} else {
+		l.timer.Reset(fullConeTimeout)
+	}
+}
+
+func (l *fullConeLink) expire() {
+	l.access.Lock()
+	attached := l.writer != nil
+	l.access.Unlock()
+	if !attached {
+		l.close()
+	}
+}
+
+func (l *fullConeLink) close() {
+	fullConeLinks.Lock()
+	if fullConeLinks.links[l.id] == l {
+		delete(fullConeLinks.links, l.id)
+	}
+	fullConeLinks.Unlock()
+
+	common.Interrupt(l.link.Reader)
+	common.Interrupt(l.link.Writer)
+
+	l.access.Lock()
+	writer := l.writer
+	l.writer = nil
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+	l.access.Unlock()
+	if writer != nil {
+		writer.Close()
+	}
+}
+
+// fullConeOutput is the output of a session on a fullConeLink.
+type fullConeOutput struct {
+	link   *fullConeLink
+	writer *Writer
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (o *fullConeOutput) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	return o.link.link.Writer.WriteMultiBuffer(mb)
+}
+
+// Close implements common.Closable. It only detaches the session from the link.
+func (o *fullConeOutput) Close() error {
+	o.link.detach(o.writer)
+	return nil
+}
@@ -0,0 +1,142 @@
+package mux_test
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	gonet "net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	. "github.com/v2fly/v2ray-core/v5/common/mux"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/net/packetaddr"
+	"github.com/v2fly/v2ray-core/v5/features/routing"
+	"github.com/v2fly/v2ray-core/v5/transport"
+	"github.com/v2fly/v2ray-core/v5/transport/pipe"
+)
+
+func packetWithAddress(dest net.Destination, payload string) *buf.Buffer {
+	b := buf.New()
+	b.WriteString(payload)
+	b, err := packetaddr.AttachAddressToPacket(b, &gonet.UDPAddr{IP: dest.Address.IP(), Port: int(dest.Port)})
+	common.Must(err)
+	return b
+}
+
+func TestPacketAddrWriter(t *testing.T) {
+	pReader, pWriter := pipe.New(pipe.WithSizeLimit(1024))
+	bytesReader := &buf.BufferedReader{Reader: pReader}
+
+	globalID := GlobalID{1, 2, 3, 4, 5, 6, 7, 8}
+	dest1 := net.UDPDestination(net.LocalHostIP, 53)
+	dest2 := net.UDPDestination(net.ParseAddress("2001:db8::1"), 3478)
+	writer := NewPacketAddrWriter(1, packetaddr.PacketAddrDestination(), globalID, pWriter)
+	common.Must(writer.WriteMultiBuffer(buf.MultiBuffer{packetWithAddress(dest1, "a"), packetWithAddress(dest2, "b")}))
+
+	for _, expected := range []struct {
+		meta FrameMetadata
+		data string
+	}{
+		{
+			meta: FrameMetadata{SessionID: 1, SessionStatus: SessionStatusNew, Option: OptionData, Target: dest1, GlobalID: globalID},
+			data: "a",
+		},
+		{
+			meta: FrameMetadata{SessionID: 1, SessionStatus: SessionStatusKeep, Option: OptionData, Target: dest2},
+			data: "b",
+		},
+	} {
+		var meta FrameMetadata
+		common.Must(meta.Unmarshal(bytesReader))
+		if r := cmp.Diff(meta, expected.meta); r != "" {
+			t.Error("meta: ", r)
+		}
+		data, err := readAll(NewPacketReader(bytesReader))
+		common.Must(err)
+		if s := data.String(); s != expected.data {
+			t.Error("data: ", s)
+		}
+	}
+}
+
+type packetAddrDispatcher struct {
+	routing.Dispatcher
+	links chan *transport.Link
+}
+
+func (d *packetAddrDispatcher) Dispatch(ctx context.Context, dest net.Destination) (*transport.Link, error) {
+	if r := cmp.Diff(dest, packetaddr.PacketAddrDestination()); r != "" {
+		return nil, errors.New(r)
+	}
+	uplinkReader, uplinkWriter := pipe.New()
+	downlinkReader, downlinkWriter := pipe.New()
+	d.links <- &transport.Link{Reader: uplinkReader, Writer: downlinkWriter}
+	return &transport.Link{Reader: downlinkReader, Writer: uplinkWriter}, nil
+}
+
+func TestFullConeServerWorker(t *testing.T) {
+	dispatcher := &packetAddrDispatcher{links: make(chan *transport.Link, 2)}
+	var globalID GlobalID
+	common.Must2(rand.Read(globalID[:]))
+	peer1 := net.UDPDestination(net.ParseAddress("192.0.2.1"), 1000)
+	peer2 := net.UDPDestination(net.ParseAddress("192.0.2.2"), 2000)
+
+	// connect starts a Mux connection with a session of the GlobalID, which sends a packet to peer1.
+	connect := func() (*buf.BufferedReader, *Writer) {
+		uplinkReader, uplinkWriter := pipe.New()
+		downlinkReader, downlinkWriter := pipe.New()
+		_, err := NewServerWorker(context.Background(), dispatcher, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter})
+		common.Must(err)
+		writer := NewPacketAddrWriter(1, packetaddr.PacketAddrDestination(), globalID, uplinkWriter)
+		common.Must(writer.WriteMultiBuffer(buf.MultiBuffer{packetWithAddress(peer1, "ping")}))
+		return &buf.BufferedReader{Reader: downlinkReader}, writer
+	}
+	expectPacket := func(reader buf.Reader, dest net.Destination, payload string) {
+		t.Helper()
+		mb, err := reader.ReadMultiBuffer()
+		common.Must(err)
+		b, addr, err := packetaddr.ExtractAddressFromPacket(mb[0])
+		common.Must(err)
+		if r := cmp.Diff(net.DestinationFromAddr(addr), dest); r != "" {
+			t.Error("address: ", r)
+		}
+		if b.String() != payload {
+			t.Error("payload: ", b.String())
+		}
+	}
+	expectFrame := func(reader *buf.BufferedReader, dest net.Destination, payload string) {
+		t.Helper()
+		var meta FrameMetadata
+		common.Must(meta.Unmarshal(reader))
+		if r := cmp.Diff(meta, FrameMetadata{SessionID: 1, SessionStatus: SessionStatusKeep, Option: OptionData, Target: dest}); r != "" {
+			t.Error("meta: ", r)
+		}
+		data, err := readAll(NewPacketReader(reader))
+		common.Must(err)
+		if data.String() != payload {
+			t.Error("data: ", data.String())
+		}
+	}
+
+	reader, writer := connect()
+	link := <-dispatcher.links
+	expectPacket(link.Reader, peer1, "ping")
+	common.Must(link.Writer.WriteMultiBuffer(buf.MultiBuffer{packetWithAddress(peer2, "pong")}))
+	expectFrame(reader, peer2, "pong")
+	writer.Close()
+
+	// A new Mux connection with the same GlobalID reuses the UDP link.
+	reader, _ = connect()
+	expectPacket(link.Reader, peer1, "ping")
+	select {
+	case <-dispatcher.links:
+		t.Fatal("UDP link is dispatched again")
+	default:
+	}
+	common.Must(link.Writer.WriteMultiBuffer(buf.MultiBuffer{packetWithAddress(peer1, "pong")}))
+	expectFrame(reader, peer1, "pong")
+}
@@ -122,6 +122,9 @@ func (w *ServerWorker) handleStatusNew(ctx context.Context, meta *FrameMetadata,
 		}
 		ctx = log.ContextWithAccessMessage(ctx, msg)
 	}
+	if meta.Target.Network == net.Network_UDP && !meta.GlobalID.IsZero() {
+		return w.handleStatusNewFullCone(ctx, meta, reader)
+	}
 	link, err := w.dispatcher.Dispatch(ctx, meta.Target)
 	if err != nil {
 		if meta.Option.Has(OptionData) {
@@ -145,7 +148,7 @@ func (w *ServerWorker) handleStatusNew(ctx context.Context, meta *FrameMetadata,
 		return nil
 	}
 
-	rr := s.NewReader(reader)
+	rr := s.NewReader(reader, meta)
 	if err := buf.Copy(rr, s.output); err != nil {
 		buf.Copy(rr, buf.Discard)
 		common.Interrupt(s.input)
@@ -154,6 +157,38 @@ func (w *ServerWorker) handleStatusNew(ctx context.Context, meta *FrameMetadata,
 	return nil
 }
 
+// handleStatusNewFullCone starts a UDP session with per-packet addressing.
+// Sessions with the same GlobalID share the same UDP link, even from different Mux connections.
+func (w *ServerWorker) handleStatusNewFullCone(ctx context.Context, meta *FrameMetadata, reader *buf.BufferedReader) error {
+	l, err := getFullConeLink(ctx, w.dispatcher, meta.GlobalID)
+	if err != nil {
+		if meta.Option.Has(OptionData) {
+			buf.Copy(NewStreamReader(reader), buf.Discard)
+		}
+		return newError("failed to dispatch request.").Base(err)
+	}
+	writer := NewPacketAddrResponseWriter(meta.SessionID, w.link.Writer)
+	s := &Session{
+		output:       &fullConeOutput{link: l, writer: writer},
+		parent:       w.sessionManager,
+		ID:           meta.SessionID,
+		transferType: protocol.TransferTypePacket,
+		packetAddr:   true,
+	}
+	w.sessionManager.Add(s)
+	l.attach(writer)
+	if !meta.Option.Has(OptionData) {
+		return nil
+	}
+
+	rr := s.NewReader(reader, meta)
+	if err := buf.Copy(rr, s.output); err != nil {
+		buf.Copy(rr, buf.Discard)
+		return s.Close()
+	}
+	return nil
+}
+
 func (w *ServerWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.BufferedReader) error {
 	if !meta.Option.Has(OptionData) {
 		return nil
@@ -168,7 +203,7 @@ func (w *ServerWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.Buffere
 		return buf.Copy(NewStreamReader(reader), buf.Discard)
 	}
 
-	rr := s.NewReader(reader)
+	rr := s.NewReader(reader, meta)
 	err := buf.Copy(rr, s.output)
 
 	if err != nil && buf.IsWriteError(err) {
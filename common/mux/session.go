@@ -5,6 +5,8 @@ import (
 
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/net/packetaddr"
 	"github.com/v2fly/v2ray-core/v5/common/protocol"
 )
 
@@ -141,6 +143,11 @@ type Session struct {
 	parent       *SessionManager
 	ID           uint16
 	transferType protocol.TransferType
+	// packetAddr indicates that packets of this session carry their addresses in frame metadata,
+	// and in packetaddr encoding on input and output.
+	packetAddr bool
+	// target is the address of the last packet with per-packet addressing.
+	target net.Destination
 }
 
 // Close closes all resources associated with this session.
@@ -151,10 +158,50 @@ func (s *Session) Close() error {
 	return nil
 }
 
-// NewReader creates a buf.Reader based on the transfer type of this Session.
-func (s *Session) NewReader(reader *buf.BufferedReader) buf.Reader {
+// NewReader creates a buf.Reader based on the transfer type of this Session, for the frame with the given metadata.
+func (s *Session) NewReader(reader *buf.BufferedReader, meta *FrameMetadata) buf.Reader {
 	if s.transferType == protocol.TransferTypeStream {
 		return NewStreamReader(reader)
 	}
-	return NewPacketReader(reader)
+	if !s.packetAddr {
+		return NewPacketReader(reader)
+	}
+	if meta.Target.IsValid() {
+		s.target = meta.Target
+	}
+	return &packetAddrReader{
+		reader: NewPacketReader(reader),
+		target: s.target,
+	}
+}
+
+// packetAddrReader prepends the address of the frame to packets, in packetaddr encoding.
+type packetAddrReader struct {
+	reader buf.Reader
+	target net.Destination
+}
+
+// ReadMultiBuffer implements buf.Reader.
+func (r *packetAddrReader) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	mb, err := r.reader.ReadMultiBuffer()
+	if err != nil {
+		return nil, err
+	}
+	if !r.target.IsValid() || !r.target.Address.Family().IsIP() {
+		buf.ReleaseMulti(mb)
+		return nil, nil
+	}
+	addr := &net.UDPAddr{
+		IP:   r.target.Address.IP(),
+		Port: int(r.target.Port),
+	}
+	for i, b := range mb {
+		if mb[i], err = packetaddr.AttachAddressToPacket(b, addr); err != nil {
+			buf.ReleaseMulti(mb[i+1:])
+			buf.ReleaseMulti(mb[:i])
+			b.Release()
+			return nil, err
+		}
+	}
+	return mb, nil
 }
@@ -5,6 +5,7 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/errors"
 	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/net/packetaddr"
 	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/common/serial"
 )
@@ -16,6 +17,8 @@ type Writer struct {
 	followup     bool
 	hasError     bool
 	transferType protocol.TransferType
+	packetAddr   bool
+	globalID     GlobalID
 }
 
 func NewWriter(id uint16, dest net.Destination, writer buf.Writer, transferType protocol.TransferType) *Writer {
@@ -37,17 +40,47 @@ func NewResponseWriter(id uint16, writer buf.Writer, transferType protocol.Trans
 	}
 }
 
+// NewPacketAddrWriter creates a Writer for a UDP session with per-packet addressing.
+// Each packet written to it starts with its destination in packetaddr encoding, which is moved into the frame metadata.
+func NewPacketAddrWriter(id uint16, dest net.Destination, globalID GlobalID, writer buf.Writer) *Writer {
+	return &Writer{
+		id:           id,
+		dest:         dest,
+		writer:       writer,
+		followup:     false,
+		transferType: protocol.TransferTypePacket,
+		packetAddr:   true,
+		globalID:     globalID,
+	}
+}
+
+// NewPacketAddrResponseWriter creates a response Writer for a UDP session with per-packet addressing.
+// Each packet written to it starts with its source in packetaddr encoding, which is moved into the frame metadata.
+func NewPacketAddrResponseWriter(id uint16, writer buf.Writer) *Writer {
+	return &Writer{
+		id:           id,
+		writer:       writer,
+		followup:     true,
+		transferType: protocol.TransferTypePacket,
+		packetAddr:   true,
+	}
+}
+
 func (w *Writer) getNextFrameMeta() FrameMetadata {
 	meta := FrameMetadata{
 		SessionID: w.id,
-		Target:    w.dest,
 	}
 
 	if w.followup {
 		meta.SessionStatus = SessionStatusKeep
+		if w.packetAddr && w.dest.IsValid() {
+			meta.Target = w.dest
+		}
 	} else {
 		w.followup = true
 		meta.SessionStatus = SessionStatusNew
+		meta.Target = w.dest
+		meta.GlobalID = w.globalID
 	}
 
 	return meta
@@ -103,6 +136,16 @@ func (w *Writer) WriteMultiBuffer(mb buf.MultiBuffer) error {
 		} else {
 			mb2, b := buf.SplitFirst(mb)
 			mb = mb2
+			if w.packetAddr {
+				payload, addr, err := packetaddr.ExtractAddressFromPacket(b)
+				if err != nil {
+					b.Release()
+					newError("dropping packet without valid address").Base(err).AtDebug().WriteToLog()
+					continue
+				}
+				b = payload
+				w.dest = net.DestinationFromAddr(addr)
+			}
 			chunk = buf.MultiBuffer{b}
 		}
 		if err := w.writeData(chunk); err != nil {
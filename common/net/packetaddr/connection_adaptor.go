@@ -39,12 +39,7 @@ func CreatePacketAddrConn(ctx context.Context, dispatcher routing.Dispatcher, is
 	if isStream {
 		return nil, errUnsupported
 	}
-	packetDest := net.Destination{
-		Address: net.DomainAddress(seqPacketMagicAddress),
-		Port:    0,
-		Network: net.Network_UDP,
-	}
-	link, err := dispatcher.Dispatch(ctx, packetDest)
+	link, err := dispatcher.Dispatch(ctx, PacketAddrDestination())
 	if err != nil {
 		return nil, err
 	}
@@ -55,6 +50,15 @@ func CreatePacketAddrConn(ctx context.Context, dispatcher routing.Dispatcher, is
 	}, nil
 }
 
+// PacketAddrDestination returns the destination of links whose packets carry their addresses in packetaddr encoding.
+func PacketAddrDestination() net.Destination {
+	return net.Destination{
+		Address: net.DomainAddress(seqPacketMagicAddress),
+		Port:    0,
+		Network: net.Network_UDP,
+	}
+}
+
 type packetConnectionAdaptor struct {
 	readerAccess *sync.Mutex
 	readerBuffer buf.MultiBuffer
@@ -0,0 +1,25 @@
+// Package process finds the local processes that own network connections.
+package process
+
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
+import (
+	"github.com/v2fly/v2ray-core/v5/common/errors"
+)
+
+// ErrNotFound indicates that no local process owns the connection, for example because the connection comes from another host.
+var ErrNotFound = errors.New("process not found")
+
+// Info describes a local process.
+type Info struct {
+	// PID of the process. It is 0 if the process is not accessible, in which case only UID is known.
+	PID int
+	// Name of the executable of the process.
+	Name string
+	// Path is the absolute path of the executable of the process.
+	Path string
+	// UID is the user that owns the socket.
+	UID uint32
+	// GID is the real group of the process.
+	GID uint32
+}
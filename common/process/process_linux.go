@@ -0,0 +1,288 @@
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"bufio"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common/net"
+)
+
+// procRoot is the mount point of procfs.
+var procRoot = "/proc"
+
+// littleEndian indicates whether addresses in /proc/net are printed as little endian words.
+var littleEndian = func() bool {
+	switch runtime.GOARCH {
+	case "armbe", "arm64be", "mips", "mips64", "mips64p32", "ppc", "ppc64", "s390", "s390x", "sparc", "sparc64":
+		return false
+	default:
+		return true
+	}
+}()
+
+// localAddresses caches the addresses assigned to the interfaces of this host.
+var localAddresses = struct {
+	sync.Mutex
+	ips       []net.IP
+	updatedAt time.Time
+}{}
+
+// isLocalAddress reports whether ip is assigned to this host. Sockets of other addresses are never found.
+var isLocalAddress = func(ip net.IP) bool {
+	if ip.IsLoopback() {
+		return true
+	}
+
+	localAddresses.Lock()
+	defer localAddresses.Unlock()
+
+	if time.Since(localAddresses.updatedAt) > time.Second*10 {
+		localAddresses.ips = localAddresses.ips[:0]
+		if addrs, err := net.InterfaceAddrs(); err == nil {
+			for _, addr := range addrs {
+				if ipNet, ok := addr.(*net.IPNet); ok {
+					localAddresses.ips = append(localAddresses.ips, ipNet.IP)
+				}
+			}
+		}
+		localAddresses.updatedAt = time.Now()
+	}
+	for _, localIP := range localAddresses.ips {
+		if localIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindProcess returns the local process that owns the socket bound to the local address.
+// The socket is looked up in /proc/net/{tcp,udp}{,6}, and its inode is then searched in the file descriptors of all processes.
+// If the socket is found but its process is not accessible, the returned Info only has UID.
+// Addresses that are not assigned to this host are not looked up.
+func FindProcess(local net.Destination) (*Info, error) {
+	if !local.IsValid() || !local.Address.Family().IsIP() || !isLocalAddress(local.Address.IP()) {
+		return nil, ErrNotFound
+	}
+	var tables []string
+	switch local.Network {
+	case net.Network_TCP:
+		tables = []string{"tcp", "tcp6"}
+	case net.Network_UDP:
+		tables = []string{"udp", "udp6"}
+	default:
+		return nil, ErrNotFound
+	}
+
+	inode, uid, err := findSocket(tables, local.Address.IP(), uint16(local.Port), local.Network == net.Network_UDP)
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{
+		UID: uid,
+	}
+	if pid, found := findProcessBySocket(inode); found {
+		readProcess(info, pid)
+	}
+	return info, nil
+}
+
+// findSocket returns the inode and the owner of the socket bound to ip and port.
+// If wildcard is true, a socket bound to the unspecified address with the same port is used if there is no exact match,
+// as unconnected UDP sockets are.
+func findSocket(tables []string, ip net.IP, port uint16, wildcard bool) (string, uint32, error) {
+	var fallbackInode string
+	var fallbackUID uint32
+	for _, table := range tables {
+		file, err := os.Open(filepath.Join(procRoot, "net", table))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Scan() // header
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 || fields[9] == "0" {
+				continue
+			}
+			socketIP, socketPort, err := parseAddress(fields[1])
+			if err != nil || socketPort != port {
+				continue
+			}
+			uid, err := strconv.ParseUint(fields[7], 10, 32)
+			if err != nil {
+				continue
+			}
+			if socketIP.Equal(ip) {
+				file.Close()
+				return fields[9], uint32(uid), nil
+			}
+			if wildcard && socketIP.IsUnspecified() && fallbackInode == "" {
+				fallbackInode = fields[9]
+				fallbackUID = uint32(uid)
+			}
+		}
+		file.Close()
+	}
+	if fallbackInode != "" {
+		return fallbackInode, fallbackUID, nil
+	}
+	return "", 0, ErrNotFound
+}
+
+// parseAddress parses an address in /proc/net, like "0100007F:1F90".
+func parseAddress(s string) (net.IP, uint16, error) {
+	host, port, found := strings.Cut(s, ":")
+	if !found {
+		return nil, 0, newError("invalid address: ", s)
+	}
+	ip, err := hex.DecodeString(host)
+	if err != nil || (len(ip) != net.IPv4len && len(ip) != net.IPv6len) {
+		return nil, 0, newError("invalid address: ", s)
+	}
+	if littleEndian {
+		for i := 0; i < len(ip); i += 4 {
+			ip[i], ip[i+1], ip[i+2], ip[i+3] = ip[i+3], ip[i+2], ip[i+1], ip[i]
+		}
+	}
+	p, err := strconv.ParseUint(port, 16, 16)
+	if err != nil {
+		return nil, 0, newError("invalid address: ", s).Base(err)
+	}
+	return net.IP(ip), uint16(p), nil
+}
+
+// socketOwnerTTL is how long the process of a socket is remembered.
+const socketOwnerTTL = time.Minute
+
+// maxRecentOwners is the number of processes that owned sockets recently, which are searched before all the others.
+const maxRecentOwners = 8
+
+type socketOwner struct {
+	pid      int
+	expireAt time.Time
+}
+
+// socketOwners caches the processes of sockets, as searching all processes is expensive.
+// A cached process is checked to still have the socket before it is used.
+var socketOwners = struct {
+	sync.Mutex
+	owners map[string]socketOwner
+	recent []int
+}{
+	owners: make(map[string]socketOwner),
+}
+
+// findProcessBySocket returns the process that has a file descriptor of the socket.
+// The last owner of the socket and the processes that owned other sockets recently are searched first.
+func findProcessBySocket(inode string) (int, bool) {
+	target := "socket:[" + inode + "]"
+
+	socketOwners.Lock()
+	owner, cached := socketOwners.owners[inode]
+	candidates := make([]int, 0, maxRecentOwners+1)
+	if cached && time.Now().Before(owner.expireAt) {
+		candidates = append(candidates, owner.pid)
+	}
+	candidates = append(candidates, socketOwners.recent...)
+	socketOwners.Unlock()
+
+	for _, pid := range candidates {
+		if hasSocket(strconv.Itoa(pid), target) {
+			rememberSocketOwner(inode, pid)
+			return pid, true
+		}
+	}
+
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return 0, false
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		if hasSocket(entry.Name(), target) {
+			rememberSocketOwner(inode, pid)
+			return pid, true
+		}
+	}
+	return 0, false
+}
+
+// hasSocket returns whether the process has a file descriptor linked to target.
+func hasSocket(pid string, target string) bool {
+	fdDir := filepath.Join(procRoot, pid, "fd")
+	fds, err := os.ReadDir(fdDir)
+	if err != nil {
+		return false
+	}
+	for _, fd := range fds {
+		if link, err := os.Readlink(filepath.Join(fdDir, fd.Name())); err == nil && link == target {
+			return true
+		}
+	}
+	return false
+}
+
+func rememberSocketOwner(inode string, pid int) {
+	socketOwners.Lock()
+	defer socketOwners.Unlock()
+
+	now := time.Now()
+	if len(socketOwners.owners) >= 1024 {
+		for key, owner := range socketOwners.owners {
+			if now.After(owner.expireAt) {
+				delete(socketOwners.owners, key)
+			}
+		}
+		if len(socketOwners.owners) >= 1024 {
+			socketOwners.owners = make(map[string]socketOwner)
+		}
+	}
+	socketOwners.owners[inode] = socketOwner{pid: pid, expireAt: now.Add(socketOwnerTTL)}
+
+	recent := append(make([]int, 0, maxRecentOwners), pid)
+	for _, p := range socketOwners.recent {
+		if p != pid && len(recent) < maxRecentOwners {
+			recent = append(recent, p)
+		}
+	}
+	socketOwners.recent = recent
+}
+
+func readProcess(info *Info, pid int) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	info.PID = pid
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		info.Path = strings.TrimSuffix(exe, " (deleted)")
+		info.Name = filepath.Base(info.Path)
+	} else if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+		info.Name = strings.TrimSpace(string(comm))
+	}
+
+	status, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if strings.HasPrefix(line, "Gid:") {
+			if fields := strings.Fields(line[len("Gid:"):]); len(fields) > 0 {
+				if gid, err := strconv.ParseUint(fields[0], 10, 32); err == nil {
+					info.GID = uint32(gid)
+				}
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,112 @@
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+)
+
+func TestFindProcessInProcfs(t *testing.T) {
+	root := t.TempDir()
+	defer func(oldRoot string) {
+		procRoot = oldRoot
+	}(procRoot)
+	procRoot = root
+	defer func(oldIsLocalAddress func(net.IP) bool) {
+		isLocalAddress = oldIsLocalAddress
+	}(isLocalAddress)
+	isLocalAddress = func(ip net.IP) bool {
+		return ip.IsLoopback() || ip.Equal(net.ParseIP("10.0.0.2"))
+	}
+
+	loopback := "0100007F"
+	if !littleEndian {
+		loopback = "7F000001"
+	}
+	writeFile := func(name, content string) {
+		name = filepath.Join(root, name)
+		common.Must(os.MkdirAll(filepath.Dir(name), 0o755))
+		common.Must(os.WriteFile(name, []byte(content), 0o644))
+	}
+	writeFile("net/tcp", "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"+
+		"   0: "+loopback+":1F90 "+loopback+":0050 01 00000000:00000000 00:00000000 00000000  1000        0 5555 1 0000000000000000 20 4 30 10 -1\n")
+	writeFile("net/udp", "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops\n"+
+		"   1: 00000000:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 6666 2 0000000000000000 0\n")
+	writeFile("1234/status", "Name:\tcurl\nUid:\t1000\t1000\t1000\t1000\nGid:\t100\t100\t100\t100\n")
+	common.Must(os.MkdirAll(filepath.Join(root, "1234", "fd"), 0o755))
+	common.Must(os.Symlink("socket:[5555]", filepath.Join(root, "1234", "fd", "3")))
+	common.Must(os.Symlink("/usr/bin/curl", filepath.Join(root, "1234", "exe")))
+
+	cases := []struct {
+		local  net.Destination
+		info   *Info
+		hasErr bool
+	}{
+		{
+			local: net.TCPDestination(net.LocalHostIP, 8080),
+			info:  &Info{PID: 1234, Name: "curl", Path: "/usr/bin/curl", UID: 1000, GID: 100},
+		},
+		{
+			local: net.UDPDestination(net.ParseAddress("10.0.0.2"), 53),
+			info:  &Info{UID: 101},
+		},
+		{
+			// Not a local address, even if a socket is bound to all addresses.
+			local:  net.UDPDestination(net.ParseAddress("192.168.1.2"), 53),
+			hasErr: true,
+		},
+		{
+			local:  net.TCPDestination(net.ParseAddress("192.168.1.2"), 8080),
+			hasErr: true,
+		},
+		{
+			local:  net.TCPDestination(net.LocalHostIP, 53),
+			hasErr: true,
+		},
+	}
+	for _, test := range cases {
+		info, err := FindProcess(test.local)
+		if test.hasErr {
+			if err == nil {
+				t.Error("expect error for ", test.local, ", but got ", info)
+			}
+			continue
+		}
+		common.Must(err)
+		if r := cmp.Diff(info, test.info); r != "" {
+			t.Error(test.local, r)
+		}
+	}
+
+	// The cached process is not used after it closes the socket.
+	common.Must(os.Remove(filepath.Join(root, "1234", "fd", "3")))
+	info, err := FindProcess(net.TCPDestination(net.LocalHostIP, 8080))
+	common.Must(err)
+	if r := cmp.Diff(info, &Info{UID: 1000}); r != "" {
+		t.Error(r)
+	}
+}
+
+func TestFindProcessOfSelf(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	common.Must(err)
+	defer conn.Close()
+
+	info, err := FindProcess(net.DestinationFromAddr(conn.LocalAddr()))
+	common.Must(err)
+	if info.PID != os.Getpid() || info.UID != uint32(os.Getuid()) {
+		t.Error("unexpected process: ", info)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package process
+
+import (
+	"github.com/v2fly/v2ray-core/v5/common/net"
+)
+
+// FindProcess returns the local process that owns the socket bound to the local address.
+// It is only supported on Linux.
+func FindProcess(local net.Destination) (*Info, error) {
+	return nil, newError("process lookup is not supported on this platform")
+}
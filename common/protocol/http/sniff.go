@@ -3,6 +3,7 @@ package http
 import (
 	"bytes"
 	"errors"
+	"net/url"
 	"strings"
 
 	"github.com/v2fly/v2ray-core/v5/common"
@@ -19,6 +20,9 @@ const (
 type SniffHeader struct {
 	version version
 	host    string
+	method  string
+	path    string
+	headers map[string]string
 }
 
 func (h *SniffHeader) Protocol() string {
@@ -36,6 +40,17 @@ func (h *SniffHeader) Domain() string {
 	return h.host
 }
 
+// Attributes returns the method, the path and the headers of the request, named in the same way as the HTTP inbound does.
+func (h *SniffHeader) Attributes() map[string]string {
+	attrs := make(map[string]string, len(h.headers)+2)
+	for key, value := range h.headers {
+		attrs[key] = value
+	}
+	attrs[":method"] = h.method
+	attrs[":path"] = h.path
+	return attrs
+}
+
 var (
 	// refer to https://pkg.go.dev/net/http@master#pkg-constants
 	methods = [...]string{"get", "post", "head", "put", "delete", "options", "connect", "patch", "trace"}
@@ -64,11 +79,19 @@ func SniffHTTP(b []byte) (*SniffHeader, error) {
 
 	sh := &SniffHeader{
 		version: HTTP1,
+		headers: make(map[string]string),
 	}
 
 	headers := bytes.Split(b, []byte{'\n'})
+	if requestLine := strings.Fields(string(headers[0])); len(requestLine) >= 2 {
+		sh.method = strings.ToUpper(requestLine[0])
+		sh.path = requestLine[1]
+		if u, err := url.ParseRequestURI(requestLine[1]); err == nil {
+			sh.path = u.Path
+		}
+	}
 	for i := 1; i < len(headers); i++ {
-		header := headers[i]
+		header := bytes.TrimSuffix(headers[i], []byte{'\r'})
 		if len(header) == 0 {
 			break
 		}
@@ -77,6 +100,9 @@ func SniffHTTP(b []byte) (*SniffHeader, error) {
 			continue
 		}
 		key := strings.ToLower(string(parts[0]))
+		if _, found := sh.headers[key]; !found {
+			sh.headers[key] = string(bytes.TrimSpace(parts[1]))
+		}
 		if key == "host" {
 			rawHost := strings.ToLower(string(bytes.TrimSpace(parts[1])))
 			dest, err := ParseHost(rawHost, net.Port(80))
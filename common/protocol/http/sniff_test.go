@@ -103,3 +103,25 @@ first_name=John&last_name=Doe&action=Submit`,
 		}
 	}
 }
+
+func TestHTTPAttributes(t *testing.T) {
+	header, err := SniffHTTP([]byte("POST http://v2fly.org/api/v1?q=1 HTTP/1.1\r\nHost: v2fly.org\r\nX-Forwarded-For: 10.0.0.1\r\nx-forwarded-for: 10.0.0.2\r\n\r\nbody: value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs := header.Attributes()
+	expected := map[string]string{
+		":method":         "POST",
+		":path":           "/api/v1",
+		"host":            "v2fly.org",
+		"x-forwarded-for": "10.0.0.1",
+	}
+	if len(attrs) != len(expected) {
+		t.Error("unexpected attributes: ", attrs)
+	}
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Error("expected ", key, " to be ", value, " but got ", attrs[key])
+		}
+	}
+}
@@ -4,9 +4,11 @@ package session
 import (
 	"context"
 	"math/rand"
+	"sync"
 
 	"github.com/v2fly/v2ray-core/v5/common/errors"
 	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/process"
 	"github.com/v2fly/v2ray-core/v5/common/protocol"
 )
 
@@ -45,6 +47,28 @@ type Inbound struct {
 	User *protocol.MemoryUser
 	// Conn is actually internet.Connection. May be nil.
 	Conn net.Conn
+	// Process is the local process that originates the connection. It may be nil. It is looked up on demand
+	// by GetProcess, and must not be set once the Inbound is shared.
+	Process *process.Info
+	// Path is the transport path that the connection is accepted on, like the WebSocket path. May be empty.
+	Path string
+	// PathTag is the tag of the transport path entry that the connection matches. May be empty.
+	PathTag string
+
+	processOnce sync.Once
+}
+
+// GetProcess returns the local process that originates the connection, or nil if it is not found.
+// The process is looked up on the first call, which is safe for concurrent use.
+func (i *Inbound) GetProcess() *process.Info {
+	i.processOnce.Do(func() {
+		if i.Process == nil && i.Source.IsValid() {
+			if info, err := process.FindProcess(i.Source); err == nil {
+				i.Process = info
+			}
+		}
+	})
+	return i.Process
 }
 
 // Outbound is the metadata of an outbound connection.
@@ -0,0 +1,44 @@
+//go:build linux
+// +build linux
+
+package session_test
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/process"
+	. "github.com/v2fly/v2ray-core/v5/common/session"
+)
+
+func TestInboundGetProcessConcurrently(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	common.Must(err)
+	defer conn.Close()
+
+	inbound := &Inbound{Source: net.DestinationFromAddr(conn.LocalAddr())}
+
+	var wg sync.WaitGroup
+	infos := make([]*process.Info, 8)
+	for i := range infos {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			infos[i] = inbound.GetProcess()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, info := range infos {
+		if info == nil || info != infos[0] || info.PID != os.Getpid() {
+			t.Fatal("unexpected process: ", info)
+		}
+	}
+}
@@ -38,6 +38,16 @@ type IPv6Lookup interface {
 	LookupIPv6(domain string) ([]net.IP, error)
 }
 
+// IPLookupWithOption is an optional feature for querying IP addresses with the given option
+// instead of the one of the client, which is shared by all its users.
+//
+// v2ray:api:beta
+type IPLookupWithOption interface {
+	// LookupIPWithOption returns IP addresses for the given domain. Only the address families enabled
+	// by both the option and the query strategy of the client are queried.
+	LookupIPWithOption(domain string, option IPOption) ([]net.IP, error)
+}
+
 // ClientWithIPOption is an optional feature for querying DNS information.
 //
 // v2ray:api:beta
@@ -13,3 +13,7 @@ type PersistentStorageEngine interface {
 	Get(ctx context.Context, key []byte) ([]byte, error)
 	List(ctx context.Context, keyPrefix []byte) ([][]byte, error)
 }
+
+func PersistentStorageEngineType() interface{} {
+	return (*PersistentStorageEngine)(nil)
+}
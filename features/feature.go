@@ -21,3 +21,10 @@ type TaggedFeatures interface {
 	GetFeaturesByTag(tag string) (Feature, error)
 	common.Runnable
 }
+
+// ConfigReloader is implemented by features that can apply a changed config
+// while running. The config has the same type as the one the feature was
+// created from.
+type ConfigReloader interface {
+	ReloadConfig(config interface{}) error
+}
@@ -5,7 +5,9 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/platform"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/features"
 )
 
@@ -27,6 +29,8 @@ type Stats struct {
 	UserUplink bool
 	// Whether or not to enable stat counter for user downlink traffic.
 	UserDownlink bool
+	// Whether or not to track the source IPs of online users.
+	UserOnline bool
 }
 
 // Buffer contains settings for internal buffer.
@@ -73,6 +77,26 @@ type Manager interface {
 	ForSystem() System
 }
 
+// UsageTracker accounts the traffic of a single connection against the limits of its user.
+type UsageTracker interface {
+	common.Closable
+
+	// Uplink accounts n bytes sent by the user. It blocks as long as the uplink rate limit requires,
+	// and returns an error once the traffic quota of the user is exhausted.
+	Uplink(n int64) error
+
+	// Downlink accounts n bytes sent to the user, see Uplink.
+	Downlink(n int64) error
+}
+
+// UserLimiter is implemented by a Manager that enforces usage limits on users.
+type UserLimiter interface {
+	// TrackUser registers a new connection of the given user from the source IP, which may be empty if unknown.
+	// It returns an error if the user is not allowed to open another connection, or a nil UsageTracker if no
+	// limits apply to the user. Waiting for the rate limits ends when ctx is done.
+	TrackUser(ctx context.Context, user *protocol.MemoryUser, ip string) (UsageTracker, error)
+}
+
 // ManagerType returns the type of Manager interface. Can be used to implement common.HasType.
 //
 // v2ray:api:stable
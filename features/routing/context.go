@@ -2,6 +2,7 @@ package routing
 
 import (
 	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/process"
 )
 
 // Context is a feature to store connection information for routing.
@@ -41,3 +42,13 @@ type Context interface {
 	// GetSkipDNSResolve returns a flag switch for weather skip dns resolve during route pick.
 	GetSkipDNSResolve() bool
 }
+
+// ProcessContext is an optional extension of Context for connections generated by local processes.
+//
+// v2ray:api:beta
+type ProcessContext interface {
+	Context
+
+	// GetProcess returns the local process that originates the connection, or nil if it is not a local connection.
+	GetProcess() *process.Info
+}
@@ -4,6 +4,7 @@ package dns
 
 import (
 	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/process"
 	"github.com/v2fly/v2ray-core/v5/features/dns"
 	"github.com/v2fly/v2ray-core/v5/features/routing"
 )
@@ -15,6 +16,14 @@ type ResolvableContext struct {
 	resolvedIPs []net.IP
 }
 
+// GetProcess implements routing.ProcessContext.
+func (ctx *ResolvableContext) GetProcess() *process.Info {
+	if processCtx, ok := ctx.Context.(routing.ProcessContext); ok {
+		return processCtx.GetProcess()
+	}
+	return nil
+}
+
 // GetTargetIPs overrides original routing.Context's implementation.
 func (ctx *ResolvableContext) GetTargetIPs() []net.IP {
 	if len(ctx.resolvedIPs) > 0 {
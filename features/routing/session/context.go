@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/process"
 	"github.com/v2fly/v2ray-core/v5/common/session"
 	"github.com/v2fly/v2ray-core/v5/features/routing"
 )
@@ -122,6 +123,14 @@ func (ctx *Context) GetSkipDNSResolve() bool {
 	return ctx.Content.SkipDNSResolve
 }
 
+// GetProcess implements routing.ProcessContext.
+func (ctx *Context) GetProcess() *process.Info {
+	if ctx.Inbound == nil {
+		return nil
+	}
+	return ctx.Inbound.GetProcess()
+}
+
 // AsRoutingContext creates a context from context.context with session info.
 func AsRoutingContext(ctx context.Context) routing.Context {
 	return &Context{
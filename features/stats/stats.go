@@ -4,6 +4,7 @@ package stats
 
 import (
 	"context"
+	"time"
 
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/features"
@@ -79,6 +80,16 @@ type Manager interface {
 	GetChannel(string) Channel
 }
 
+// OnlineTracker is implemented by a Manager that keeps track of the source IPs users are connected from.
+type OnlineTracker interface {
+	// AddOnlineIP records a new connection of the user from the given IP.
+	AddOnlineIP(email string, ip string)
+	// RemoveOnlineIP records that a connection of the user from the given IP is closed.
+	RemoveOnlineIP(email string, ip string)
+	// GetOnlineIPs returns the IPs the user is currently connected from, along with the time each of them came online.
+	GetOnlineIPs(email string) map[string]time.Time
+}
+
 // GetOrRegisterCounter tries to get the StatCounter first. If not exist, it then tries to create a new counter.
 func GetOrRegisterCounter(m Manager, name string) (Counter, error) {
 	counter := m.GetCounter(name)
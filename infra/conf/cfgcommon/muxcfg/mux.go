@@ -3,8 +3,12 @@ package muxcfg
 import "github.com/v2fly/v2ray-core/v5/app/proxyman"
 
 type MuxConfig struct {
-	Enabled     bool  `json:"enabled"`
-	Concurrency int16 `json:"concurrency"`
+	Enabled        bool   `json:"enabled"`
+	Concurrency    int16  `json:"concurrency"`
+	IdleTimeout    uint32 `json:"idleTimeout"`
+	MaxConnections uint32 `json:"maxConnections"`
+	MaxReuse       uint32 `json:"maxReuse"`
+	MaxBytes       uint64 `json:"maxBytes"`
 }
 
 // Build creates MultiplexingConfig, Concurrency < 0 completely disables mux.
@@ -19,7 +23,11 @@ func (m *MuxConfig) Build() *proxyman.MultiplexingConfig {
 	}
 
 	return &proxyman.MultiplexingConfig{
-		Enabled:     m.Enabled,
-		Concurrency: con,
+		Enabled:        m.Enabled,
+		Concurrency:    con,
+		IdleTimeout:    m.IdleTimeout,
+		MaxConnections: m.MaxConnections,
+		MaxReuse:       m.MaxReuse,
+		MaxBytes:       m.MaxBytes,
 	}
 }
@@ -22,6 +22,7 @@ type TLSConfig struct {
 	DisableSystemRoot                bool                  `json:"disableSystemRoot"`
 	PinnedPeerCertificateChainSha256 *[]string             `json:"pinnedPeerCertificateChainSha256"`
 	VerifyClientCertificate          bool                  `json:"verifyClientCertificate"`
+	ACME                             *ACMEConfig           `json:"acme"`
 }
 
 // Build implements Buildable.
@@ -58,9 +59,39 @@ func (c *TLSConfig) Build() (proto.Message, error) {
 		}
 	}
 
+	if c.ACME != nil {
+		acme, err := c.ACME.Build()
+		if err != nil {
+			return nil, newError("failed to build ACME config").Base(err)
+		}
+		config.Acme = acme
+	}
+
 	return config, nil
 }
 
+type ACMEConfig struct {
+	Domains      *cfgcommon.StringList `json:"domains"`
+	Email        string                `json:"email"`
+	DirectoryURL string                `json:"directoryUrl"`
+	StorageDir   string                `json:"storageDir"`
+	RenewBefore  uint32                `json:"renewBefore"`
+}
+
+// Build implements Buildable.
+func (c *ACMEConfig) Build() (*tls.ACME, error) {
+	if c.Domains == nil || len(*c.Domains) == 0 {
+		return nil, newError("no domain specified")
+	}
+	return &tls.ACME{
+		Domain:       []string(*c.Domains),
+		Email:        c.Email,
+		DirectoryUrl: c.DirectoryURL,
+		StorageDir:   c.StorageDir,
+		RenewBefore:  c.RenewBefore,
+	}, nil
+}
+
 type TLSCertConfig struct {
 	CertFile string   `json:"certificateFile"`
 	CertStr  []string `json:"certificate"`
@@ -78,6 +109,7 @@ func (c *TLSCertConfig) Build() (*tls.Certificate, error) {
 		return nil, newError("failed to parse certificate").Base(err)
 	}
 	certificate.Certificate = cert
+	certificate.CertificateFile = c.CertFile
 
 	if len(c.KeyFile) > 0 || len(c.KeyStr) > 0 {
 		key, err := readFileOrString(c.KeyFile, c.KeyStr)
@@ -85,6 +117,7 @@ func (c *TLSCertConfig) Build() (*tls.Certificate, error) {
 			return nil, newError("failed to parse key").Base(err)
 		}
 		certificate.Key = key
+		certificate.KeyFile = c.KeyFile
 	}
 
 	switch strings.ToLower(c.Usage) {
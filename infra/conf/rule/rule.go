@@ -204,6 +204,7 @@ func toCidrList(ctx context.Context, ips cfgcommon.StringList) ([]*routercommon.
 func parseFieldRule(ctx context.Context, msg json.RawMessage) (*router.RoutingRule, error) {
 	type RawFieldRule struct {
 		RouterRule
+		RuleTag    string                 `json:"ruleTag"`
 		Domain     *cfgcommon.StringList  `json:"domain"`
 		Domains    *cfgcommon.StringList  `json:"domains"`
 		IP         *cfgcommon.StringList  `json:"ip"`
@@ -215,6 +216,11 @@ func parseFieldRule(ctx context.Context, msg json.RawMessage) (*router.RoutingRu
 		InboundTag *cfgcommon.StringList  `json:"inboundTag"`
 		Protocols  *cfgcommon.StringList  `json:"protocol"`
 		Attributes string                 `json:"attrs"`
+		TimeRange  *cfgcommon.StringList  `json:"timeRange"`
+		TimeZone   string                 `json:"timeZone"`
+		Process    *cfgcommon.StringList  `json:"process"`
+		ProcessUID []uint32               `json:"processUid"`
+		ProcessGID []uint32               `json:"processGid"`
 	}
 	rawFieldRule := new(RawFieldRule)
 	err := json.Unmarshal(msg, rawFieldRule)
@@ -240,6 +246,8 @@ func parseFieldRule(ctx context.Context, msg json.RawMessage) (*router.RoutingRu
 		rule.DomainMatcher = rawFieldRule.DomainMatcher
 	}
 
+	rule.RuleTag = rawFieldRule.RuleTag
+
 	if rawFieldRule.Domain != nil {
 		for _, domain := range *rawFieldRule.Domain {
 			rules, err := parseDomainRule(ctx, domain)
@@ -310,6 +318,18 @@ func parseFieldRule(ctx context.Context, msg json.RawMessage) (*router.RoutingRu
 		rule.Attributes = rawFieldRule.Attributes
 	}
 
+	if rawFieldRule.TimeRange != nil {
+		rule.TimeRange = *rawFieldRule.TimeRange
+		rule.TimeZone = rawFieldRule.TimeZone
+	}
+
+	if rawFieldRule.Process != nil {
+		rule.ProcessName = *rawFieldRule.Process
+	}
+
+	rule.ProcessUid = rawFieldRule.ProcessUID
+	rule.ProcessGid = rawFieldRule.ProcessGID
+
 	return rule, nil
 }
 
@@ -32,6 +32,14 @@ func TestLoaderError(t *testing.T) {
 		}`,
 			Output: "line 5 char 5",
 		},
+		{
+			Input: `{
+				"log": {
+					"accessFormat": "xml"
+				}
+		}`,
+			Output: "unknown log format: xml",
+		},
 		{
 			Input: `{
 				"port": 1,
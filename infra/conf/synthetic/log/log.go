@@ -1,5 +1,7 @@
 package log
 
+//go:generate go run github.com/v2fly/v2ray-core/v5/common/errors/errorgen
+
 import (
 	"strings"
 
@@ -15,18 +17,63 @@ func DefaultLogConfig() *log.Config {
 }
 
 type LogConfig struct { // nolint: revive
-	AccessLog string `json:"access"`
-	ErrorLog  string `json:"error"`
-	LogLevel  string `json:"loglevel"`
+	AccessLog    string `json:"access"`
+	ErrorLog     string `json:"error"`
+	LogLevel     string `json:"loglevel"`
+	AccessFormat string `json:"accessFormat"`
+	ErrorFormat  string `json:"errorFormat"`
+
+	Rotation *LogRotationConfig `json:"rotation"`
 }
 
-func (v *LogConfig) Build() *log.Config {
-	if v == nil {
+// LogRotationConfig applies to both access and error log files.
+type LogRotationConfig struct { // nolint: revive
+	// MaxSize is in megabytes.
+	MaxSize uint64 `json:"maxSize"`
+	// MaxAge is in hours.
+	MaxAge     uint32 `json:"maxAge"`
+	MaxBackups uint32 `json:"maxBackups"`
+	Compress   bool   `json:"compress"`
+}
+
+func (c *LogRotationConfig) Build() *log.LogRotation {
+	if c == nil {
 		return nil
 	}
+	return &log.LogRotation{
+		MaxSize:    c.MaxSize * 1024 * 1024,
+		MaxAge:     c.MaxAge * 3600,
+		MaxBackups: c.MaxBackups,
+		Compress:   c.Compress,
+	}
+}
+
+func parseFormat(format string) (log.LogFormat, error) {
+	switch strings.ToLower(format) {
+	case "", "text":
+		return log.LogFormat_Text, nil
+	case "json":
+		return log.LogFormat_JSON, nil
+	default:
+		return log.LogFormat_Text, newError("unknown log format: ", format)
+	}
+}
+
+func (v *LogConfig) Build() (*log.Config, error) {
+	if v == nil {
+		return nil, nil
+	}
+	accessFormat, err := parseFormat(v.AccessFormat)
+	if err != nil {
+		return nil, newError("invalid accessFormat").Base(err)
+	}
+	errorFormat, err := parseFormat(v.ErrorFormat)
+	if err != nil {
+		return nil, newError("invalid errorFormat").Base(err)
+	}
 	config := &log.Config{
-		Access: &log.LogSpecification{Type: log.LogType_Console},
-		Error:  &log.LogSpecification{Type: log.LogType_Console},
+		Access: &log.LogSpecification{Type: log.LogType_Console, Format: accessFormat},
+		Error:  &log.LogSpecification{Type: log.LogType_Console, Format: errorFormat},
 	}
 
 	if v.AccessLog == "none" {
@@ -34,12 +81,14 @@ func (v *LogConfig) Build() *log.Config {
 	} else if len(v.AccessLog) > 0 {
 		config.Access.Path = v.AccessLog
 		config.Access.Type = log.LogType_File
+		config.Access.Rotation = v.Rotation.Build()
 	}
 	if v.ErrorLog == "none" {
 		config.Error.Type = log.LogType_None
 	} else if len(v.ErrorLog) > 0 {
 		config.Error.Path = v.ErrorLog
 		config.Error.Type = log.LogType_File
+		config.Error.Rotation = v.Rotation.Build()
 	}
 
 	level := strings.ToLower(v.LogLevel)
@@ -56,5 +105,5 @@ func (v *LogConfig) Build() *log.Config {
 	default:
 		config.Error.Level = clog.Severity_Warning
 	}
-	return config
+	return config, nil
 }
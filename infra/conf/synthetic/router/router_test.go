@@ -66,7 +66,8 @@ func TestRouterConfig(t *testing.T) {
 						},{
 							"type": "field",
 							"port": 123,
-							"outboundTag": "test"
+							"outboundTag": "test",
+							"ruleTag": "ntp"
 						}
 					]
 				},
@@ -208,6 +209,7 @@ func TestRouterConfig(t *testing.T) {
 						TargetTag: &router.RoutingRule_Tag{
 							Tag: "test",
 						},
+						RuleTag: "ntp",
 					},
 				},
 			},
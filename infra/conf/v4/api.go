@@ -11,6 +11,7 @@ import (
 	loggerservice "github.com/v2fly/v2ray-core/v5/app/log/command"
 	observatoryservice "github.com/v2fly/v2ray-core/v5/app/observatory/command"
 	handlerservice "github.com/v2fly/v2ray-core/v5/app/proxyman/command"
+	reverseservice "github.com/v2fly/v2ray-core/v5/app/reverse/command"
 	routerservice "github.com/v2fly/v2ray-core/v5/app/router/command"
 	statsservice "github.com/v2fly/v2ray-core/v5/app/stats/command"
 	"github.com/v2fly/v2ray-core/v5/common/serial"
@@ -41,6 +42,8 @@ func (c *APIConfig) Build() (*commander.Config, error) {
 			services = append(services, serial.ToTypedMessage(&observatoryservice.Config{}))
 		case "routingservice":
 			services = append(services, serial.ToTypedMessage(&routerservice.Config{}))
+		case "reverseservice":
+			services = append(services, serial.ToTypedMessage(&reverseservice.Config{}))
 		default:
 			if !strings.HasPrefix(s, "#") {
 				continue
@@ -28,3 +28,39 @@ func (c *DNSOutboundConfig) Build() (proto.Message, error) {
 	}
 	return config, nil
 }
+
+type DNSInboundConfig struct {
+	DoHPath   string             `json:"dohPath"`
+	UserLevel uint32             `json:"userLevel"`
+	Upstream  *DNSUpstreamConfig `json:"upstream"`
+	TTL       uint32             `json:"ttl"`
+}
+
+// DNSUpstreamConfig is the server that the DNS inbound forwards queries other than A and AAAA to.
+type DNSUpstreamConfig struct {
+	Network cfgcommon.Network  `json:"network"`
+	Address *cfgcommon.Address `json:"address"`
+	Port    uint16             `json:"port"`
+}
+
+func (c *DNSInboundConfig) Build() (proto.Message, error) {
+	if c.DoHPath != "" && c.DoHPath[0] != '/' {
+		return nil, newError("dohPath must start with /: ", c.DoHPath)
+	}
+	config := &dns.ServerConfig{
+		UserLevel: c.UserLevel,
+		DohPath:   c.DoHPath,
+		Ttl:       c.TTL,
+	}
+	if c.Upstream != nil {
+		if c.Upstream.Address == nil {
+			return nil, newError("upstream address is not specified")
+		}
+		config.Upstream = &net.Endpoint{
+			Network: c.Upstream.Network.Build(),
+			Address: c.Upstream.Address.Build(),
+			Port:    uint32(c.Upstream.Port),
+		}
+	}
+	return config, nil
+}
@@ -33,3 +33,38 @@ func TestDnsProxyConfig(t *testing.T) {
 		},
 	})
 }
+
+func TestDnsInboundConfig(t *testing.T) {
+	creator := func() cfgcommon.Buildable {
+		return new(v4.DNSInboundConfig)
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"dohPath": "/dns-query",
+				"userLevel": 1
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &dns.ServerConfig{
+				DohPath:   "/dns-query",
+				UserLevel: 1,
+			},
+		},
+		{
+			Input: `{
+				"upstream": {
+					"address": "8.8.8.8"
+				},
+				"ttl": 60
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &dns.ServerConfig{
+				Upstream: &net.Endpoint{
+					Address: net.NewIPOrDomain(net.IPAddress([]byte{8, 8, 8, 8})),
+				},
+				Ttl: 60,
+			},
+		},
+	})
+}
@@ -4,14 +4,39 @@ import (
 	"github.com/v2fly/v2ray-core/v5/app/policy"
 )
 
+type PolicyLimit struct {
+	Quota          uint64  `json:"quota"`
+	QuotaWindow    *uint32 `json:"quotaWindow"`
+	UplinkRate     uint64  `json:"uplinkRate"`
+	DownlinkRate   uint64  `json:"downlinkRate"`
+	MaxConnections uint32  `json:"maxConnections"`
+	MaxIPs         uint32  `json:"maxIPs"`
+}
+
+func (l *PolicyLimit) Build() *policy.Policy_Limit {
+	config := &policy.Policy_Limit{
+		Quota:          l.Quota,
+		UplinkRate:     l.UplinkRate,
+		DownlinkRate:   l.DownlinkRate,
+		MaxConnections: l.MaxConnections,
+		MaxIps:         l.MaxIPs,
+	}
+	if l.QuotaWindow != nil {
+		config.QuotaWindow = &policy.Second{Value: *l.QuotaWindow}
+	}
+	return config
+}
+
 type Policy struct {
-	Handshake         *uint32 `json:"handshake"`
-	ConnectionIdle    *uint32 `json:"connIdle"`
-	UplinkOnly        *uint32 `json:"uplinkOnly"`
-	DownlinkOnly      *uint32 `json:"downlinkOnly"`
-	StatsUserUplink   bool    `json:"statsUserUplink"`
-	StatsUserDownlink bool    `json:"statsUserDownlink"`
-	BufferSize        *int32  `json:"bufferSize"`
+	Handshake         *uint32      `json:"handshake"`
+	ConnectionIdle    *uint32      `json:"connIdle"`
+	UplinkOnly        *uint32      `json:"uplinkOnly"`
+	DownlinkOnly      *uint32      `json:"downlinkOnly"`
+	StatsUserUplink   bool         `json:"statsUserUplink"`
+	StatsUserDownlink bool         `json:"statsUserDownlink"`
+	StatsUserOnline   bool         `json:"statsUserOnline"`
+	BufferSize        *int32       `json:"bufferSize"`
+	Limit             *PolicyLimit `json:"limit"`
 }
 
 func (t *Policy) Build() (*policy.Policy, error) {
@@ -34,6 +59,7 @@ func (t *Policy) Build() (*policy.Policy, error) {
 		Stats: &policy.Policy_Stats{
 			UserUplink:   t.StatsUserUplink,
 			UserDownlink: t.StatsUserDownlink,
+			UserOnline:   t.StatsUserOnline,
 		},
 	}
 
@@ -47,6 +73,10 @@ func (t *Policy) Build() (*policy.Policy, error) {
 		}
 	}
 
+	if t.Limit != nil {
+		p.Limit = t.Limit.Build()
+	}
+
 	return p, nil
 }
 
@@ -69,8 +99,9 @@ func (p *SystemPolicy) Build() (*policy.SystemPolicy, error) {
 }
 
 type PolicyConfig struct {
-	Levels map[uint32]*Policy `json:"levels"`
-	System *SystemPolicy      `json:"system"`
+	Levels map[uint32]*Policy      `json:"levels"`
+	System *SystemPolicy           `json:"system"`
+	Users  map[string]*PolicyLimit `json:"users"`
 }
 
 func (c *PolicyConfig) Build() (*policy.Config, error) {
@@ -88,6 +119,16 @@ func (c *PolicyConfig) Build() (*policy.Config, error) {
 		Level: levels,
 	}
 
+	for email, l := range c.Users {
+		if l == nil {
+			return nil, newError("no limit specified for user ", email)
+		}
+		if config.User == nil {
+			config.User = make(map[string]*policy.Policy_Limit)
+		}
+		config.User[email] = l.Build()
+	}
+
 	if c.System != nil {
 		sc, err := c.System.Build()
 		if err != nil {
@@ -1,9 +1,14 @@
 package v4_test
 
 import (
+	"encoding/json"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+
+	"github.com/v2fly/v2ray-core/v5/app/policy"
 	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon/testassist"
 	v4 "github.com/v2fly/v2ray-core/v5/infra/conf/v4"
 )
 
@@ -38,3 +43,58 @@ func TestBufferSize(t *testing.T) {
 		}
 	}
 }
+
+func TestPolicyLimit(t *testing.T) {
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"levels": {
+					"0": {
+						"limit": {
+							"quota": 1073741824,
+							"quotaWindow": 2592000,
+							"uplinkRate": 1048576,
+							"downlinkRate": 2097152,
+							"maxConnections": 8,
+							"maxIPs": 2
+						},
+						"statsUserOnline": true
+					}
+				},
+				"users": {
+					"love@v2fly.org": {
+						"maxConnections": 2
+					}
+				}
+			}`,
+			Parser: func(s string) (proto.Message, error) {
+				config := new(v4.PolicyConfig)
+				if err := json.Unmarshal([]byte(s), config); err != nil {
+					return nil, err
+				}
+				return config.Build()
+			},
+			Output: &policy.Config{
+				Level: map[uint32]*policy.Policy{
+					0: {
+						Timeout: &policy.Policy_Timeout{},
+						Stats:   &policy.Policy_Stats{UserOnline: true},
+						Limit: &policy.Policy_Limit{
+							Quota:          1073741824,
+							QuotaWindow:    &policy.Second{Value: 2592000},
+							UplinkRate:     1048576,
+							DownlinkRate:   2097152,
+							MaxConnections: 8,
+							MaxIps:         2,
+						},
+					},
+				},
+				User: map[string]*policy.Policy_Limit{
+					"love@v2fly.org": {
+						MaxConnections: 2,
+					},
+				},
+			},
+		},
+	})
+}
@@ -1,33 +1,51 @@
 package v4
 
 import (
+	"strings"
+
 	"github.com/golang/protobuf/proto"
 
 	"github.com/v2fly/v2ray-core/v5/app/reverse"
 )
 
 type BridgeConfig struct {
-	Tag    string `json:"tag"`
-	Domain string `json:"domain"`
+	Tag      string `json:"tag"`
+	Domain   string `json:"domain"`
+	Identity string `json:"identity"`
 }
 
 func (c *BridgeConfig) Build() (*reverse.BridgeConfig, error) {
 	return &reverse.BridgeConfig{
-		Tag:    c.Tag,
-		Domain: c.Domain,
+		Tag:      c.Tag,
+		Domain:   c.Domain,
+		Identity: c.Identity,
 	}, nil
 }
 
 type PortalConfig struct {
-	Tag    string `json:"tag"`
-	Domain string `json:"domain"`
+	Tag            string `json:"tag"`
+	Domain         string `json:"domain"`
+	Strategy       string `json:"strategy"`
+	BridgeIdentity string `json:"bridgeIdentity"`
 }
 
 func (c *PortalConfig) Build() (*reverse.PortalConfig, error) {
-	return &reverse.PortalConfig{
-		Tag:    c.Tag,
-		Domain: c.Domain,
-	}, nil
+	config := &reverse.PortalConfig{
+		Tag:            c.Tag,
+		Domain:         c.Domain,
+		BridgeIdentity: c.BridgeIdentity,
+	}
+	switch strings.ToLower(c.Strategy) {
+	case "", "leastconnections":
+		config.Strategy = reverse.PortalConfig_LEAST_CONNECTIONS
+	case "roundrobin":
+		config.Strategy = reverse.PortalConfig_ROUND_ROBIN
+	case "sticky", "stickysource":
+		config.Strategy = reverse.PortalConfig_STICKY_SOURCE
+	default:
+		return nil, newError("unknown portal strategy: ", c.Strategy)
+	}
+	return config, nil
 }
 
 type ReverseConfig struct {
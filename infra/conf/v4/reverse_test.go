@@ -43,5 +43,34 @@ func TestReverseConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			Input: `{
+				"bridges": [{
+					"tag": "bridge",
+					"domain": "test.v2fly.org",
+					"identity": "office"
+				}],
+				"portals": [{
+					"tag": "portal",
+					"domain": "test.v2fly.org",
+					"strategy": "roundRobin",
+					"bridgeIdentity": "office"
+				}, {
+					"tag": "sticky",
+					"domain": "test.v2fly.org",
+					"strategy": "sticky"
+				}]
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &reverse.Config{
+				BridgeConfig: []*reverse.BridgeConfig{
+					{Tag: "bridge", Domain: "test.v2fly.org", Identity: "office"},
+				},
+				PortalConfig: []*reverse.PortalConfig{
+					{Tag: "portal", Domain: "test.v2fly.org", Strategy: reverse.PortalConfig_ROUND_ROBIN, BridgeIdentity: "office"},
+					{Tag: "sticky", Domain: "test.v2fly.org", Strategy: reverse.PortalConfig_STICKY_SOURCE},
+				},
+			},
+		},
 	})
 }
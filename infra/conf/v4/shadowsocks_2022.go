@@ -4,16 +4,27 @@ import (
 	"github.com/golang/protobuf/proto"
 
 	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
 	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon"
 	shadowsocks_2022 "github.com/v2fly/v2ray-core/v5/proxy/shadowsocks_2022"
 )
 
+type Shadowsocks2022UserConfig struct {
+	Key     string             `json:"key"`
+	Level   byte               `json:"level"`
+	Email   string             `json:"email"`
+	Address *cfgcommon.Address `json:"address"`
+	Port    uint16             `json:"port"`
+}
+
 type Shadowsocks2022ServerConfig struct {
-	Method  string                 `json:"method"`
-	Key     string                 `json:"key"`
-	Level   byte                   `json:"level"`
-	Email   string                 `json:"email"`
-	Network *cfgcommon.NetworkList `json:"network"`
+	Method  string                       `json:"method"`
+	Key     string                       `json:"key"`
+	Level   byte                         `json:"level"`
+	Email   string                       `json:"email"`
+	Network *cfgcommon.NetworkList       `json:"network"`
+	Users   []*Shadowsocks2022UserConfig `json:"users"`
 }
 
 func (v *Shadowsocks2022ServerConfig) Build() (proto.Message, error) {
@@ -21,20 +32,40 @@ func (v *Shadowsocks2022ServerConfig) Build() (proto.Message, error) {
 	if v.Network != nil {
 		network = v.Network.Build()
 	}
-	return &shadowsocks_2022.ServerConfig{
+	config := &shadowsocks_2022.ServerConfig{
 		Method:  v.Method,
 		Key:     v.Key,
 		Level:   int32(v.Level),
 		Email:   v.Email,
 		Network: network,
-	}, nil
+	}
+	for _, user := range v.Users {
+		if user.Key == "" {
+			return nil, newError("shadowsocks 2022: missing user key")
+		}
+		account := &shadowsocks_2022.Account{Key: user.Key}
+		if user.Address != nil {
+			if user.Port == 0 {
+				return nil, newError("shadowsocks 2022: invalid upstream port")
+			}
+			account.Address = user.Address.Build()
+			account.Port = uint32(user.Port)
+		}
+		config.Users = append(config.Users, &protocol.User{
+			Email:   user.Email,
+			Level:   uint32(user.Level),
+			Account: serial.ToTypedMessage(account),
+		})
+	}
+	return config, nil
 }
 
 type Shadowsocks2022ClientConfig struct {
-	Address *cfgcommon.Address `json:"address"`
-	Port    uint16             `json:"port"`
-	Method  string             `json:"method"`
-	Key     string             `json:"key"`
+	Address      *cfgcommon.Address `json:"address"`
+	Port         uint16             `json:"port"`
+	Method       string             `json:"method"`
+	Key          string             `json:"key"`
+	IdentityKeys []string           `json:"identityKeys"`
 }
 
 func (v *Shadowsocks2022ClientConfig) Build() (proto.Message, error) {
@@ -42,9 +73,10 @@ func (v *Shadowsocks2022ClientConfig) Build() (proto.Message, error) {
 		return nil, newError("shadowsocks 2022: missing server address")
 	}
 	return &shadowsocks_2022.ClientConfig{
-		Address: v.Address.Build(),
-		Port:    uint32(v.Port),
-		Method:  v.Method,
-		Key:     v.Key,
+		Address:      v.Address.Build(),
+		Port:         uint32(v.Port),
+		Method:       v.Method,
+		Key:          v.Key,
+		IdentityKeys: v.IdentityKeys,
 	}, nil
 }
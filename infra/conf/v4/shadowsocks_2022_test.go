@@ -0,0 +1,75 @@
+package v4_test
+
+import (
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon"
+	"github.com/v2fly/v2ray-core/v5/infra/conf/cfgcommon/testassist"
+	v4 "github.com/v2fly/v2ray-core/v5/infra/conf/v4"
+	shadowsocks_2022 "github.com/v2fly/v2ray-core/v5/proxy/shadowsocks_2022"
+)
+
+func TestShadowsocks2022ServerConfigParsing(t *testing.T) {
+	creator := func() cfgcommon.Buildable {
+		return new(v4.Shadowsocks2022ServerConfig)
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"method": "2022-blake3-aes-128-gcm",
+				"key": "AAAAAAAAAAAAAAAAAAAAAA==",
+				"users": [
+					{
+						"key": "AQEBAQEBAQEBAQEBAQEBAQ==",
+						"email": "love@v2fly.org",
+						"level": 1
+					}
+				]
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &shadowsocks_2022.ServerConfig{
+				Method: "2022-blake3-aes-128-gcm",
+				Key:    "AAAAAAAAAAAAAAAAAAAAAA==",
+				Users: []*protocol.User{
+					{
+						Email: "love@v2fly.org",
+						Level: 1,
+						Account: serial.ToTypedMessage(&shadowsocks_2022.Account{
+							Key: "AQEBAQEBAQEBAQEBAQEBAQ==",
+						}),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestShadowsocks2022ClientConfigParsing(t *testing.T) {
+	creator := func() cfgcommon.Buildable {
+		return new(v4.Shadowsocks2022ClientConfig)
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"address": "127.0.0.1",
+				"port": 8388,
+				"method": "2022-blake3-aes-128-gcm",
+				"key": "AQEBAQEBAQEBAQEBAQEBAQ==",
+				"identityKeys": ["AAAAAAAAAAAAAAAAAAAAAA=="]
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &shadowsocks_2022.ClientConfig{
+				Address:      net.NewIPOrDomain(net.LocalHostIP),
+				Port:         8388,
+				Method:       "2022-blake3-aes-128-gcm",
+				Key:          "AQEBAQEBAQEBAQEBAQEBAQ==",
+				IdentityKeys: []string{"AAAAAAAAAAAAAAAAAAAAAA=="},
+			},
+		},
+	})
+}
@@ -2,6 +2,7 @@ package v4
 
 import (
 	"encoding/json"
+	"sort"
 	"strings"
 
 	"github.com/golang/protobuf/proto"
@@ -137,13 +138,58 @@ func (c *TCPConfig) Build() (proto.Message, error) {
 	return config, nil
 }
 
+type WebSocketFallbackConfig struct {
+	StaticDir  string            `json:"staticDir"`
+	ProxyURL   string            `json:"proxyUrl"`
+	StatusCode uint32            `json:"statusCode"`
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+	AccessLog  string            `json:"accessLog"`
+}
+
+// Build implements Buildable.
+func (c *WebSocketFallbackConfig) Build() (*websocket.Fallback, error) {
+	if c.StatusCode != 0 && (c.StatusCode < 100 || c.StatusCode > 999) {
+		return nil, newError("invalid fallback status code: ", c.StatusCode)
+	}
+	config := &websocket.Fallback{
+		StaticDir:  c.StaticDir,
+		ProxyUrl:   c.ProxyURL,
+		StatusCode: c.StatusCode,
+		Body:       []byte(c.Body),
+		AccessLog:  c.AccessLog,
+	}
+	keys := make([]string, 0, len(c.Headers))
+	for key := range c.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		config.Header = append(config.Header, &websocket.Header{
+			Key:   key,
+			Value: c.Headers[key],
+		})
+	}
+	return config, nil
+}
+
+type WebSocketPathConfig struct {
+	Path  string `json:"path"`
+	Tag   string `json:"tag"`
+	Email string `json:"email"`
+	Level uint32 `json:"level"`
+}
+
 type WebSocketConfig struct {
-	Path                 string            `json:"path"`
-	Headers              map[string]string `json:"headers"`
-	AcceptProxyProtocol  bool              `json:"acceptProxyProtocol"`
-	MaxEarlyData         int32             `json:"maxEarlyData"`
-	UseBrowserForwarding bool              `json:"useBrowserForwarding"`
-	EarlyDataHeaderName  string            `json:"earlyDataHeaderName"`
+	Path                 string                   `json:"path"`
+	Headers              map[string]string        `json:"headers"`
+	AcceptProxyProtocol  bool                     `json:"acceptProxyProtocol"`
+	MaxEarlyData         int32                    `json:"maxEarlyData"`
+	UseBrowserForwarding bool                     `json:"useBrowserForwarding"`
+	EarlyDataHeaderName  string                   `json:"earlyDataHeaderName"`
+	Fallback             *WebSocketFallbackConfig `json:"fallback"`
+	Paths                []WebSocketPathConfig    `json:"paths"`
+	UseHTTP2             bool                     `json:"useHttp2"`
 }
 
 // Build implements Buildable.
@@ -162,10 +208,29 @@ func (c *WebSocketConfig) Build() (proto.Message, error) {
 		MaxEarlyData:         c.MaxEarlyData,
 		UseBrowserForwarding: c.UseBrowserForwarding,
 		EarlyDataHeaderName:  c.EarlyDataHeaderName,
+		UseHttp2:             c.UseHTTP2,
 	}
 	if c.AcceptProxyProtocol {
 		config.AcceptProxyProtocol = c.AcceptProxyProtocol
 	}
+	for _, p := range c.Paths {
+		if p.Path == "" {
+			return nil, newError("empty WebSocket path")
+		}
+		config.Paths = append(config.Paths, &websocket.PathEntry{
+			Path:  p.Path,
+			Tag:   p.Tag,
+			Email: p.Email,
+			Level: p.Level,
+		})
+	}
+	if c.Fallback != nil {
+		fallback, err := c.Fallback.Build()
+		if err != nil {
+			return nil, newError("failed to build WebSocket fallback").Base(err)
+		}
+		config.Fallback = fallback
+	}
 	return config, nil
 }
 
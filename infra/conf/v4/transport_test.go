@@ -90,7 +90,20 @@ func TestTransportConfig(t *testing.T) {
 					}
 				},
 				"wsSettings": {
-					"path": "/t"
+					"path": "/t",
+					"fallback": {
+						"statusCode": 403,
+						"headers": {
+							"Server": "nginx"
+						},
+						"body": "Forbidden",
+						"accessLog": "/var/log/v2ray/fallback.log"
+					},
+					"paths": [
+						{"path": "/alice", "tag": "alice", "email": "alice@v2fly.org", "level": 1},
+						{"path": "/api/*", "tag": "api"}
+					],
+					"useHttp2": true
 				},
 				"quicSettings": {
 					"key": "abcd",
@@ -155,6 +168,19 @@ func TestTransportConfig(t *testing.T) {
 						ProtocolName: "websocket",
 						Settings: serial.ToTypedMessage(&websocket.Config{
 							Path: "/t",
+							Fallback: &websocket.Fallback{
+								StatusCode: 403,
+								Header: []*websocket.Header{
+									{Key: "Server", Value: "nginx"},
+								},
+								Body:      []byte("Forbidden"),
+								AccessLog: "/var/log/v2ray/fallback.log",
+							},
+							Paths: []*websocket.PathEntry{
+								{Path: "/alice", Tag: "alice", Email: "alice@v2fly.org", Level: 1},
+								{Path: "/api/*", Tag: "api"},
+							},
+							UseHttp2: true,
 						}),
 					},
 					{
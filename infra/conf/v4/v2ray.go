@@ -33,6 +33,7 @@ var (
 		"vliteu":           func() interface{} { return new(VLiteUDPInboundConfig) },
 		"shadowsocks-2022": func() interface{} { return new(Shadowsocks2022ServerConfig) },
 		"mixed":            func() interface{} { return new(MixedServerConfig) },
+		"dns":              func() interface{} { return new(DNSInboundConfig) },
 	}, "protocol", "settings")
 
 	outboundConfigLoader = loader.NewJSONConfigLoader(loader.ConfigCreatorCache{
@@ -404,7 +405,11 @@ func (c *Config) Build() (*core.Config, error) {
 
 	var logConfMsg *anypb.Any
 	if c.LogConfig != nil {
-		logConfMsg = serial.ToTypedMessage(c.LogConfig.Build())
+		logConf, err := c.LogConfig.Build()
+		if err != nil {
+			return nil, err
+		}
+		logConfMsg = serial.ToTypedMessage(logConf)
 	} else {
 		logConfMsg = serial.ToTypedMessage(log.DefaultLogConfig())
 	}
@@ -68,7 +68,13 @@ func TestV2RayConfig(t *testing.T) {
 							"path": ""
 						},
 						"tlsSettings": {
-							"alpn": "h2"
+							"alpn": "h2",
+							"acme": {
+								"domains": ["v2fly.org", "www.v2fly.org"],
+								"email": "admin@v2fly.org",
+								"storageDir": "/var/lib/v2ray/acme",
+								"renewBefore": 1728000
+							}
 						},
 						"security": "tls"
 					},
@@ -269,6 +275,12 @@ func TestV2RayConfig(t *testing.T) {
 								SecuritySettings: []*anypb.Any{
 									serial.ToTypedMessage(&tls.Config{
 										NextProtocol: []string{"h2"},
+										Acme: &tls.ACME{
+											Domain:      []string{"v2fly.org", "www.v2fly.org"},
+											Email:       "admin@v2fly.org",
+											StorageDir:  "/var/lib/v2ray/acme",
+											RenewBefore: 1728000,
+										},
 									}),
 								},
 							},
@@ -368,6 +380,14 @@ func TestMuxConfig_Build(t *testing.T) {
 			Enabled:     false,
 			Concurrency: 4,
 		}},
+		{"rotation", `{"enabled": true, "idleTimeout": 60, "maxConnections": 4, "maxReuse": 32, "maxBytes": 1073741824}`, &proxyman.MultiplexingConfig{
+			Enabled:        true,
+			Concurrency:    8,
+			IdleTimeout:    60,
+			MaxConnections: 4,
+			MaxReuse:       32,
+			MaxBytes:       1073741824,
+		}},
 		{"forbidden", `{"enabled": false, "concurrency": -1}`, nil},
 	}
 	for _, tt := range tests {
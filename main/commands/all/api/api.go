@@ -13,6 +13,7 @@ var CmdAPI = &base.Command{
 	Commands: []*base.Command{
 		cmdLog,
 		cmdStats,
+		cmdOnline,
 		cmdBalancerInfo,
 		cmdBalancerOverride,
 	},
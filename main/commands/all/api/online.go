@@ -0,0 +1,69 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	statsService "github.com/v2fly/v2ray-core/v5/app/stats/command"
+	"github.com/v2fly/v2ray-core/v5/main/commands/base"
+)
+
+var cmdOnline = &base.Command{
+	CustomFlags: true,
+	UsageLine:   "{{.Exec}} api online [--server=127.0.0.1:8080] <email>",
+	Short:       "online IPs of a user",
+	Long: `
+Get the source IPs a user is currently connected from.
+
+> Make sure you have "StatsService" set in "config.api.services" 
+of server config, and "statsUserOnline" enabled in the policy of 
+the user level.
+
+Arguments:
+
+	-json
+		Use json output.
+
+	-s, -server <server:port>
+		The API server address. Default 127.0.0.1:8080
+
+	-t, -timeout <seconds>
+		Timeout seconds to call API. Default 3
+
+Example:
+
+	{{.Exec}} {{.LongName}} love@v2fly.org
+`,
+	Run: executeOnline,
+}
+
+func executeOnline(cmd *base.Command, args []string) {
+	setSharedFlags(cmd)
+	cmd.Flag.Parse(args)
+	if cmd.Flag.NArg() != 1 {
+		base.Fatalf("exactly one user email is required")
+	}
+
+	conn, ctx, close := dialAPIServer()
+	defer close()
+
+	client := statsService.NewStatsServiceClient(conn)
+	r := &statsService.GetUserOnlineIPsRequest{Email: cmd.Flag.Arg(0)}
+	resp, err := client.GetUserOnlineIPs(ctx, r)
+	if err != nil {
+		base.Fatalf("failed to get online IPs: %s", err)
+	}
+
+	if apiJSON {
+		showJSONResponse(resp)
+		return
+	}
+
+	sb := new(strings.Builder)
+	for _, ip := range resp.Ip {
+		sb.WriteString(fmt.Sprintf("%-40s %s\n", ip.Ip, time.Unix(ip.Since, 0).Format(time.RFC3339)))
+	}
+	os.Stdout.WriteString(sb.String())
+}
@@ -12,9 +12,11 @@ import (
 	"syscall"
 
 	core "github.com/v2fly/v2ray-core/v5"
+	applog "github.com/v2fly/v2ray-core/v5/app/log"
 	"github.com/v2fly/v2ray-core/v5/common/cmdarg"
 	"github.com/v2fly/v2ray-core/v5/common/platform"
 	"github.com/v2fly/v2ray-core/v5/main/commands/base"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
 )
 
 // CmdRun runs V2Ray with config
@@ -47,6 +49,11 @@ Arguments:
 	-format <format>
 		Format of config input. (default "auto")
 
+Send SIGHUP to the process to reload the config files. Only the parts of
+the config that changed are applied, and unaffected listeners keep running.
+Send SIGUSR1 to reopen the log files after they are moved by an external
+log rotation tool.
+
 Examples:
 
 	{{.Exec}} {{.LongName}} -c config.json
@@ -94,8 +101,55 @@ func executeRun(cmd *base.Command, args []string) {
 
 	{
 		osSignals := make(chan os.Signal, 1)
-		signal.Notify(osSignals, os.Interrupt, syscall.SIGTERM)
-		<-osSignals
+		signal.Notify(osSignals, append([]os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}, reopenLogSignals...)...)
+		for sig := range osSignals {
+			if sig == syscall.SIGHUP {
+				reloadV2Ray(server)
+				continue
+			}
+			if isReopenLogSignal(sig) {
+				reopenLogs(server)
+				continue
+			}
+			break
+		}
+	}
+}
+
+func reloadV2Ray(server *core.Instance) {
+	if err := tls.ReloadCertificates(); err != nil {
+		log.Println("Failed to reload certificates:", err)
+	}
+	log.Println("Reloading config:", configFiles)
+	changes, err := server.ReloadConfig()
+	for _, change := range changes {
+		log.Println(change)
+	}
+	if err != nil {
+		log.Println("Failed to reload config:", err)
+		return
+	}
+	if len(changes) == 0 {
+		log.Println("Config reloaded, nothing changed")
+	}
+}
+
+func isReopenLogSignal(sig os.Signal) bool {
+	for _, s := range reopenLogSignals {
+		if sig == s {
+			return true
+		}
+	}
+	return false
+}
+
+func reopenLogs(server *core.Instance) {
+	logger, ok := server.GetFeature((*applog.Instance)(nil)).(*applog.Instance)
+	if !ok {
+		return
+	}
+	if err := logger.Reopen(); err != nil {
+		log.Println("Failed to reopen log files:", err)
 	}
 }
 
@@ -191,7 +245,7 @@ func getConfigFilePath() cmdarg.Arg {
 	return nil
 }
 
-func startV2Ray() (core.Server, error) {
+func startV2Ray() (*core.Instance, error) {
 	config, err := core.LoadConfig(*configFormat, configFiles)
 	if err != nil {
 		if len(configFiles) == 0 {
@@ -206,6 +260,9 @@ func startV2Ray() (core.Server, error) {
 	if err != nil {
 		return nil, newError("failed to create server").Base(err)
 	}
+	server.SetConfigSource(func() (*core.Config, error) {
+		return core.LoadConfig(*configFormat, configFiles)
+	})
 
 	return server, nil
 }
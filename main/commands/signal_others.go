@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package commands
+
+import (
+	"os"
+	"syscall"
+)
+
+// reopenLogSignals are the signals that make V2Ray reopen its log files.
+var reopenLogSignals = []os.Signal{syscall.SIGUSR1}
@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package commands
+
+import "os"
+
+// reopenLogSignals are the signals that make V2Ray reopen its log files.
+var reopenLogSignals []os.Signal
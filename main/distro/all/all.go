@@ -17,6 +17,7 @@ import (
 	// Developer preview services
 	_ "github.com/v2fly/v2ray-core/v5/app/instman/command"
 	_ "github.com/v2fly/v2ray-core/v5/app/observatory/command"
+	_ "github.com/v2fly/v2ray-core/v5/app/reverse/command"
 
 	// Other optional features.
 	_ "github.com/v2fly/v2ray-core/v5/app/dns"
@@ -32,7 +33,9 @@ import (
 
 	// Developer preview features
 	_ "github.com/v2fly/v2ray-core/v5/app/instman"
+	_ "github.com/v2fly/v2ray-core/v5/app/metrics"
 	_ "github.com/v2fly/v2ray-core/v5/app/observatory"
+	_ "github.com/v2fly/v2ray-core/v5/app/persistentstorage/filestorage"
 	_ "github.com/v2fly/v2ray-core/v5/app/restfulapi"
 
 	// Inbound and outbound proxies.
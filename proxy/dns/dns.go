@@ -253,6 +253,19 @@ func (h *Handler) handleIPQuery(id uint16, qType dnsmessage.Type, domain string,
 		return
 	}
 
+	b, err := packAnswer(id, qType, domain, rcode, ttl, ips)
+	if err != nil {
+		newError("pack message").Base(err).WriteToLog()
+		return
+	}
+
+	if err := writer.WriteMessage(b); err != nil {
+		newError("write IP answer").Base(err).WriteToLog()
+	}
+}
+
+// packAnswer builds the response to the query of the given type, with ips as its answers.
+func packAnswer(id uint16, qType dnsmessage.Type, domain string, rcode uint16, ttl uint32, ips []net.IP) (*buf.Buffer, error) {
 	b := buf.New()
 	rawBytes := b.Extend(buf.Size)
 	builder := dnsmessage.NewBuilder(rawBytes[:0], dnsmessage.Header{
@@ -285,15 +298,11 @@ func (h *Handler) handleIPQuery(id uint16, qType dnsmessage.Type, domain string,
 	}
 	msgBytes, err := builder.Finish()
 	if err != nil {
-		newError("pack message").Base(err).WriteToLog()
 		b.Release()
-		return
+		return nil, err
 	}
 	b.Resize(0, int32(len(msgBytes)))
-
-	if err := writer.WriteMessage(b); err != nil {
-		newError("write IP answer").Base(err).WriteToLog()
-	}
+	return b, nil
 }
 
 type outboundConn struct {
@@ -0,0 +1,417 @@
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"golang.org/x/net/http2"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/errors"
+	"github.com/v2fly/v2ray-core/v5/common/log"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	dns_proto "github.com/v2fly/v2ray-core/v5/common/protocol/dns"
+	"github.com/v2fly/v2ray-core/v5/common/session"
+	"github.com/v2fly/v2ray-core/v5/common/signal"
+	"github.com/v2fly/v2ray-core/v5/common/task"
+	"github.com/v2fly/v2ray-core/v5/features/dns"
+	"github.com/v2fly/v2ray-core/v5/features/policy"
+	"github.com/v2fly/v2ray-core/v5/features/routing"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+)
+
+const (
+	dohContentType = "application/dns-message"
+	// maxMessageSize is the largest DNS message accepted in DNS over HTTPS requests.
+	maxMessageSize = 65535
+	// defaultTTL is the TTL of the address records in the answers if it is not configured.
+	defaultTTL = 600
+	// forwardTimeout limits the time the upstream server takes to answer a forwarded query.
+	forwardTimeout = 4 * time.Second
+)
+
+func init() {
+	common.Must(common.RegisterConfig((*ServerConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewServer(ctx, config.(*ServerConfig))
+	}))
+
+	common.Must(common.RegisterConfig((*SimplifiedServerConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		simplifiedServer := config.(*SimplifiedServerConfig)
+		fullConfig := &ServerConfig{
+			DohPath:  simplifiedServer.DohPath,
+			Upstream: simplifiedServer.Upstream,
+			Ttl:      simplifiedServer.Ttl,
+		}
+		return common.CreateObject(ctx, fullConfig)
+	}))
+}
+
+// Server is an inbound handler that answers DNS queries of clients with the DNS client of V2Ray,
+// so static hosts, FakeDNS, name server selection and query strategy all apply.
+// Other queries are forwarded to the upstream server through routing.
+// Queries are served over UDP and TCP, and over HTTP on TCP if DoHPath is set.
+type Server struct {
+	config        *ServerConfig
+	client        dns.Client
+	ipv4Lookup    dns.IPv4Lookup
+	ipv6Lookup    dns.IPv6Lookup
+	policyManager policy.Manager
+	ttl           uint32
+
+	// upstream is only valid if dispatcher is not nil.
+	upstream   net.Destination
+	dispatcher routing.Dispatcher
+
+	// lookupWithOption is nil if the client does not support it.
+	lookupWithOption dns.IPLookupWithOption
+}
+
+// NewServer creates a new DNS server inbound.
+func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
+	s := &Server{
+		config: config,
+		ttl:    config.Ttl,
+	}
+	if s.ttl == 0 {
+		s.ttl = defaultTTL
+	}
+	if err := core.RequireFeatures(ctx, func(dnsClient dns.Client, policyManager policy.Manager) error {
+		return s.init(dnsClient, policyManager)
+	}); err != nil {
+		return nil, err
+	}
+	if config.Upstream != nil {
+		if config.Upstream.Address == nil {
+			return nil, newError("upstream address is not specified")
+		}
+		s.upstream = config.Upstream.AsDestination()
+		if s.upstream.Network == net.Network_Unknown {
+			s.upstream.Network = net.Network_UDP
+		}
+		if s.upstream.Port == 0 {
+			s.upstream.Port = 53
+		}
+		if err := core.RequireFeatures(ctx, func(dispatcher routing.Dispatcher) error {
+			s.dispatcher = dispatcher
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Server) init(dnsClient dns.Client, policyManager policy.Manager) error {
+	s.client = dnsClient
+	s.policyManager = policyManager
+
+	if ipv4lookup, ok := dnsClient.(dns.IPv4Lookup); ok {
+		s.ipv4Lookup = ipv4lookup
+	} else {
+		return newError("dns.Client doesn't implement IPv4Lookup")
+	}
+
+	if ipv6lookup, ok := dnsClient.(dns.IPv6Lookup); ok {
+		s.ipv6Lookup = ipv6lookup
+	} else {
+		return newError("dns.Client doesn't implement IPv6Lookup")
+	}
+
+	// The FakeDNS option of the client is changed by routing, so it is given with each query instead.
+	if lookup, ok := dnsClient.(dns.IPLookupWithOption); ok {
+		s.lookupWithOption = lookup
+	}
+	return nil
+}
+
+// lookupIP resolves domain with FakeDNS, for only one of IPv4 and IPv6 as option specifies.
+func (s *Server) lookupIP(domain string, option dns.IPOption) ([]net.IP, error) {
+	if s.lookupWithOption != nil {
+		// Do NOT skip FakeDNS
+		option.FakeEnable = true
+		return s.lookupWithOption.LookupIPWithOption(domain, option)
+	}
+	if option.IPv4Enable {
+		return s.ipv4Lookup.LookupIPv4(domain)
+	}
+	return s.ipv6Lookup.LookupIPv6(domain)
+}
+
+// Network implements proxy.Inbound.
+func (*Server) Network() []net.Network {
+	return []net.Network{net.Network_TCP, net.Network_UDP}
+}
+
+// Process implements proxy.Inbound.
+func (s *Server) Process(ctx context.Context, network net.Network, conn internet.Connection, dispatcher routing.Dispatcher) error {
+	if network == net.Network_UDP {
+		return s.serveMessages(ctx, network, &dns_proto.UDPReader{
+			Reader: buf.NewPacketReader(conn),
+		}, &dns_proto.UDPWriter{
+			Writer: buf.NewWriter(conn),
+		})
+	}
+
+	reader := bufio.NewReader(conn)
+	if s.config.DohPath != "" {
+		prefix, err := reader.Peek(4)
+		if err != nil {
+			return newError("failed to read request").Base(err)
+		}
+		switch string(prefix) {
+		case "PRI ":
+			// HTTP/2 connection preface
+			server := &http2.Server{}
+			server.ServeConn(&bufferedConn{Connection: conn, reader: reader}, &http2.ServeConnOpts{
+				Context: ctx,
+				Handler: s,
+			})
+			return nil
+		case "GET ", "POST":
+			return s.serveHTTP1(ctx, reader, conn)
+		}
+	}
+	return s.serveMessages(ctx, network, dns_proto.NewTCPReader(buf.NewReader(reader)), &dns_proto.TCPWriter{
+		Writer: buf.NewWriter(conn),
+	})
+}
+
+func (s *Server) serveMessages(ctx context.Context, network net.Network, reader dns_proto.MessageReader, writer dns_proto.MessageWriter) error {
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, cancel, s.policyManager.ForLevel(s.config.UserLevel).Timeouts.ConnectionIdle)
+
+	var writeAccess sync.Mutex
+	serve := func() error {
+		for {
+			b, err := reader.ReadMessage()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			timer.Update()
+
+			go func() {
+				answer := s.answer(ctx, network, b.Bytes())
+				b.Release()
+				if answer == nil {
+					return
+				}
+				writeAccess.Lock()
+				defer writeAccess.Unlock()
+				if err := writer.WriteMessage(answer); err != nil {
+					newError("failed to write answer").Base(err).WriteToLog(session.ExportIDToError(ctx))
+				}
+				timer.Update()
+			}()
+		}
+	}
+
+	if err := task.Run(ctx, serve); err != nil {
+		return newError("connection ends").Base(err)
+	}
+	return nil
+}
+
+// answer resolves the query in msg. Only address queries are resolved, others are forwarded to the upstream
+// server, or answered with NOTIMP if there is none. It returns nil if msg is not a valid query.
+func (s *Server) answer(ctx context.Context, network net.Network, msg []byte) *buf.Buffer {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(msg)
+	if err != nil {
+		newError("failed to parse query").Base(err).WriteToLog(session.ExportIDToError(ctx))
+		return nil
+	}
+	q, err := parser.Question()
+	if err != nil {
+		newError("failed to parse question").Base(err).WriteToLog(session.ExportIDToError(ctx))
+		return nil
+	}
+	domain := q.Name.String()
+
+	var ips []net.IP
+	var rcode uint16
+	switch {
+	case q.Type == dnsmessage.TypeA || q.Type == dnsmessage.TypeAAAA:
+		ips, err = s.lookupIP(domain, dns.IPOption{IPv4Enable: q.Type == dnsmessage.TypeA, IPv6Enable: q.Type == dnsmessage.TypeAAAA})
+		rcode = dns.RCodeFromError(err)
+		if rcode == 0 && len(ips) == 0 && err != nil && err != dns.ErrEmptyResponse {
+			rcode = uint16(dnsmessage.RCodeServerFailure)
+		}
+	case s.dispatcher != nil:
+		var b *buf.Buffer
+		b, err = s.forward(ctx, msg)
+		if err == nil {
+			s.logQuery(ctx, network, strings.TrimSuffix(domain, "."), q.Type, nil)
+			return b
+		}
+		rcode = uint16(dnsmessage.RCodeServerFailure)
+	default:
+		err = newError("no upstream server for ", strings.TrimPrefix(q.Type.String(), "Type"), " queries")
+		rcode = uint16(dnsmessage.RCodeNotImplemented)
+	}
+	s.logQuery(ctx, network, strings.TrimSuffix(domain, "."), q.Type, err)
+
+	b, err := packAnswer(header.ID, q.Type, domain, rcode, s.ttl, ips)
+	if err != nil {
+		newError("failed to pack answer").Base(err).WriteToLog(session.ExportIDToError(ctx))
+		return nil
+	}
+	return b
+}
+
+// forward sends the query in msg to the upstream server through routing, and returns its answer.
+func (s *Server) forward(ctx context.Context, msg []byte) (*buf.Buffer, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	link, err := s.dispatcher.Dispatch(ctx, s.upstream)
+	if err != nil {
+		return nil, newError("failed to dispatch query to ", s.upstream).Base(err)
+	}
+	defer common.Close(link.Writer)
+	defer common.Interrupt(link.Reader)
+	timer := time.AfterFunc(forwardTimeout, func() {
+		common.Interrupt(link.Reader)
+	})
+	defer timer.Stop()
+
+	var reader dns_proto.MessageReader
+	var writer dns_proto.MessageWriter
+	if s.upstream.Network == net.Network_TCP {
+		reader = dns_proto.NewTCPReader(link.Reader)
+		writer = &dns_proto.TCPWriter{Writer: link.Writer}
+	} else {
+		reader = &dns_proto.UDPReader{Reader: link.Reader}
+		writer = &dns_proto.UDPWriter{Writer: link.Writer}
+	}
+
+	b := buf.New()
+	if _, err := b.Write(msg); err != nil {
+		b.Release()
+		return nil, newError("query too large").Base(err)
+	}
+	if err := writer.WriteMessage(b); err != nil {
+		return nil, newError("failed to forward query to ", s.upstream).Base(err)
+	}
+	answer, err := reader.ReadMessage()
+	if err != nil {
+		return nil, newError("failed to read answer from ", s.upstream).Base(err)
+	}
+	return answer, nil
+}
+
+func (s *Server) logQuery(ctx context.Context, network net.Network, domain string, qType dnsmessage.Type, err error) {
+	inbound := session.InboundFromContext(ctx)
+	if inbound == nil || !inbound.Source.IsValid() {
+		return
+	}
+	msg := &log.AccessMessage{
+		From:    inbound.Source,
+		To:      strings.TrimPrefix(qType.String(), "Type") + " " + domain,
+		Status:  log.AccessAccepted,
+		Reason:  err,
+		Network: network.SystemString(),
+		Domain:  domain,
+	}
+	if inbound.User != nil {
+		msg.Email = inbound.User.Email
+	}
+	msg.InboundTag = inbound.Tag
+	log.Record(msg)
+}
+
+func (s *Server) serveHTTP1(ctx context.Context, reader *bufio.Reader, conn internet.Connection) error {
+	for {
+		request, err := http.ReadRequest(reader)
+		if err != nil {
+			if errors.Cause(err) == io.EOF {
+				return nil
+			}
+			return newError("failed to read HTTP request").Base(err)
+		}
+		status, body := s.handleDoH(ctx, request)
+		response := &http.Response{
+			Status:        http.StatusText(status),
+			StatusCode:    status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{},
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Close:         request.Close,
+		}
+		if status == http.StatusOK {
+			response.Header.Set("Content-Type", dohContentType)
+		}
+		if err := response.Write(conn); err != nil {
+			return newError("failed to write HTTP response").Base(err)
+		}
+		if request.Close {
+			return nil
+		}
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status, body := s.handleDoH(r.Context(), r)
+	if status == http.StatusOK {
+		w.Header().Set("Content-Type", dohContentType)
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// handleDoH serves a DNS over HTTPS (RFC 8484) request. It returns the status code and the body of the response.
+func (s *Server) handleDoH(ctx context.Context, r *http.Request) (int, []byte) {
+	if r.URL.Path != s.config.DohPath {
+		return http.StatusNotFound, nil
+	}
+
+	var msg []byte
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		msg, err = base64.RawURLEncoding.DecodeString(r.URL.Query().Get("dns"))
+	case http.MethodPost:
+		if r.Header.Get("Content-Type") != dohContentType {
+			return http.StatusUnsupportedMediaType, nil
+		}
+		msg, err = io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	default:
+		return http.StatusMethodNotAllowed, nil
+	}
+	if err != nil || len(msg) == 0 {
+		return http.StatusBadRequest, nil
+	}
+
+	answer := s.answer(ctx, net.Network_TCP, msg)
+	if answer == nil {
+		return http.StatusBadRequest, nil
+	}
+	defer answer.Release()
+	return http.StatusOK, append([]byte(nil), answer.Bytes()...)
+}
+
+// bufferedConn is a connection whose reads go through a buffered reader.
+type bufferedConn struct {
+	internet.Connection
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
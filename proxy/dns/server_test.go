@@ -0,0 +1,203 @@
+package dns_test
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/miekg/dns"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/app/dispatcher"
+	dnsapp "github.com/v2fly/v2ray-core/v5/app/dns"
+	"github.com/v2fly/v2ray-core/v5/app/policy"
+	"github.com/v2fly/v2ray-core/v5/app/proxyman"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	dns_proxy "github.com/v2fly/v2ray-core/v5/proxy/dns"
+	"github.com/v2fly/v2ray-core/v5/proxy/freedom"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/tcp"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/udp"
+)
+
+func TestDNSServerInbound(t *testing.T) {
+	port := udp.PickPort()
+
+	dnsServer := dns.Server{
+		Addr:    "127.0.0.1:" + port.String(),
+		Net:     "udp",
+		Handler: &staticHandler{},
+		UDPSize: 1200,
+	}
+	defer dnsServer.Shutdown()
+
+	go dnsServer.ListenAndServe()
+	time.Sleep(time.Second)
+
+	serverPort := tcp.PickPort()
+	noUpstreamPort := tcp.PickPort()
+	config := &core.Config{
+		App: []*anypb.Any{
+			serial.ToTypedMessage(&dnsapp.Config{
+				NameServers: []*net.Endpoint{
+					{
+						Network: net.Network_UDP,
+						Address: &net.IPOrDomain{
+							Address: &net.IPOrDomain_Ip{
+								Ip: []byte{127, 0, 0, 1},
+							},
+						},
+						Port: uint32(port),
+					},
+				},
+				StaticHosts: []*dnsapp.HostMapping{
+					{
+						Type:   dnsapp.DomainMatchingType_Full,
+						Domain: "office.lan",
+						Ip:     [][]byte{{10, 0, 0, 1}},
+					},
+				},
+			}),
+			serial.ToTypedMessage(&dispatcher.Config{}),
+			serial.ToTypedMessage(&proxyman.OutboundConfig{}),
+			serial.ToTypedMessage(&proxyman.InboundConfig{}),
+			serial.ToTypedMessage(&policy.Config{}),
+		},
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				ProxySettings: serial.ToTypedMessage(&dns_proxy.ServerConfig{
+					DohPath: "/dns-query",
+					Upstream: &net.Endpoint{
+						Address: net.NewIPOrDomain(net.LocalHostIP),
+						Port:    uint32(port),
+					},
+					Ttl: 60,
+				}),
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortRange: net.SinglePortRange(serverPort),
+					Listen:    net.NewIPOrDomain(net.LocalHostIP),
+				}),
+			},
+			{
+				ProxySettings: serial.ToTypedMessage(&dns_proxy.ServerConfig{}),
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortRange: net.SinglePortRange(noUpstreamPort),
+					Listen:    net.NewIPOrDomain(net.LocalHostIP),
+				}),
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				ProxySettings: serial.ToTypedMessage(&freedom.Config{}),
+			},
+		},
+	}
+
+	v, err := core.New(config)
+	common.Must(err)
+	common.Must(v.Start())
+	defer v.Close()
+
+	serverAddr := "127.0.0.1:" + strconv.Itoa(int(serverPort))
+	query := func(name string, qType uint16) *dns.Msg {
+		m := new(dns.Msg)
+		m.Id = dns.Id()
+		m.RecursionDesired = true
+		m.Question = []dns.Question{{Name: name, Qtype: qType, Qclass: dns.ClassINET}}
+		return m
+	}
+	expectA := func(in *dns.Msg, ip net.IP) {
+		t.Helper()
+		if len(in.Answer) != 1 {
+			t.Fatal("len(answer): ", len(in.Answer))
+		}
+		rr, ok := in.Answer[0].(*dns.A)
+		if !ok {
+			t.Fatal("not A record")
+		}
+		if r := cmp.Diff(rr.A[:], ip); r != "" {
+			t.Error(r)
+		}
+		if rr.Hdr.Ttl != 60 {
+			t.Error("TTL: ", rr.Hdr.Ttl)
+		}
+	}
+
+	{
+		c := new(dns.Client)
+		in, _, err := c.Exchange(query("google.com.", dns.TypeA), serverAddr)
+		common.Must(err)
+		expectA(in, net.IP{8, 8, 8, 8})
+	}
+
+	{
+		c := &dns.Client{Net: "tcp"}
+		in, _, err := c.Exchange(query("office.lan.", dns.TypeA), serverAddr)
+		common.Must(err)
+		expectA(in, net.IP{10, 0, 0, 1})
+	}
+
+	{
+		c := new(dns.Client)
+		in, _, err := c.Exchange(query("notexist.google.com.", dns.TypeAAAA), serverAddr)
+		common.Must(err)
+		if in.Rcode != dns.RcodeNameError {
+			t.Error("expected NameError, but got ", in.Rcode)
+		}
+	}
+
+	{
+		c := new(dns.Client)
+		in, _, err := c.Exchange(query("google.com.", dns.TypeMX), serverAddr)
+		common.Must(err)
+		if len(in.Answer) != 1 {
+			t.Fatal("len(answer): ", len(in.Answer))
+		}
+		if rr, ok := in.Answer[0].(*dns.MX); !ok || rr.Mx != "smtp.google.com." {
+			t.Error("expected forwarded MX record, but got ", in.Answer[0])
+		}
+	}
+
+	{
+		c := new(dns.Client)
+		in, _, err := c.Exchange(query("google.com.", dns.TypeMX), "127.0.0.1:"+strconv.Itoa(int(noUpstreamPort)))
+		common.Must(err)
+		if in.Rcode != dns.RcodeNotImplemented || len(in.Answer) != 0 {
+			t.Error("expected NOTIMP, but got ", in)
+		}
+	}
+
+	{
+		msg, err := query("facebook.com.", dns.TypeA).Pack()
+		common.Must(err)
+		resp, err := http.Get("http://" + serverAddr + "/dns-query?dns=" + base64.RawURLEncoding.EncodeToString(msg))
+		common.Must(err)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatal("status: ", resp.Status)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "application/dns-message" {
+			t.Error("content type: ", ct)
+		}
+		b, err := io.ReadAll(resp.Body)
+		common.Must(err)
+		in := new(dns.Msg)
+		common.Must(in.Unpack(b))
+		expectA(in, net.IP{9, 9, 9, 9})
+	}
+
+	{
+		resp, err := http.Get("http://" + serverAddr + "/other")
+		common.Must(err)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Error("status: ", resp.Status)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package shadowsocks2022
+
+import (
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+)
+
+// MemoryAccount is an account type converted from Account.
+type MemoryAccount struct {
+	Key string
+	// Upstream is the server connections are relayed to. It is only valid
+	// in relay mode.
+	Upstream net.Destination
+}
+
+// AsAccount implements protocol.AsAccount.
+func (a *Account) AsAccount() (protocol.Account, error) {
+	account := &MemoryAccount{
+		Key: a.GetKey(),
+	}
+	if a.Address != nil {
+		if a.Port == 0 {
+			return nil, newError("invalid upstream port")
+		}
+		account.Upstream = net.TCPDestination(a.Address.AsAddress(), net.Port(a.Port))
+	}
+	return account, nil
+}
+
+// Equals implements protocol.Account.Equals().
+func (a *MemoryAccount) Equals(another protocol.Account) bool {
+	if account, ok := another.(*MemoryAccount); ok {
+		return a.Key == account.Key && a.Upstream == account.Upstream
+	}
+	return false
+}
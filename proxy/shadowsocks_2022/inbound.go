@@ -2,10 +2,13 @@ package shadowsocks2022
 
 import (
 	"context"
+	"strings"
+	"sync"
 
 	shadowsocks "github.com/sagernet/sing-shadowsocks"
 	"github.com/sagernet/sing-shadowsocks/shadowaead_2022"
 	C "github.com/sagernet/sing/common"
+	"github.com/sagernet/sing/common/auth"
 	B "github.com/sagernet/sing/common/buf"
 	"github.com/sagernet/sing/common/bufio"
 	E "github.com/sagernet/sing/common/exceptions"
@@ -33,6 +36,12 @@ type Inbound struct {
 	service  shadowsocks.Service
 	email    string
 	level    int
+
+	// multiService is set in multi-user mode, relayService in relay mode.
+	multiService *shadowaead_2022.MultiService[*protocol.MemoryUser]
+	relayService *shadowaead_2022.RelayService[*protocol.MemoryUser]
+	usersAccess  sync.Mutex
+	users        []*protocol.MemoryUser
 }
 
 func NewServer(ctx context.Context, config *ServerConfig) (*Inbound, error) {
@@ -51,6 +60,35 @@ func NewServer(ctx context.Context, config *ServerConfig) (*Inbound, error) {
 	if !C.Contains(shadowaead_2022.List, config.Method) {
 		return nil, newError("unsupported method ", config.Method)
 	}
+	if len(config.Users) > 0 {
+		users := make([]*protocol.MemoryUser, 0, len(config.Users))
+		for _, user := range config.Users {
+			u, err := user.ToMemoryUser()
+			if err != nil {
+				return nil, newError("failed to get shadowsocks 2022 user").Base(err).AtError()
+			}
+			users = append(users, u)
+		}
+		if account, ok := users[0].Account.(*MemoryAccount); ok && account.Upstream.IsValid() {
+			service, err := shadowaead_2022.NewRelayServiceWithPassword[*protocol.MemoryUser](config.Method, config.Key, 500, inbound)
+			if err != nil {
+				return nil, newError("create relay service").Base(err)
+			}
+			inbound.service = service
+			inbound.relayService = service
+		} else {
+			service, err := shadowaead_2022.NewMultiServiceWithPassword[*protocol.MemoryUser](config.Method, config.Key, 500, inbound)
+			if err != nil {
+				return nil, newError("create multi-user service").Base(err)
+			}
+			inbound.service = service
+			inbound.multiService = service
+		}
+		if err := inbound.updateUsers(users); err != nil {
+			return nil, err
+		}
+		return inbound, nil
+	}
 	service, err := shadowaead_2022.NewServiceWithPassword(config.Method, config.Key, 500, inbound)
 	if err != nil {
 		return nil, newError("create service").Base(err)
@@ -59,6 +97,94 @@ func NewServer(ctx context.Context, config *ServerConfig) (*Inbound, error) {
 	return inbound, nil
 }
 
+// updateUsers replaces the users accepted in multi-user or relay mode.
+// usersAccess must be held by the caller once the inbound is running.
+func (i *Inbound) updateUsers(users []*protocol.MemoryUser) error {
+	relay := i.relayService != nil
+	keys := make([]string, 0, len(users))
+	var upstreams []M.Socksaddr
+	for _, user := range users {
+		account, ok := user.Account.(*MemoryAccount)
+		if !ok {
+			return newError("user ", user.Email, " has no shadowsocks 2022 account")
+		}
+		if account.Upstream.IsValid() != relay {
+			if relay {
+				return newError("user ", user.Email, " has no upstream server in relay mode")
+			}
+			return newError("user ", user.Email, " has an upstream server, but the inbound is not in relay mode")
+		}
+		keys = append(keys, account.Key)
+		if relay {
+			upstreams = append(upstreams, toSocksaddr(account.Upstream))
+		}
+	}
+	var err error
+	if relay {
+		err = i.relayService.UpdateUsersWithPasswords(users, keys, upstreams)
+	} else {
+		err = i.multiService.UpdateUsersWithPasswords(users, keys)
+	}
+	if err != nil {
+		return newError("failed to update users").Base(err)
+	}
+	i.users = users
+	return nil
+}
+
+// AddUser implements proxy.UserManager.AddUser().
+func (i *Inbound) AddUser(ctx context.Context, u *protocol.MemoryUser) error {
+	if i.multiService == nil && i.relayService == nil {
+		return newError("multi-user mode is not enabled")
+	}
+	if u.Email == "" {
+		return newError("Email must not be empty.")
+	}
+	i.usersAccess.Lock()
+	defer i.usersAccess.Unlock()
+
+	for _, user := range i.users {
+		if strings.EqualFold(user.Email, u.Email) {
+			return newError("User ", u.Email, " already exists.")
+		}
+	}
+	users := make([]*protocol.MemoryUser, 0, len(i.users)+1)
+	users = append(users, i.users...)
+	return i.updateUsers(append(users, u))
+}
+
+// RemoveUser implements proxy.UserManager.RemoveUser().
+func (i *Inbound) RemoveUser(ctx context.Context, email string) error {
+	if i.multiService == nil && i.relayService == nil {
+		return newError("multi-user mode is not enabled")
+	}
+	if email == "" {
+		return newError("Email must not be empty.")
+	}
+	i.usersAccess.Lock()
+	defer i.usersAccess.Unlock()
+
+	for idx, user := range i.users {
+		if strings.EqualFold(user.Email, email) {
+			users := make([]*protocol.MemoryUser, 0, len(i.users)-1)
+			users = append(users, i.users[:idx]...)
+			return i.updateUsers(append(users, i.users[idx+1:]...))
+		}
+	}
+	return newError("User ", email, " not found.")
+}
+
+// user returns the user a connection is authenticated as.
+func (i *Inbound) user(ctx context.Context) *protocol.MemoryUser {
+	if user, loaded := auth.UserFromContext[*protocol.MemoryUser](ctx); loaded {
+		return user
+	}
+	return &protocol.MemoryUser{
+		Email: i.email,
+		Level: uint32(i.level),
+	}
+}
+
 func (i *Inbound) Network() []net.Network {
 	return i.networks
 }
@@ -100,15 +226,12 @@ func (i *Inbound) Process(ctx context.Context, network net.Network, connection i
 
 func (i *Inbound) NewConnection(ctx context.Context, conn net.Conn, metadata M.Metadata) error {
 	inbound := session.InboundFromContext(ctx)
-	inbound.User = &protocol.MemoryUser{
-		Email: i.email,
-		Level: uint32(i.level),
-	}
+	inbound.User = i.user(ctx)
 	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
 		From:   metadata.Source,
 		To:     metadata.Destination,
 		Status: log.AccessAccepted,
-		Email:  i.email,
+		Email:  inbound.User.Email,
 	})
 	newError("tunnelling request to tcp:", metadata.Destination).WriteToLog(session.ExportIDToError(ctx))
 	dispatcher := session.DispatcherFromContext(ctx)
@@ -126,15 +249,12 @@ func (i *Inbound) NewConnection(ctx context.Context, conn net.Conn, metadata M.M
 
 func (i *Inbound) NewPacketConnection(ctx context.Context, conn N.PacketConn, metadata M.Metadata) error {
 	inbound := session.InboundFromContext(ctx)
-	inbound.User = &protocol.MemoryUser{
-		Email: i.email,
-		Level: uint32(i.level),
-	}
+	inbound.User = i.user(ctx)
 	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
 		From:   metadata.Source,
 		To:     metadata.Destination,
 		Status: log.AccessAccepted,
-		Email:  i.email,
+		Email:  inbound.User.Email,
 	})
 	newError("tunnelling request to udp:", metadata.Destination).WriteToLog(session.ExportIDToError(ctx))
 	dispatcher := session.DispatcherFromContext(ctx)
@@ -0,0 +1,94 @@
+package shadowsocks2022_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	. "github.com/v2fly/v2ray-core/v5/proxy/shadowsocks_2022"
+)
+
+func TestInboundUserManagement(t *testing.T) {
+	inbound, err := NewServer(context.Background(), &ServerConfig{
+		Method: "2022-blake3-aes-128-gcm",
+		Key:    "AAAAAAAAAAAAAAAAAAAAAA==",
+		Users: []*protocol.User{
+			{
+				Email:   "a@v2fly.org",
+				Account: serial.ToTypedMessage(&Account{Key: "AQEBAQEBAQEBAQEBAQEBAQ=="}),
+			},
+		},
+	})
+	common.Must(err)
+
+	user, err := (&protocol.User{
+		Email:   "b@v2fly.org",
+		Account: serial.ToTypedMessage(&Account{Key: "AgICAgICAgICAgICAgICAg=="}),
+	}).ToMemoryUser()
+	common.Must(err)
+
+	common.Must(inbound.AddUser(context.Background(), user))
+	if err := inbound.AddUser(context.Background(), user); err == nil {
+		t.Error("expected error when adding a duplicate user")
+	}
+	common.Must(inbound.RemoveUser(context.Background(), "a@v2fly.org"))
+	if err := inbound.RemoveUser(context.Background(), "a@v2fly.org"); err == nil {
+		t.Error("expected error when removing an absent user")
+	}
+
+	invalid := &protocol.MemoryUser{
+		Email:   "c@v2fly.org",
+		Account: &MemoryAccount{Key: "not base64"},
+	}
+	if err := inbound.AddUser(context.Background(), invalid); err == nil || !strings.Contains(err.Error(), "illegal base64 data") {
+		t.Error("expected key decoding error when adding a user with an invalid key, but got ", err)
+	}
+	if err := inbound.RemoveUser(context.Background(), "c@v2fly.org"); err == nil {
+		t.Error("user with an invalid key is added")
+	}
+}
+
+func TestInboundSingleUserRejectsUserManagement(t *testing.T) {
+	inbound, err := NewServer(context.Background(), &ServerConfig{
+		Method: "2022-blake3-aes-128-gcm",
+		Key:    "AAAAAAAAAAAAAAAAAAAAAA==",
+	})
+	common.Must(err)
+	if err := inbound.RemoveUser(context.Background(), "a@v2fly.org"); err == nil {
+		t.Error("expected error in single-user mode")
+	}
+}
+
+func TestInboundRelayUserManagement(t *testing.T) {
+	inbound, err := NewServer(context.Background(), &ServerConfig{
+		Method: "2022-blake3-aes-128-gcm",
+		Key:    "AAAAAAAAAAAAAAAAAAAAAA==",
+		Users: []*protocol.User{
+			{
+				Email: "a@v2fly.org",
+				Account: serial.ToTypedMessage(&Account{
+					Key:     "AQEBAQEBAQEBAQEBAQEBAQ==",
+					Address: net.NewIPOrDomain(net.LocalHostIP),
+					Port:    8388,
+				}),
+			},
+		},
+	})
+	common.Must(err)
+
+	user, err := (&protocol.User{
+		Email:   "b@v2fly.org",
+		Account: serial.ToTypedMessage(&Account{Key: "AgICAgICAgICAgICAgICAg=="}),
+	}).ToMemoryUser()
+	common.Must(err)
+	if err := inbound.AddUser(context.Background(), user); err == nil {
+		t.Error("expected error when adding a user without upstream in relay mode")
+	}
+
+	user.Account.(*MemoryAccount).Upstream = net.TCPDestination(net.DomainAddress("example.com"), 8388)
+	common.Must(inbound.AddUser(context.Background(), user))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"runtime"
+	"strings"
 	"time"
 
 	shadowsocks "github.com/sagernet/sing-shadowsocks"
@@ -46,7 +47,10 @@ func NewClient(ctx context.Context, config *ClientConfig) (*Outbound, error) {
 		if config.Key == "" {
 			return nil, newError("missing psk")
 		}
-		method, err := shadowaead_2022.NewWithPassword(config.Method, config.Key)
+		// The relay chain is encoded as colon separated keys, ending with
+		// the user PSK.
+		keys := append(append([]string(nil), config.IdentityKeys...), config.Key)
+		method, err := shadowaead_2022.NewWithPassword(config.Method, strings.Join(keys, ":"))
 		if err != nil {
 			return nil, newError("create method").Base(err)
 		}
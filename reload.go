@@ -0,0 +1,268 @@
+package core
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/features"
+	"github.com/v2fly/v2ray-core/v5/features/inbound"
+)
+
+// ConfigSource loads the config an Instance is reloaded with.
+type ConfigSource func() (*Config, error)
+
+// SetConfigSource sets the source ReloadConfig loads the config from.
+func (s *Instance) SetConfigSource(source ConfigSource) {
+	s.access.Lock()
+	defer s.access.Unlock()
+	s.configSource = source
+}
+
+// ReloadConfig loads the config from the config source of the instance, and
+// applies it with Reload.
+func (s *Instance) ReloadConfig() ([]string, error) {
+	s.access.Lock()
+	source := s.configSource
+	s.access.Unlock()
+	if source == nil {
+		return nil, newError("config source is not set")
+	}
+	config, err := source()
+	if err != nil {
+		return nil, newError("failed to load config").Base(err)
+	}
+	return s.Reload(config)
+}
+
+// Reload applies config to the running instance, and returns a description
+// of the changes made.
+//
+// Inbound and outbound handlers are compared with the running ones by tag,
+// and only those that were added, removed or changed are touched. App
+// settings that changed are applied to their features if they implement
+// features.ConfigReloader. Changes that cannot be applied at runtime are
+// logged and skipped; they take effect on the next restart.
+//
+// If a change fails, Reload stops there and returns the error. The changes
+// made before it are kept, and RunningConfig reflects them.
+func (s *Instance) Reload(config *Config) ([]string, error) {
+	s.reloadAccess.Lock()
+	defer s.reloadAccess.Unlock()
+
+	running := s.runningConfig.snapshot()
+	if len(config.Outbound) > 0 && len(running.Outbound) > 0 && config.Outbound[0].Tag != running.Outbound[0].Tag {
+		return nil, newError("changing the default outbound requires a restart")
+	}
+	if !proto.Equal(config.Transport, running.Transport) {
+		newError("global transport settings changed, restart to apply").AtWarning().WriteToLog()
+	}
+
+	reloads, err := s.diffApps(running.App, config.App)
+	if err != nil {
+		return nil, err
+	}
+	outbounds := diffHandlers(running.Outbound, config.Outbound)
+	if outbounds.untaggedRemoved > 0 {
+		newError(outbounds.untaggedRemoved, " untagged outbound(s) cannot be removed at runtime, restart to apply").AtWarning().WriteToLog()
+	}
+	inbounds := diffHandlers(running.Inbound, config.Inbound)
+	if inbounds.untaggedRemoved > 0 {
+		newError(inbounds.untaggedRemoved, " untagged inbound(s) cannot be removed at runtime, restart to apply").AtWarning().WriteToLog()
+	}
+
+	// Handler changes are recorded as they are made. The apps and the other
+	// settings are recorded here, whether or not all changes are applied.
+	defer s.runningConfig.setBase(running)
+
+	var changes []string
+	for _, reload := range reloads {
+		if err := reload.reloader.ReloadConfig(reload.settings); err != nil {
+			return changes, newError("failed to reload app ", reload.name).Base(err)
+		}
+		running.App[reload.index] = reload.app
+		changes = append(changes, "app "+reload.name+" reloaded")
+	}
+
+	for _, tag := range outbounds.removed {
+		if err := RemoveOutboundHandler(s, tag); err != nil {
+			return changes, newError("failed to remove outbound ", tag).Base(err)
+		}
+		changes = append(changes, "outbound "+tag+" removed")
+	}
+	for _, c := range outbounds.updated {
+		if err := AddOutboundHandler(s, c); err != nil {
+			return changes, newError("failed to update outbound ", c.Tag).Base(err)
+		}
+		changes = append(changes, "outbound "+c.Tag+" updated")
+	}
+	for _, c := range outbounds.added {
+		if err := AddOutboundHandler(s, c); err != nil {
+			return changes, newError("failed to add outbound ", c.Tag).Base(err)
+		}
+		changes = append(changes, "outbound "+c.Tag+" added")
+	}
+
+	for _, tag := range inbounds.removed {
+		if err := RemoveInboundHandler(s, tag); err != nil {
+			return changes, newError("failed to remove inbound ", tag).Base(err)
+		}
+		changes = append(changes, "inbound "+tag+" removed")
+	}
+	for _, c := range inbounds.updated {
+		if err := s.replaceInboundHandler(running, c); err != nil {
+			return changes, newError("failed to update inbound ", c.Tag).Base(err)
+		}
+		changes = append(changes, "inbound "+c.Tag+" updated")
+	}
+	for _, c := range inbounds.added {
+		if err := AddInboundHandler(s, c); err != nil {
+			return changes, newError("failed to add inbound ", c.Tag).Base(err)
+		}
+		changes = append(changes, "inbound "+c.Tag+" added")
+	}
+
+	return changes, nil
+}
+
+// replaceInboundHandler recreates the inbound handler with the tag of config,
+// as listeners cannot be changed in place. The new handler is created before
+// the old one is removed, and the old one is restored if the new one fails to
+// start.
+func (s *Instance) replaceInboundHandler(running *Config, config *InboundHandlerConfig) error {
+	handler, err := createInboundHandler(s, config)
+	if err != nil {
+		return err
+	}
+	if err := RemoveInboundHandler(s, config.Tag); err != nil {
+		handler.Close()
+		return err
+	}
+	err = addInboundHandler(s, config, handler)
+	if err == nil {
+		return nil
+	}
+	if err := s.GetFeature(inbound.ManagerType()).(inbound.Manager).RemoveHandler(s.ctx, config.Tag); err != nil {
+		newError("failed to remove inbound ", config.Tag).Base(err).AtWarning().WriteToLog()
+	}
+	for _, old := range running.Inbound {
+		if old.Tag == config.Tag {
+			if err := AddInboundHandler(s, old); err != nil {
+				newError("failed to restore inbound ", config.Tag).Base(err).AtError().WriteToLog()
+			}
+			break
+		}
+	}
+	return err
+}
+
+type appReload struct {
+	name     string
+	reloader features.ConfigReloader
+	settings interface{}
+	// index is the index of the app in the running config, and app its new settings.
+	index int
+	app   *anypb.Any
+}
+
+// diffApps returns the reloads to perform to apply the app settings.
+func (s *Instance) diffApps(running, apps []*anypb.Any) ([]appReload, error) {
+	var reloads []appReload
+	updated := make(map[string]*anypb.Any, len(apps))
+	for _, app := range apps {
+		updated[app.TypeUrl] = app
+	}
+	for i, old := range running {
+		app, found := updated[old.TypeUrl]
+		delete(updated, old.TypeUrl)
+		switch {
+		case !found:
+			newError("app ", appName(old), " removed, restart to apply").AtWarning().WriteToLog()
+			continue
+		case proto.Equal(old, app):
+			continue
+		}
+		reloader, ok := s.apps[old.TypeUrl].(features.ConfigReloader)
+		if !ok {
+			newError("app ", appName(old), " changed but cannot be reloaded, restart to apply").AtWarning().WriteToLog()
+			continue
+		}
+		settings, err := serial.GetInstanceOf(app)
+		if err != nil {
+			return nil, err
+		}
+		reloads = append(reloads, appReload{name: appName(app), reloader: reloader, settings: settings, index: i, app: app})
+	}
+	for _, app := range apps {
+		if _, found := updated[app.TypeUrl]; found {
+			newError("app ", appName(app), " added, restart to apply").AtWarning().WriteToLog()
+		}
+	}
+	return reloads, nil
+}
+
+func appName(app *anypb.Any) string {
+	return strings.TrimPrefix(app.TypeUrl, serial.V2RayTypeURLHeader)
+}
+
+type handlerConfig interface {
+	proto.Message
+	GetTag() string
+}
+
+type handlerDiff[T handlerConfig] struct {
+	added           []T
+	updated         []T
+	removed         []string
+	untaggedRemoved int
+}
+
+// diffHandlers compares handler configs by tag. Untagged handlers cannot be
+// addressed, so they are only matched by content.
+func diffHandlers[T handlerConfig](running, configs []T) handlerDiff[T] {
+	var diff handlerDiff[T]
+	tagged := make(map[string]T)
+	var untagged []T
+	for _, c := range running {
+		if c.GetTag() != "" {
+			tagged[c.GetTag()] = c
+		} else {
+			untagged = append(untagged, c)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, c := range configs {
+		tag := c.GetTag()
+		if tag == "" {
+			matched := false
+			for i, old := range untagged {
+				if proto.Equal(old, c) {
+					untagged = append(untagged[:i:i], untagged[i+1:]...)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				diff.added = append(diff.added, c)
+			}
+			continue
+		}
+		seen[tag] = true
+		old, found := tagged[tag]
+		switch {
+		case !found:
+			diff.added = append(diff.added, c)
+		case !proto.Equal(old, c):
+			diff.updated = append(diff.updated, c)
+		}
+	}
+	for _, c := range running {
+		if tag := c.GetTag(); tag != "" && !seen[tag] {
+			diff.removed = append(diff.removed, tag)
+		}
+	}
+	diff.untaggedRemoved = len(untagged)
+	return diff
+}
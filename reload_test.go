@@ -0,0 +1,125 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	. "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/app/dispatcher"
+	"github.com/v2fly/v2ray-core/v5/app/policy"
+	"github.com/v2fly/v2ray-core/v5/app/proxyman"
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"github.com/v2fly/v2ray-core/v5/features/inbound"
+	"github.com/v2fly/v2ray-core/v5/proxy/dokodemo"
+	"github.com/v2fly/v2ray-core/v5/proxy/freedom"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/tcp"
+)
+
+func TestInstanceReload(t *testing.T) {
+	newInbound := func(tag string, port net.Port) *InboundHandlerConfig {
+		return &InboundHandlerConfig{
+			Tag: tag,
+			ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+				PortRange: net.SinglePortRange(port),
+				Listen:    net.NewIPOrDomain(net.LocalHostIP),
+			}),
+			ProxySettings: serial.ToTypedMessage(&dokodemo.Config{
+				Address:  net.NewIPOrDomain(net.LocalHostIP),
+				Port:     80,
+				Networks: []net.Network{net.Network_TCP},
+			}),
+		}
+	}
+	newConfig := func(handshake uint32, inbounds ...*InboundHandlerConfig) *Config {
+		return &Config{
+			App: []*anypb.Any{
+				serial.ToTypedMessage(&dispatcher.Config{}),
+				serial.ToTypedMessage(&proxyman.InboundConfig{}),
+				serial.ToTypedMessage(&proxyman.OutboundConfig{}),
+				serial.ToTypedMessage(&policy.Config{
+					Level: map[uint32]*policy.Policy{
+						0: {Timeout: &policy.Policy_Timeout{Handshake: &policy.Second{Value: handshake}}},
+					},
+				}),
+			},
+			Inbound: inbounds,
+			Outbound: []*OutboundHandlerConfig{
+				{
+					Tag:           "direct",
+					ProxySettings: serial.ToTypedMessage(&freedom.Config{}),
+				},
+			},
+		}
+	}
+
+	a := newInbound("a", tcp.PickPort())
+	config := newConfig(4, a, newInbound("b", tcp.PickPort()))
+	server, err := New(config)
+	common.Must(err)
+	common.Must(server.Start())
+	defer server.Close()
+
+	// The app settings are referenced, not copied.
+	for i, app := range server.RunningConfig().App {
+		if app != config.App[i] {
+			t.Error("expect app settings to be shared: ", app.TypeUrl)
+		}
+	}
+
+	inboundManager := server.GetFeature(inbound.ManagerType()).(inbound.Manager)
+	handlerA, err := inboundManager.GetHandler(context.Background(), "a")
+	common.Must(err)
+
+	changes, err := server.Reload(newConfig(8, a, newInbound("b", tcp.PickPort()), newInbound("c", tcp.PickPort())))
+	common.Must(err)
+	expected := []string{
+		"app " + serial.GetMessageType(&policy.Config{}) + " reloaded",
+		"inbound b updated",
+		"inbound c added",
+	}
+	if r := cmp.Diff(changes, expected); r != "" {
+		t.Error(r)
+	}
+
+	if h, err := inboundManager.GetHandler(context.Background(), "a"); err != nil || h != handlerA {
+		t.Error("expect inbound a to be kept")
+	}
+	if tags := len(server.RunningConfig().Inbound); tags != 3 {
+		t.Error("expect 3 inbounds, but got ", tags)
+	}
+
+	changes, err = server.Reload(newConfig(8, a))
+	common.Must(err)
+	if r := cmp.Diff(changes, []string{"inbound b removed", "inbound c removed"}); r != "" {
+		t.Error(r)
+	}
+
+	// The new port of inbound a is taken, so the old handler must be kept.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	defer listener.Close()
+	taken := newInbound("a", net.Port(listener.Addr().(*net.TCPAddr).Port))
+	changes, err = server.Reload(newConfig(12, taken))
+	if err == nil {
+		t.Fatal("expect reload to fail")
+	}
+	if r := cmp.Diff(changes, []string{"app " + serial.GetMessageType(&policy.Config{}) + " reloaded"}); r != "" {
+		t.Error(r)
+	}
+	if _, err := inboundManager.GetHandler(context.Background(), "a"); err != nil {
+		t.Error("expect inbound a to be restored: ", err)
+	}
+	running := server.RunningConfig()
+	if r := cmp.Diff(running.Inbound, []*InboundHandlerConfig{a}, protocmp.Transform()); r != "" {
+		t.Error(r)
+	}
+	if r := cmp.Diff(running.App, newConfig(12).App, protocmp.Transform()); r != "" {
+		t.Error(r)
+	}
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/v2fly/v2ray-core/v5/features/inbound"
+	"github.com/v2fly/v2ray-core/v5/features/outbound"
+)
+
+// runningConfig keeps track of the handler configs of an Instance as they are
+// changed at runtime.
+//
+// The app settings may hold tens of MB of rules and geo data, so they are
+// referenced rather than copied. They are never modified, neither here nor by
+// the apps that are created from them.
+type runningConfig struct {
+	access    sync.Mutex
+	base      *Config
+	inbounds  []*InboundHandlerConfig
+	outbounds []*OutboundHandlerConfig
+}
+
+func (c *runningConfig) setBase(config *Config) {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	c.base = shallowCopy(config)
+}
+
+// shallowCopy returns a copy of config without handlers, which shares the other settings with config.
+func shallowCopy(config *Config) *Config {
+	return &Config{
+		App:       append([]*anypb.Any(nil), config.App...),
+		Transport: config.Transport,
+		Extension: append([]*anypb.Any(nil), config.Extension...),
+	}
+}
+
+func (c *runningConfig) putInbound(config *InboundHandlerConfig) {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	if config.Tag != "" {
+		for i, existing := range c.inbounds {
+			if existing.Tag == config.Tag {
+				c.inbounds[i] = config
+				return
+			}
+		}
+	}
+	c.inbounds = append(c.inbounds, config)
+}
+
+func (c *runningConfig) removeInbound(tag string) {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	for i, existing := range c.inbounds {
+		if existing.Tag == tag {
+			c.inbounds = append(c.inbounds[:i:i], c.inbounds[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *runningConfig) putOutbound(config *OutboundHandlerConfig) {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	if config.Tag != "" {
+		for i, existing := range c.outbounds {
+			if existing.Tag == config.Tag {
+				c.outbounds[i] = config
+				return
+			}
+		}
+	}
+	c.outbounds = append(c.outbounds, config)
+}
+
+func (c *runningConfig) removeOutbound(tag string) {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	for i, existing := range c.outbounds {
+		if existing.Tag == tag {
+			c.outbounds = append(c.outbounds[:i:i], c.outbounds[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *runningConfig) snapshot() *Config {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	config := &Config{}
+	if c.base != nil {
+		config = shallowCopy(c.base)
+	}
+	for _, inbound := range c.inbounds {
+		config.Inbound = append(config.Inbound, proto.Clone(inbound).(*InboundHandlerConfig))
+	}
+	for _, outbound := range c.outbounds {
+		config.Outbound = append(config.Outbound, proto.Clone(outbound).(*OutboundHandlerConfig))
+	}
+	return config
+}
+
+// RunningConfig returns the config this instance was created with, updated
+// with the inbound and outbound handlers that have been added, removed or
+// altered at runtime. The app settings in it must not be modified.
+func (s *Instance) RunningConfig() *Config {
+	return s.runningConfig.snapshot()
+}
+
+// RemoveInboundHandler removes the inbound handler with the given tag.
+func RemoveInboundHandler(server *Instance, tag string) error {
+	inboundManager := server.GetFeature(inbound.ManagerType()).(inbound.Manager)
+	if err := inboundManager.RemoveHandler(server.ctx, tag); err != nil {
+		return err
+	}
+	server.runningConfig.removeInbound(tag)
+	return nil
+}
+
+// RemoveOutboundHandler removes the outbound handler with the given tag.
+func RemoveOutboundHandler(server *Instance, tag string) error {
+	outboundManager := server.GetFeature(outbound.ManagerType()).(outbound.Manager)
+	if err := outboundManager.RemoveHandler(server.ctx, tag); err != nil {
+		return err
+	}
+	server.runningConfig.removeOutbound(tag)
+	return nil
+}
+
+// UpdateInboundHandlerConfig records a change made to a running inbound
+// handler, so that it is reflected by Instance.RunningConfig. The handler
+// itself is not touched.
+func UpdateInboundHandlerConfig(server *Instance, tag string, update func(*InboundHandlerConfig) error) error {
+	c := &server.runningConfig
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	for i, existing := range c.inbounds {
+		if existing.Tag == tag {
+			config := proto.Clone(existing).(*InboundHandlerConfig)
+			if err := update(config); err != nil {
+				return err
+			}
+			c.inbounds[i] = config
+			return nil
+		}
+	}
+	return newError("inbound handler not found: ", tag)
+}
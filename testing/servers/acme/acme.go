@@ -0,0 +1,595 @@
+// Package acme implements a minimal ACME (RFC 8555) server standing in for pebble in tests.
+// It trusts every request without checking signatures or nonces, but validates the challenges
+// against local ports and signs the certificates with its own CA.
+package acme
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common/net"
+)
+
+const (
+	ChallengeHTTP01    = "http-01"
+	ChallengeTLSALPN01 = "tls-alpn-01"
+)
+
+var idPeACMEIdentifier = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}
+
+type Server struct {
+	Port net.Port
+	// HTTPPort and TLSPort are the ports on localhost to validate the HTTP-01 and TLS-ALPN-01 challenges against.
+	HTTPPort net.Port
+	TLSPort  net.Port
+	// Challenges offered in each authorization. Both types are offered if empty.
+	Challenges []string
+	// Validity of the issued certificates. 90 days if zero.
+	Validity time.Duration
+
+	server *http.Server
+	caCert *x509.Certificate
+	caKey  crypto.Signer
+	caPEM  []byte
+
+	access  sync.Mutex
+	nextID  int
+	orders  map[string]*order
+	authzs  map[string]*authorization
+	certs   map[string][]byte
+	issued  int
+	account map[string]string // account URL to key thumbprint
+}
+
+type identifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type challenge struct {
+	Type   string `json:"type"`
+	URL    string `json:"url"`
+	Token  string `json:"token"`
+	Status string `json:"status"`
+}
+
+type authorization struct {
+	Identifier identifier   `json:"identifier"`
+	Status     string       `json:"status"`
+	Challenges []*challenge `json:"challenges"`
+
+	thumbprint string
+}
+
+type order struct {
+	Status         string       `json:"status"`
+	Identifiers    []identifier `json:"identifiers"`
+	Authorizations []string     `json:"authorizations"`
+	Finalize       string       `json:"finalize"`
+	Certificate    string       `json:"certificate,omitempty"`
+
+	url string
+}
+
+type request struct {
+	kid     string
+	jwk     map[string]string
+	payload []byte
+}
+
+func (s *Server) Start() (net.Destination, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return net.Destination{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "V2Ray Test ACME CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour * 24 * 365),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return net.Destination{}, err
+	}
+	s.caCert, _ = x509.ParseCertificate(der)
+	s.caKey = key
+	s.caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	s.orders = make(map[string]*order)
+	s.authzs = make(map[string]*authorization)
+	s.certs = make(map[string][]byte)
+	s.account = make(map[string]string)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:"+s.Port.String())
+	if err != nil {
+		return net.Destination{}, err
+	}
+	s.Port = net.Port(listener.Addr().(*net.TCPAddr).Port)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/directory", s.handleDirectory)
+	mux.HandleFunc("/nonce", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/account", s.handleAccount)
+	mux.HandleFunc("/order", s.handleNewOrder)
+	mux.HandleFunc("/order/", s.handleOrder)
+	mux.HandleFunc("/authz/", s.handleAuthorization)
+	mux.HandleFunc("/challenge/", s.handleChallenge)
+	mux.HandleFunc("/finalize/", s.handleFinalize)
+	mux.HandleFunc("/cert/", s.handleCertificate)
+	s.server = &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Replay-Nonce", strconv.FormatInt(time.Now().UnixNano(), 36))
+			w.Header().Set("Cache-Control", "no-store")
+			mux.ServeHTTP(w, r)
+		}),
+	}
+	go s.server.Serve(listener)
+
+	return net.TCPDestination(net.LocalHostIP, s.Port), nil
+}
+
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+// DirectoryURL returns the URL for ACME clients to start with.
+func (s *Server) DirectoryURL() string {
+	return s.url("/directory")
+}
+
+// CACertificate returns the PEM of the CA that signs the issued certificates.
+func (s *Server) CACertificate() []byte {
+	return s.caPEM
+}
+
+// Issued returns the number of certificates issued so far.
+func (s *Server) Issued() int {
+	s.access.Lock()
+	defer s.access.Unlock()
+	return s.issued
+}
+
+func (s *Server) url(path string) string {
+	return "http://127.0.0.1:" + s.Port.String() + path
+}
+
+func (s *Server) newID() string {
+	s.nextID++
+	return strconv.Itoa(s.nextID)
+}
+
+func writeJSON(w http.ResponseWriter, status int, location string, v interface{}) {
+	if location != "" {
+		w.Header().Set("Location", location)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeProblem(w http.ResponseWriter, status int, typ string, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"type":   "urn:ietf:params:acme:error:" + typ,
+		"detail": detail,
+	})
+}
+
+func readRequest(r *http.Request) (*request, error) {
+	var body struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	protected, err := base64.RawURLEncoding.DecodeString(body.Protected)
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		KID string            `json:"kid"`
+		JWK map[string]string `json:"jwk"`
+	}
+	if err := json.Unmarshal(protected, &header); err != nil {
+		return nil, err
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return &request{kid: header.KID, jwk: header.JWK, payload: payload}, nil
+}
+
+// thumbprint computes the RFC 7638 thumbprint of the key.
+func thumbprint(jwk map[string]string) (string, error) {
+	var canonical string
+	switch jwk["kty"] {
+	case "EC":
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, jwk["crv"], jwk["x"], jwk["y"])
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk["e"], jwk["n"])
+	default:
+		return "", fmt.Errorf("unsupported key type %q", jwk["kty"])
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func (s *Server) handleDirectory(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, "", map[string]string{
+		"newNonce":   s.url("/nonce"),
+		"newAccount": s.url("/account"),
+		"newOrder":   s.url("/order"),
+		"revokeCert": s.url("/revoke"),
+		"keyChange":  s.url("/key-change"),
+	})
+}
+
+func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request) {
+	req, err := readRequest(r)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "malformed", err.Error())
+		return
+	}
+	key, err := thumbprint(req.jwk)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "badPublicKey", err.Error())
+		return
+	}
+
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	location := s.url("/account/" + key)
+	status := http.StatusOK
+	if _, found := s.account[location]; !found {
+		s.account[location] = key
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, location, map[string]string{"status": "valid"})
+}
+
+func (s *Server) handleNewOrder(w http.ResponseWriter, r *http.Request) {
+	req, err := readRequest(r)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "malformed", err.Error())
+		return
+	}
+	var payload struct {
+		Identifiers []identifier `json:"identifiers"`
+	}
+	if err := json.Unmarshal(req.payload, &payload); err != nil || len(payload.Identifiers) == 0 {
+		writeProblem(w, http.StatusBadRequest, "malformed", "no identifier")
+		return
+	}
+
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	key, found := s.account[req.kid]
+	if !found {
+		writeProblem(w, http.StatusBadRequest, "accountDoesNotExist", req.kid)
+		return
+	}
+
+	challenges := s.Challenges
+	if len(challenges) == 0 {
+		challenges = []string{ChallengeTLSALPN01, ChallengeHTTP01}
+	}
+
+	id := s.newID()
+	o := &order{
+		Status:      "pending",
+		Identifiers: payload.Identifiers,
+		Finalize:    s.url("/finalize/" + id),
+		url:         s.url("/order/" + id),
+	}
+	for _, ident := range payload.Identifiers {
+		authzID := s.newID()
+		authz := &authorization{
+			Identifier: ident,
+			Status:     "pending",
+			thumbprint: key,
+		}
+		for _, typ := range challenges {
+			var token [16]byte
+			rand.Read(token[:])
+			authz.Challenges = append(authz.Challenges, &challenge{
+				Type:   typ,
+				URL:    s.url("/challenge/" + authzID + "/" + typ),
+				Token:  base64.RawURLEncoding.EncodeToString(token[:]),
+				Status: "pending",
+			})
+		}
+		s.authzs[authzID] = authz
+		o.Authorizations = append(o.Authorizations, s.url("/authz/"+authzID))
+	}
+	s.orders[id] = o
+	writeJSON(w, http.StatusCreated, o.url, o)
+}
+
+// updateOrders marks the orders ready or invalid according to their authorizations.
+func (s *Server) updateOrders() {
+	for _, o := range s.orders {
+		if o.Status != "pending" {
+			continue
+		}
+		ready := true
+		for _, authzURL := range o.Authorizations {
+			authz := s.authzs[authzURL[strings.LastIndex(authzURL, "/")+1:]]
+			switch authz.Status {
+			case "valid":
+			case "pending":
+				ready = false
+			default:
+				o.Status = "invalid"
+			}
+		}
+		if ready && o.Status == "pending" {
+			o.Status = "ready"
+		}
+	}
+}
+
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request) {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	o, found := s.orders[strings.TrimPrefix(r.URL.Path, "/order/")]
+	if !found {
+		writeProblem(w, http.StatusNotFound, "malformed", "no such order")
+		return
+	}
+	writeJSON(w, http.StatusOK, o.url, o)
+}
+
+func (s *Server) handleAuthorization(w http.ResponseWriter, r *http.Request) {
+	req, err := readRequest(r)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "malformed", err.Error())
+		return
+	}
+
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	authz, found := s.authzs[strings.TrimPrefix(r.URL.Path, "/authz/")]
+	if !found {
+		writeProblem(w, http.StatusNotFound, "malformed", "no such authorization")
+		return
+	}
+	var payload struct {
+		Status string `json:"status"`
+	}
+	if json.Unmarshal(req.payload, &payload) == nil && payload.Status == "deactivated" {
+		authz.Status = "deactivated"
+		s.updateOrders()
+	}
+	writeJSON(w, http.StatusOK, "", authz)
+}
+
+func (s *Server) handleChallenge(w http.ResponseWriter, r *http.Request) {
+	authzID, typ, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/challenge/"), "/")
+
+	s.access.Lock()
+	authz, found := s.authzs[authzID]
+	var chal *challenge
+	if found {
+		for _, c := range authz.Challenges {
+			if c.Type == typ {
+				chal = c
+			}
+		}
+	}
+	s.access.Unlock()
+
+	if chal == nil {
+		writeProblem(w, http.StatusNotFound, "malformed", "no such challenge")
+		return
+	}
+
+	// Validate synchronously, so that the authorization is settled when the client polls it.
+	keyAuth := chal.Token + "." + authz.thumbprint
+	var err error
+	switch chal.Type {
+	case ChallengeHTTP01:
+		err = s.validateHTTP01(authz.Identifier.Value, chal.Token, keyAuth)
+	case ChallengeTLSALPN01:
+		err = s.validateTLSALPN01(authz.Identifier.Value, keyAuth)
+	}
+
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	if authz.Status == "pending" {
+		if err != nil {
+			chal.Status = "invalid"
+			authz.Status = "invalid"
+		} else {
+			chal.Status = "valid"
+			authz.Status = "valid"
+		}
+		s.updateOrders()
+	}
+	writeJSON(w, http.StatusOK, "", chal)
+}
+
+func (s *Server) validateHTTP01(domain string, token string, keyAuth string) error {
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:"+s.HTTPPort.String()+"/.well-known/acme-challenge/"+token, nil)
+	if err != nil {
+		return err
+	}
+	req.Host = domain
+	client := &http.Client{Timeout: time.Second * 5}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(body)) != keyAuth {
+		return fmt.Errorf("unexpected HTTP-01 response: %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *Server) validateTLSALPN01(domain string, keyAuth string) error {
+	dialer := &net.Dialer{Timeout: time.Second * 5}
+	conn, err := tls.DialWithDialer(dialer, "tcp", "127.0.0.1:"+s.TLSPort.String(), &tls.Config{
+		ServerName:         domain,
+		NextProtos:         []string{"acme-tls/1"},
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	if state.NegotiatedProtocol != "acme-tls/1" {
+		return fmt.Errorf("unexpected protocol %q", state.NegotiatedProtocol)
+	}
+	leaf := state.PeerCertificates[0]
+	if err := leaf.VerifyHostname(domain); err != nil {
+		return err
+	}
+	sum := sha256.Sum256([]byte(keyAuth))
+	expected, err := asn1.Marshal(sum[:])
+	if err != nil {
+		return err
+	}
+	for _, ext := range leaf.Extensions {
+		if ext.Id.Equal(idPeACMEIdentifier) {
+			if !ext.Critical || string(ext.Value) != string(expected) {
+				return fmt.Errorf("invalid acmeIdentifier extension")
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no acmeIdentifier extension")
+}
+
+func (s *Server) handleFinalize(w http.ResponseWriter, r *http.Request) {
+	req, err := readRequest(r)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "malformed", err.Error())
+		return
+	}
+	var payload struct {
+		CSR string `json:"csr"`
+	}
+	if err := json.Unmarshal(req.payload, &payload); err != nil {
+		writeProblem(w, http.StatusBadRequest, "malformed", err.Error())
+		return
+	}
+	der, err := base64.RawURLEncoding.DecodeString(payload.CSR)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "badCSR", err.Error())
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, "badCSR", err.Error())
+		return
+	}
+	if err := csr.CheckSignature(); err != nil {
+		writeProblem(w, http.StatusBadRequest, "badCSR", err.Error())
+		return
+	}
+
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	id := strings.TrimPrefix(r.URL.Path, "/finalize/")
+	o, found := s.orders[id]
+	if !found {
+		writeProblem(w, http.StatusNotFound, "malformed", "no such order")
+		return
+	}
+	if o.Status != "ready" {
+		writeProblem(w, http.StatusForbidden, "orderNotReady", o.Status)
+		return
+	}
+	for _, name := range csr.DNSNames {
+		authorized := false
+		for _, ident := range o.Identifiers {
+			if ident.Value == name {
+				authorized = true
+			}
+		}
+		if !authorized {
+			writeProblem(w, http.StatusForbidden, "unauthorized", name)
+			return
+		}
+	}
+
+	validity := s.Validity
+	if validity == 0 {
+		validity = time.Hour * 24 * 90
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "serverInternal", err.Error())
+		return
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: csr.DNSNames[0]},
+		DNSNames:     csr.DNSNames,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, s.caCert, csr.PublicKey, s.caKey)
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "serverInternal", err.Error())
+		return
+	}
+
+	s.certs[id] = append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), s.caPEM...)
+	s.issued++
+	o.Status = "valid"
+	o.Certificate = s.url("/cert/" + id)
+	writeJSON(w, http.StatusOK, o.url, o)
+}
+
+func (s *Server) handleCertificate(w http.ResponseWriter, r *http.Request) {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	chain, found := s.certs[strings.TrimPrefix(r.URL.Path, "/cert/")]
+	if !found {
+		writeProblem(w, http.StatusNotFound, "malformed", "no such certificate")
+		return
+	}
+	w.Header().Set("Content-Type", "application/pem-certificate-chain")
+	w.Write(chain)
+}
@@ -4,6 +4,7 @@ import (
 	"net"
 
 	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/features/stats"
 )
 
@@ -11,6 +12,16 @@ type Connection interface {
 	net.Conn
 }
 
+// PathConnection is a Connection accepted on a path, like a WebSocket connection.
+type PathConnection interface {
+	// Path returns the request path that the connection is accepted on.
+	Path() string
+	// PathTag returns the tag of the path entry that the connection matches.
+	PathTag() string
+	// PathUser returns the user of the path entry that the connection matches, or nil if it has none.
+	PathUser() *protocol.MemoryUser
+}
+
 type AbstractPacketConnReader interface {
 	ReadFrom(p []byte) (n int, addr net.Addr, err error)
 }
@@ -58,6 +58,9 @@ func (fw flushWriter) Write(p []byte) (n int, err error) {
 }
 
 func (l *Listener) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if tls.ServeACMEChallenge(writer, request) {
+		return
+	}
 	host := request.Host
 	if !l.config.isValidHost(host) {
 		writer.WriteHeader(404)
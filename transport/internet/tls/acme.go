@@ -0,0 +1,143 @@
+package tls
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"github.com/v2fly/v2ray-core/v5/common/net"
+)
+
+const acmeChallengePath = "/.well-known/acme-challenge/"
+
+type acmeManager struct {
+	manager *autocert.Manager
+	domains []string
+	http    http.Handler
+}
+
+// acmeManagers holds one manager per distinct ACME config, so that all listeners sharing a config
+// also share its certificates, its renewal and its pending challenges.
+var acmeManagers = struct {
+	sync.Mutex
+	managers map[string]*acmeManager
+}{
+	managers: make(map[string]*acmeManager),
+}
+
+func (c *ACME) key() string {
+	domains := make([]string, 0, len(c.Domain))
+	for _, domain := range c.Domain {
+		domains = append(domains, strings.ToLower(domain))
+	}
+	sort.Strings(domains)
+	return strings.Join([]string{
+		c.DirectoryUrl,
+		c.StorageDir,
+		c.Email,
+		strconv.FormatUint(uint64(c.RenewBefore), 10),
+		strings.Join(domains, ","),
+	}, "|")
+}
+
+func getACMEManager(config *ACME) (*acmeManager, error) {
+	if len(config.Domain) == 0 {
+		return nil, newError("no domain specified for ACME")
+	}
+
+	key := config.key()
+	acmeManagers.Lock()
+	defer acmeManagers.Unlock()
+
+	if m, found := acmeManagers.managers[key]; found {
+		return m, nil
+	}
+
+	manager := &autocert.Manager{
+		Prompt:      autocert.AcceptTOS,
+		HostPolicy:  autocert.HostWhitelist(config.Domain...),
+		RenewBefore: time.Duration(config.RenewBefore) * time.Second,
+		Email:       config.Email,
+		Client:      &acme.Client{DirectoryURL: config.DirectoryUrl},
+	}
+	if len(config.StorageDir) > 0 {
+		manager.Cache = autocert.DirCache(config.StorageDir)
+	} else {
+		newError("ACME certificates are not stored, they are requested again after restart").AtWarning().WriteToLog()
+	}
+
+	m := &acmeManager{
+		manager: manager,
+		http:    manager.HTTPHandler(http.NotFoundHandler()),
+	}
+	for _, domain := range config.Domain {
+		m.domains = append(m.domains, strings.ToLower(domain))
+	}
+	acmeManagers.managers[key] = m
+	return m, nil
+}
+
+func (m *acmeManager) manages(domain string) bool {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	for _, d := range m.domains {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// getCertificateFunc serves the ACME certificates for the managed domains, and leaves the others to next,
+// or to the static certificates if next is nil.
+func (m *acmeManager) getCertificateFunc(next func(*tls.ClientHelloInfo) (*tls.Certificate, error)) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		if m.manages(hello.ServerName) {
+			return m.manager.GetCertificate(hello)
+		}
+		if next != nil {
+			return next(hello)
+		}
+		return nil, nil
+	}
+}
+
+// ServeACMEChallenge answers an ACME HTTP-01 challenge request for a domain managed by any ACME config,
+// so that plain HTTP listeners can take over the challenges for the TLS ones.
+// It returns false if the request is not such a challenge.
+func ServeACMEChallenge(writer http.ResponseWriter, request *http.Request) bool {
+	if !strings.HasPrefix(request.URL.Path, acmeChallengePath) {
+		return false
+	}
+
+	host := request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	var manager *acmeManager
+	acmeManagers.Lock()
+	for _, m := range acmeManagers.managers {
+		if m.manages(host) {
+			manager = m
+			break
+		}
+	}
+	acmeManagers.Unlock()
+
+	if manager == nil {
+		return false
+	}
+
+	newError("serving ACME challenge for ", host).AtInfo().WriteToLog()
+	r := *request
+	r.Host = host
+	manager.http.ServeHTTP(writer, &r)
+	return true
+}
@@ -0,0 +1,156 @@
+package tls_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	gotls "crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/acme"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/tcp"
+	. "github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+func startACMEListener(t *testing.T, port net.Port, config *ACME) net.Listener {
+	listener, err := gotls.Listen("tcp", "127.0.0.1:"+port.String(), (&Config{Acme: config}).GetTLSConfig())
+	common.Must(err)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.(*gotls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return listener
+}
+
+func dialACMEListener(listener net.Listener, domain string, roots *x509.CertPool) (*x509.Certificate, error) {
+	conn, err := gotls.Dial("tcp", listener.Addr().String(), &gotls.Config{
+		ServerName:         domain,
+		RootCAs:            roots,
+		InsecureSkipVerify: roots == nil, // nolint: gosec
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestACMETLSALPN01(t *testing.T) {
+	const domain = "alpn.v2fly.test"
+
+	port := tcp.PickPort()
+
+	ca := &acme.Server{
+		TLSPort:    port,
+		Challenges: []string{acme.ChallengeTLSALPN01},
+	}
+	common.Must2(ca.Start())
+	defer ca.Close()
+
+	storage := t.TempDir()
+	listener := startACMEListener(t, port, &ACME{
+		Domain:       []string{domain},
+		DirectoryUrl: ca.DirectoryURL(),
+		StorageDir:   storage,
+	})
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.CACertificate())
+	leaf, err := dialACMEListener(listener, domain, roots)
+	if err != nil {
+		t.Fatal("failed to verify ACME certificate: ", err)
+	}
+	if leaf.Subject.CommonName != domain {
+		t.Error("unexpected certificate: ", leaf.Subject)
+	}
+	if _, err := os.Stat(filepath.Join(storage, domain)); err != nil {
+		t.Error("certificate not stored: ", err)
+	}
+
+	// Served from memory afterwards.
+	common.Must2(dialACMEListener(listener, domain, roots))
+	if n := ca.Issued(); n != 1 {
+		t.Error("expected 1 certificate issued, but got ", n)
+	}
+}
+
+func TestACMERenewal(t *testing.T) {
+	const domain = "renew.v2fly.test"
+
+	storage := t.TempDir()
+
+	// A certificate expiring within the renewal window is already in the storage.
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	common.Must(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    time.Now().Add(-time.Hour * 24),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	common.Must(err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	common.Must(err)
+	stored := append(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	common.Must(os.WriteFile(filepath.Join(storage, domain), stored, 0o600))
+
+	port := tcp.PickPort()
+
+	ca := &acme.Server{
+		TLSPort:    port,
+		Challenges: []string{acme.ChallengeTLSALPN01},
+	}
+	common.Must2(ca.Start())
+	defer ca.Close()
+
+	listener := startACMEListener(t, port, &ACME{
+		Domain:       []string{domain},
+		DirectoryUrl: ca.DirectoryURL(),
+		StorageDir:   storage,
+	})
+
+	leaf, err := dialACMEListener(listener, domain, nil)
+	common.Must(err)
+	if leaf.SerialNumber.Int64() != 1 {
+		t.Fatal("expected the stored certificate to be served first")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.CACertificate())
+	deadline := time.Now().Add(time.Second * 20)
+	for {
+		if _, err := dialACMEListener(listener, domain, roots); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("certificate not renewed")
+		}
+		time.Sleep(time.Millisecond * 200)
+	}
+
+	renewed, err := os.ReadFile(filepath.Join(storage, domain))
+	common.Must(err)
+	if string(renewed) == string(stored) {
+		t.Error("renewed certificate not stored")
+	}
+}
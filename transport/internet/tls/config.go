@@ -9,6 +9,8 @@ import (
 	"sync"
 	"time"
 
+	"golang.org/x/crypto/acme"
+
 	"github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/common/protocol/tls/cert"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
@@ -44,9 +46,14 @@ func (c *Config) loadSelfCertPool(usage Certificate_Usage) (*x509.CertPool, erro
 
 // BuildCertificates builds a list of TLS certificates from proto definition.
 func (c *Config) BuildCertificates() []tls.Certificate {
+	return c.buildCertificates(false)
+}
+
+// buildCertificates builds the certificates, leaving out the ones loaded from files if skipFiles is true.
+func (c *Config) buildCertificates(skipFiles bool) []tls.Certificate {
 	certs := make([]tls.Certificate, 0, len(c.Certificate))
 	for _, entry := range c.Certificate {
-		if entry.Usage != Certificate_ENCIPHERMENT {
+		if entry.Usage != Certificate_ENCIPHERMENT || (skipFiles && entry.CertificateFile != "") {
 			continue
 		}
 		keyPair, err := tls.X509KeyPair(entry.Certificate, entry.Key)
@@ -230,7 +237,9 @@ func (c *Config) GetTLSConfig(opts ...Option) *tls.Config {
 		opt(config)
 	}
 
-	config.Certificates = c.BuildCertificates()
+	// Certificates from files are served through GetCertificate, so that they can be reloaded.
+	files := c.getFileCertificates()
+	config.Certificates = c.buildCertificates(true)
 	config.BuildNameToCertificate()
 
 	caCerts := c.getCustomCA()
@@ -238,6 +247,11 @@ func (c *Config) GetTLSConfig(opts ...Option) *tls.Config {
 		config.GetCertificate = getGetCertificateFunc(config, caCerts)
 	}
 
+	if len(files) > 0 {
+		config.GetCertificate = getFileCertificateFunc(config, files, config.GetCertificate)
+		config.GetClientCertificate = getFileClientCertificateFunc(config, files)
+	}
+
 	if sn := c.parseServerName(); len(sn) > 0 {
 		config.ServerName = sn
 	}
@@ -246,6 +260,16 @@ func (c *Config) GetTLSConfig(opts ...Option) *tls.Config {
 		config.NextProtos = []string{"h2", "http/1.1"}
 	}
 
+	if c.Acme != nil {
+		manager, err := getACMEManager(c.Acme)
+		if err != nil {
+			newError("failed to set up ACME").AtError().Base(err).WriteToLog()
+		} else {
+			config.GetCertificate = manager.getCertificateFunc(config.GetCertificate)
+			config.NextProtos = append(append([]string(nil), config.NextProtos...), acme.ALPNProto)
+		}
+	}
+
 	if c.VerifyClientCertificate {
 		config.ClientAuth = tls.RequireAndVerifyClientCert
 	}
@@ -0,0 +1,257 @@
+package tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common/errors"
+	"github.com/v2fly/v2ray-core/v5/common/platform/filesystem"
+)
+
+// certificateCheckInterval is how often the certificate files are checked for changes, at most.
+var certificateCheckInterval = time.Second * 10
+
+// fileCertificate is a certificate loaded from files, which is reloaded when the files change.
+type fileCertificate struct {
+	certificateFile string
+	keyFile         string
+	key             []byte // used if keyFile is empty
+
+	access    sync.RWMutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+	loadedAt  time.Time
+}
+
+// fileCertificates holds the certificates of all configs by their files, so that a reload
+// takes effect on every listener using them.
+var fileCertificates = struct {
+	sync.Mutex
+	certs map[string]*fileCertificate
+}{
+	certs: make(map[string]*fileCertificate),
+}
+
+func getFileCertificate(entry *Certificate) *fileCertificate {
+	key := entry.CertificateFile + "\x00" + entry.KeyFile
+
+	fileCertificates.Lock()
+	defer fileCertificates.Unlock()
+
+	if f, found := fileCertificates.certs[key]; found {
+		return f
+	}
+
+	f := &fileCertificate{
+		certificateFile: entry.CertificateFile,
+		keyFile:         entry.KeyFile,
+		key:             entry.Key,
+	}
+	// The files were read when the config was loaded; start from that content unless they have changed since.
+	if cert, err := parseKeyPair(entry.Certificate, entry.Key); err == nil {
+		f.cert = cert
+		f.modTime = f.lastModified()
+		f.loadedAt = time.Now()
+	}
+	f.checkedAt = time.Now()
+	if f.cert == nil {
+		if err := f.reload(); err != nil {
+			newError("failed to load certificate from ", f.certificateFile).Base(err).AtWarning().WriteToLog()
+		}
+	}
+	fileCertificates.certs[key] = f
+	return f
+}
+
+func parseKeyPair(certificate, key []byte) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(certificate, key)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	cert.Leaf = leaf
+	return &cert, nil
+}
+
+// lastModified returns the latest modification time of the files.
+func (f *fileCertificate) lastModified() time.Time {
+	var modTime time.Time
+	for _, file := range []string{f.certificateFile, f.keyFile} {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil && info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return modTime
+}
+
+// reload reads the files. The loaded certificate is kept if they are not a valid pair,
+// which is also the case while they are being replaced one after another.
+func (f *fileCertificate) reload() error {
+	modTime := f.lastModified()
+	certificate, err := filesystem.ReadFile(f.certificateFile)
+	if err != nil {
+		return newError("failed to read certificate file").Base(err)
+	}
+	key := f.key
+	if f.keyFile != "" {
+		key, err = filesystem.ReadFile(f.keyFile)
+		if err != nil {
+			return newError("failed to read key file").Base(err)
+		}
+	}
+	cert, err := parseKeyPair(certificate, key)
+	if err != nil {
+		return newError("invalid X509 key pair").Base(err)
+	}
+
+	f.access.Lock()
+	f.cert = cert
+	f.modTime = modTime
+	f.loadedAt = time.Now()
+	f.access.Unlock()
+
+	newError("certificate loaded from ", f.certificateFile, " (expire on ", cert.Leaf.NotAfter.Format(time.RFC3339), ")").AtInfo().WriteToLog()
+	return nil
+}
+
+// get returns the current certificate, reloading it first if the files have changed.
+func (f *fileCertificate) get() *tls.Certificate {
+	f.access.Lock()
+	check := time.Since(f.checkedAt) >= certificateCheckInterval
+	if check {
+		f.checkedAt = time.Now()
+	}
+	modTime := f.modTime
+	f.access.Unlock()
+
+	if check && !f.lastModified().Equal(modTime) {
+		if err := f.reload(); err != nil {
+			newError("failed to reload certificate from ", f.certificateFile).Base(err).AtWarning().WriteToLog()
+		}
+	}
+
+	f.access.RLock()
+	defer f.access.RUnlock()
+	return f.cert
+}
+
+func (f *fileCertificate) status() *CertificateStatus {
+	f.access.RLock()
+	defer f.access.RUnlock()
+
+	status := &CertificateStatus{
+		CertificateFile: f.certificateFile,
+		KeyFile:         f.keyFile,
+	}
+	if f.cert != nil {
+		leaf := f.cert.Leaf
+		status.Subject = leaf.Subject.String()
+		status.Issuer = leaf.Issuer.String()
+		status.Domain = leaf.DNSNames
+		status.NotBefore = leaf.NotBefore.Unix()
+		status.NotAfter = leaf.NotAfter.Unix()
+		status.LoadedAt = f.loadedAt.Unix()
+	}
+	return status
+}
+
+func (c *Config) getFileCertificates() []*fileCertificate {
+	var files []*fileCertificate
+	for _, entry := range c.Certificate {
+		if entry.Usage == Certificate_ENCIPHERMENT && entry.CertificateFile != "" {
+			files = append(files, getFileCertificate(entry))
+		}
+	}
+	return files
+}
+
+// getFileCertificateFunc serves the first file certificate that suits the client, and leaves the rest to next,
+// or to the static certificates if next is nil.
+func getFileCertificateFunc(c *tls.Config, files []*fileCertificate, next func(*tls.ClientHelloInfo) (*tls.Certificate, error)) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		var first *tls.Certificate
+		for _, f := range files {
+			cert := f.get()
+			if cert == nil {
+				continue
+			}
+			if hello.SupportsCertificate(cert) == nil {
+				return cert, nil
+			}
+			if first == nil {
+				first = cert
+			}
+		}
+		if next != nil {
+			return next(hello)
+		}
+		if len(c.Certificates) > 0 {
+			return nil, nil
+		}
+		return first, nil
+	}
+}
+
+func getFileClientCertificateFunc(c *tls.Config, files []*fileCertificate) func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return func(request *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		for _, f := range files {
+			if cert := f.get(); cert != nil && request.SupportsCertificate(cert) == nil {
+				return cert, nil
+			}
+		}
+		for i := range c.Certificates {
+			if request.SupportsCertificate(&c.Certificates[i]) == nil {
+				return &c.Certificates[i], nil
+			}
+		}
+		return new(tls.Certificate), nil
+	}
+}
+
+// ReloadCertificates reads all the certificate files again. New handshakes use the reloaded certificates,
+// while established connections are not affected.
+func ReloadCertificates() error {
+	fileCertificates.Lock()
+	files := make([]*fileCertificate, 0, len(fileCertificates.certs))
+	for _, f := range fileCertificates.certs {
+		files = append(files, f)
+	}
+	fileCertificates.Unlock()
+
+	var errs []error
+	for _, f := range files {
+		if err := f.reload(); err != nil {
+			errs = append(errs, newError("failed to reload certificate from ", f.certificateFile).Base(err))
+		}
+	}
+	return errors.Combine(errs...)
+}
+
+// CertificateStatuses returns the status of all the certificates loaded from files.
+func CertificateStatuses() []*CertificateStatus {
+	fileCertificates.Lock()
+	defer fileCertificates.Unlock()
+
+	statuses := make([]*CertificateStatus, 0, len(fileCertificates.certs))
+	for _, f := range fileCertificates.certs {
+		statuses = append(statuses, f.status())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].CertificateFile != statuses[j].CertificateFile {
+			return statuses[i].CertificateFile < statuses[j].CertificateFile
+		}
+		return statuses[i].KeyFile < statuses[j].KeyFile
+	})
+	return statuses
+}
@@ -0,0 +1,130 @@
+package tls
+
+import (
+	gotls "crypto/tls"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/protocol/tls/cert"
+)
+
+func TestFileCertificateReload(t *testing.T) {
+	const domain = "reload.v2fly.test"
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeCertificate := func(org string, modTime time.Time) {
+		certPEM, keyPEM := cert.MustGenerate(nil, cert.DNSNames(domain), cert.CommonName(domain), cert.Organization(org)).ToPEM()
+		common.Must(os.WriteFile(certFile, certPEM, 0o600))
+		common.Must(os.WriteFile(keyFile, keyPEM, 0o600))
+		common.Must(os.Chtimes(certFile, modTime, modTime))
+		common.Must(os.Chtimes(keyFile, modTime, modTime))
+	}
+	writeCertificate("first", time.Now().Add(-time.Hour))
+
+	certPEM, err := os.ReadFile(certFile)
+	common.Must(err)
+	keyPEM, err := os.ReadFile(keyFile)
+	common.Must(err)
+	config := &Config{
+		Certificate: []*Certificate{{
+			Certificate:     certPEM,
+			Key:             keyPEM,
+			CertificateFile: certFile,
+			KeyFile:         keyFile,
+		}},
+	}
+
+	listener, err := gotls.Listen("tcp", "127.0.0.1:0", config.GetTLSConfig())
+	common.Must(err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	dial := func() (*gotls.Conn, string) {
+		conn, err := gotls.Dial("tcp", listener.Addr().String(), &gotls.Config{
+			ServerName:         domain,
+			InsecureSkipVerify: true, // nolint: gosec
+		})
+		common.Must(err)
+		return conn, conn.ConnectionState().PeerCertificates[0].Subject.Organization[0]
+	}
+	served := func() string {
+		conn, org := dial()
+		conn.Close()
+		return org
+	}
+
+	established, org := dial()
+	defer established.Close()
+	if org != "first" {
+		t.Fatal("unexpected certificate: ", org)
+	}
+
+	// Changed files are picked up by new handshakes.
+	defer func(interval time.Duration) {
+		certificateCheckInterval = interval
+	}(certificateCheckInterval)
+	certificateCheckInterval = 0
+	writeCertificate("second", time.Now())
+	if org := served(); org != "second" {
+		t.Error("expected the changed certificate, but got ", org)
+	}
+
+	statuses := CertificateStatuses()
+	found := false
+	for _, status := range statuses {
+		if status.CertificateFile == certFile {
+			found = true
+			if status.Subject != "CN="+domain+",O=second" || len(status.Domain) != 1 || status.Domain[0] != domain || status.NotAfter <= time.Now().Unix() {
+				t.Error("unexpected status: ", status)
+			}
+		}
+	}
+	if !found {
+		t.Error("certificate not in status")
+	}
+
+	// Without watching, the files are read on reload.
+	certificateCheckInterval = time.Hour
+	writeCertificate("third", time.Now().Add(time.Minute))
+	if org := served(); org != "second" {
+		t.Error("expected the loaded certificate, but got ", org)
+	}
+	common.Must(ReloadCertificates())
+	if org := served(); org != "third" {
+		t.Error("expected the reloaded certificate, but got ", org)
+	}
+
+	// A broken pair is not loaded.
+	common.Must(os.WriteFile(keyFile, keyPEM, 0o600))
+	if err := ReloadCertificates(); err == nil {
+		t.Error("expected error for mismatched key")
+	}
+	if org := served(); org != "third" {
+		t.Error("expected the loaded certificate, but got ", org)
+	}
+
+	// The established connection is not disturbed.
+	common.Must2(established.Write([]byte("ping")))
+	b := make([]byte, 4)
+	common.Must2(io.ReadFull(established, b))
+	if string(b) != "ping" {
+		t.Error("unexpected response: ", string(b))
+	}
+}
@@ -2,15 +2,17 @@ package websocket
 
 import (
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
 )
 
 const protocolName = "websocket"
 
-func (c *Config) GetNormalizedPath() string {
-	path := c.Path
+func normalizePath(path string) string {
 	if path == "" {
 		return "/"
 	}
@@ -20,6 +22,52 @@ func (c *Config) GetNormalizedPath() string {
 	return path
 }
 
+func (c *Config) GetNormalizedPath() string {
+	return normalizePath(c.Path)
+}
+
+// pathEntry is a normalized PathEntry.
+type pathEntry struct {
+	path   string
+	prefix bool
+	tag    string
+	user   *protocol.MemoryUser
+}
+
+// getPathEntries returns the paths that the listener accepts, in the order they are matched:
+// exact paths before prefixes, and longer ones before shorter ones.
+func (c *Config) getPathEntries() []pathEntry {
+	var entries []pathEntry
+	if c.Path != "" || len(c.Paths) == 0 {
+		entries = append(entries, pathEntry{path: c.GetNormalizedPath()})
+	}
+	for _, p := range c.Paths {
+		entry := pathEntry{
+			path: p.Path,
+			tag:  p.Tag,
+		}
+		if p.Email != "" {
+			entry.user = &protocol.MemoryUser{
+				Email: p.Email,
+				Level: p.Level,
+			}
+		}
+		if strings.HasSuffix(entry.path, "*") {
+			entry.path = entry.path[:len(entry.path)-1]
+			entry.prefix = true
+		}
+		entry.path = normalizePath(entry.path)
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].prefix != entries[j].prefix {
+			return !entries[i].prefix
+		}
+		return len(entries[i].path) > len(entries[j].path)
+	})
+	return entries
+}
+
 func (c *Config) GetRequestHeader() http.Header {
 	header := http.Header{}
 	for _, h := range c.Header {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/v2fly/v2ray-core/v5/common/buf"
 	"github.com/v2fly/v2ray-core/v5/common/errors"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/common/serial"
 )
 
@@ -20,6 +21,9 @@ type connection struct {
 	conn       *websocket.Conn
 	reader     io.Reader
 	remoteAddr net.Addr
+	path       string
+	pathTag    string
+	pathUser   *protocol.MemoryUser
 
 	shouldWait        bool
 	delayedDialFinish context.Context
@@ -173,6 +177,21 @@ func (c *connection) RemoteAddr() net.Addr {
 	return c.remoteAddr
 }
 
+// Path implements internet.PathConnection.
+func (c *connection) Path() string {
+	return c.path
+}
+
+// PathTag implements internet.PathConnection.
+func (c *connection) PathTag() string {
+	return c.pathTag
+}
+
+// PathUser implements internet.PathConnection.
+func (c *connection) PathUser() *protocol.MemoryUser {
+	return c.pathUser
+}
+
 func (c *connection) SetDeadline(t time.Time) error {
 	if err := c.SetReadDeadline(t); err != nil {
 		return err
@@ -81,6 +81,19 @@ func dialWebsocket(ctx context.Context, dest net.Destination, streamSettings *in
 		return newRelayedConnection(conn), nil
 	}
 
+	if wsSettings.UseHttp2 {
+		dialer.NetDial = func(network, addr string) (net.Conn, error) {
+			session, err := getH2Session(ctx, dest, streamSettings)
+			if err != nil {
+				return nil, newError("failed to dial HTTP/2 connection").Base(err)
+			}
+			return newH2ClientConn(session), nil
+		}
+		// TLS is on the shared HTTP/2 connection, not on the streams.
+		dialer.TLSClientConfig = nil
+		uri = "ws://" + host + wsSettings.GetNormalizedPath()
+	}
+
 	if wsSettings.MaxEarlyData != 0 {
 		return newConnectionWithDelayedDial(&dialerWithEarlyData{
 			dialer:  dialer,
@@ -0,0 +1,94 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/v2fly/v2ray-core/v5/common"
+	"github.com/v2fly/v2ray-core/v5/common/log"
+)
+
+// newFallbackHandler creates the handler for the requests that are not WebSocket connections.
+// Without config, such requests get a bare 404. The returned handler may need to be closed with common.Close.
+func newFallbackHandler(config *Fallback) (http.Handler, error) {
+	if config == nil {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusNotFound)
+		}), nil
+	}
+
+	var handler http.Handler
+	switch {
+	case config.StaticDir != "":
+		handler = http.FileServer(http.Dir(config.StaticDir))
+	case config.ProxyUrl != "":
+		backend, err := url.Parse(config.ProxyUrl)
+		if err != nil {
+			return nil, newError("invalid fallback proxy url: ", config.ProxyUrl).Base(err)
+		}
+		if backend.Scheme != "http" && backend.Scheme != "https" {
+			return nil, newError("unsupported fallback proxy url: ", config.ProxyUrl)
+		}
+		proxy := httputil.NewSingleHostReverseProxy(backend)
+		proxy.ErrorHandler = func(writer http.ResponseWriter, request *http.Request, err error) {
+			newError("failed to proxy fallback request to ", config.ProxyUrl).Base(err).AtWarning().WriteToLog()
+			writer.WriteHeader(http.StatusBadGateway)
+		}
+		handler = proxy
+	default:
+		handler = &cannedResponse{config: config}
+	}
+
+	fallback := &fallbackHandler{handler: handler}
+	if config.AccessLog != "" {
+		creator, err := log.CreateFileLogWriter(config.AccessLog)
+		if err != nil {
+			return nil, newError("failed to open fallback access log: ", config.AccessLog).Base(err)
+		}
+		fallback.accessLogger = log.NewLogger(creator)
+	}
+	return fallback, nil
+}
+
+type fallbackHandler struct {
+	handler http.Handler
+	// accessLogger is nil if the requests are logged to the access log of the instance.
+	accessLogger log.Handler
+}
+
+func (h *fallbackHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	msg := &log.AccessMessage{
+		From:   request.RemoteAddr,
+		To:     request.Host + request.URL.RequestURI(),
+		Status: log.AccessAccepted,
+		Reason: "websocket fallback",
+	}
+	if h.accessLogger != nil {
+		h.accessLogger.Handle(msg)
+	} else {
+		log.Record(msg)
+	}
+	h.handler.ServeHTTP(writer, request)
+}
+
+// Close implements common.Closable.
+func (h *fallbackHandler) Close() error {
+	return common.Close(h.accessLogger)
+}
+
+type cannedResponse struct {
+	config *Fallback
+}
+
+func (r *cannedResponse) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	for _, h := range r.config.Header {
+		writer.Header().Add(h.Key, h.Value)
+	}
+	statusCode := int(r.config.StatusCode)
+	if statusCode == 0 {
+		statusCode = http.StatusNotFound
+	}
+	writer.WriteHeader(statusCode)
+	writer.Write(r.config.Body)
+}
@@ -0,0 +1,834 @@
+package websocket
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+
+	"github.com/v2fly/v2ray-core/v5/common/buf"
+	"github.com/v2fly/v2ray-core/v5/common/signal/done"
+)
+
+// This is a minimal HTTP/2 implementation for WebSocket over HTTP/2 with extended CONNECT (RFC 8441).
+// The golang.org/x/net/http2 this module builds with rejects the :protocol pseudo-header on both the
+// server and the transport, so only its Framer and hpack are used. It covers just what a WebSocket
+// stream needs: no priorities, no push and no trailers. It should be replaced by x/net once that can
+// be upgraded to a version supporting extended CONNECT.
+
+const (
+	// h2SettingEnableConnectProtocol is SETTINGS_ENABLE_CONNECT_PROTOCOL of RFC 8441.
+	h2SettingEnableConnectProtocol http2.SettingID = 0x8
+
+	// h2StreamWindow and h2ConnWindow are the receive windows of a stream and of a connection.
+	h2StreamWindow = 1 << 20
+	h2ConnWindow   = 1 << 24
+
+	// h2DefaultWindow and h2DefaultFrameSize are the initial values of the peer settings.
+	h2DefaultWindow    = 65535
+	h2DefaultFrameSize = 16384
+
+	// h2MaxHeaderListSize limits both the encoded header block and the decoded header fields of a request or response.
+	h2MaxHeaderListSize = 1 << 16
+
+	h2MaxStreams       = 1024
+	h2HandshakeTimeout = time.Second * 8
+)
+
+var errH2Closed = newError("HTTP/2 connection closed")
+
+// h2Session is an HTTP/2 connection.
+type h2Session struct {
+	conn     net.Conn
+	framer   *http2.Framer
+	decoder  *hpack.Decoder
+	isServer bool
+	// handler handles the streams opened by the peer. Only servers accept streams.
+	handler func(*h2Stream)
+
+	// writeAccess guards the framer for writing, and the encoder.
+	writeAccess sync.Mutex
+	encoder     *hpack.Encoder
+	encoderBuf  bytes.Buffer
+
+	access           sync.Mutex
+	streams          map[uint32]*h2Stream
+	nextStreamID     uint32
+	lastPeerStreamID uint32
+	goingAway        bool
+	unackedRecv      uint32
+	// sendWindow is the send window of the connection. The send windows of streams are also guarded by access.
+	sendWindow        int64
+	peerInitialWindow int64
+	peerMaxFrameSize  uint32
+	peerMaxStreams    uint32
+	extendedConnect   bool
+	// windowUpdated is closed and replaced when send windows grow.
+	windowUpdated chan struct{}
+
+	settingsReceived chan struct{}
+	settingsOnce     sync.Once
+	done             *done.Instance
+}
+
+func newH2Session(conn net.Conn, isServer bool, handler func(*h2Stream)) *h2Session {
+	s := &h2Session{
+		conn:              conn,
+		framer:            http2.NewFramer(conn, conn),
+		decoder:           hpack.NewDecoder(4096, nil),
+		isServer:          isServer,
+		handler:           handler,
+		streams:           make(map[uint32]*h2Stream),
+		nextStreamID:      1,
+		sendWindow:        h2DefaultWindow,
+		peerInitialWindow: h2DefaultWindow,
+		peerMaxFrameSize:  h2DefaultFrameSize,
+		peerMaxStreams:    math.MaxUint32,
+		windowUpdated:     make(chan struct{}),
+		settingsReceived:  make(chan struct{}),
+		done:              done.New(),
+	}
+	// We never advertise a larger SETTINGS_MAX_FRAME_SIZE, so larger frames are refused before their payload is read.
+	s.framer.SetMaxReadFrameSize(h2DefaultFrameSize)
+	s.decoder.SetMaxStringLength(h2MaxHeaderListSize)
+	s.encoder = hpack.NewEncoder(&s.encoderBuf)
+	return s
+}
+
+// handshake exchanges the connection preface. The peer settings arrive later in run.
+func (s *h2Session) handshake() error {
+	if s.isServer {
+		preface := make([]byte, len(http2.ClientPreface))
+		if _, err := io.ReadFull(s.conn, preface); err != nil {
+			return newError("failed to read HTTP/2 preface").Base(err)
+		}
+		if string(preface) != http2.ClientPreface {
+			return newError("invalid HTTP/2 preface")
+		}
+	} else if _, err := io.WriteString(s.conn, http2.ClientPreface); err != nil {
+		return newError("failed to write HTTP/2 preface").Base(err)
+	}
+
+	settings := []http2.Setting{
+		{ID: http2.SettingInitialWindowSize, Val: h2StreamWindow},
+		{ID: http2.SettingMaxHeaderListSize, Val: h2MaxHeaderListSize},
+	}
+	if s.isServer {
+		settings = append(settings,
+			http2.Setting{ID: http2.SettingMaxConcurrentStreams, Val: h2MaxStreams},
+			http2.Setting{ID: h2SettingEnableConnectProtocol, Val: 1})
+	} else {
+		settings = append(settings, http2.Setting{ID: http2.SettingEnablePush, Val: 0})
+	}
+	return s.write(func(framer *http2.Framer) error {
+		if err := framer.WriteSettings(settings...); err != nil {
+			return err
+		}
+		return framer.WriteWindowUpdate(0, h2ConnWindow-h2DefaultWindow)
+	})
+}
+
+func (s *h2Session) write(f func(*http2.Framer) error) error {
+	s.writeAccess.Lock()
+	defer s.writeAccess.Unlock()
+
+	if s.done.Done() {
+		return errH2Closed
+	}
+	return f(s.framer)
+}
+
+// run reads frames until the connection fails, and closes the session then.
+func (s *h2Session) run() {
+	err := s.readFrames()
+	if err != nil && err != io.EOF && !s.done.Done() {
+		newError("HTTP/2 connection ends").Base(err).AtDebug().WriteToLog()
+	}
+	s.close()
+}
+
+func (s *h2Session) readFrames() error {
+	for {
+		frame, err := s.framer.ReadFrame()
+		if err != nil {
+			if streamErr, ok := err.(http2.StreamError); ok {
+				s.resetStream(streamErr.StreamID, streamErr.Code)
+				continue
+			}
+			if err == http2.ErrFrameTooLarge {
+				s.access.Lock()
+				lastStreamID := s.lastPeerStreamID
+				s.access.Unlock()
+				s.write(func(framer *http2.Framer) error {
+					return framer.WriteGoAway(lastStreamID, http2.ErrCodeFrameSize, nil)
+				})
+			}
+			return err
+		}
+		switch f := frame.(type) {
+		case *http2.SettingsFrame:
+			err = s.handleSettings(f)
+		case *http2.HeadersFrame:
+			err = s.handleHeaders(f)
+		case *http2.DataFrame:
+			err = s.handleData(f)
+		case *http2.WindowUpdateFrame:
+			s.handleWindowUpdate(f)
+		case *http2.RSTStreamFrame:
+			if stream := s.getStream(f.StreamID); stream != nil {
+				stream.closeWithError(newError("stream reset by peer: ", f.ErrCode))
+			}
+		case *http2.PingFrame:
+			if !f.IsAck() {
+				err = s.write(func(framer *http2.Framer) error {
+					return framer.WritePing(true, f.Data)
+				})
+			}
+		case *http2.GoAwayFrame:
+			s.access.Lock()
+			s.goingAway = true
+			s.access.Unlock()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *h2Session) handleSettings(f *http2.SettingsFrame) error {
+	if f.IsAck() {
+		return nil
+	}
+
+	s.access.Lock()
+	err := f.ForeachSetting(func(setting http2.Setting) error {
+		switch setting.ID {
+		case http2.SettingInitialWindowSize:
+			delta := int64(setting.Val) - s.peerInitialWindow
+			s.peerInitialWindow = int64(setting.Val)
+			for _, stream := range s.streams {
+				stream.sendWindow += delta
+			}
+		case http2.SettingMaxFrameSize:
+			s.peerMaxFrameSize = setting.Val
+		case http2.SettingMaxConcurrentStreams:
+			s.peerMaxStreams = setting.Val
+		case h2SettingEnableConnectProtocol:
+			s.extendedConnect = setting.Val == 1
+		}
+		return nil
+	})
+	s.notifyWindowLocked()
+	s.access.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.settingsOnce.Do(func() {
+		close(s.settingsReceived)
+	})
+	return s.write(func(framer *http2.Framer) error {
+		return framer.WriteSettingsAck()
+	})
+}
+
+func (s *h2Session) handleHeaders(f *http2.HeadersFrame) error {
+	block := append([]byte(nil), f.HeaderBlockFragment()...)
+	for ended := f.HeadersEnded(); !ended; {
+		if len(block) > h2MaxHeaderListSize {
+			return newError("HTTP/2 header block too large")
+		}
+		frame, err := s.framer.ReadFrame()
+		if err != nil {
+			return err
+		}
+		// The framer ensures that CONTINUATION frames follow.
+		continuation := frame.(*http2.ContinuationFrame)
+		block = append(block, continuation.HeaderBlockFragment()...)
+		ended = continuation.HeadersEnded()
+	}
+	if len(block) > h2MaxHeaderListSize {
+		return newError("HTTP/2 header block too large")
+	}
+
+	// Fields referring to the dynamic table may expand, so the decoded size is limited too.
+	var fields []hpack.HeaderField
+	var size uint32
+	s.decoder.SetEmitFunc(func(field hpack.HeaderField) {
+		size += field.Size()
+		if size <= h2MaxHeaderListSize {
+			fields = append(fields, field)
+		}
+	})
+	_, err := s.decoder.Write(block)
+	if err == nil {
+		err = s.decoder.Close()
+	}
+	if err != nil {
+		return newError("failed to decode HTTP/2 headers").Base(err)
+	}
+	if size > h2MaxHeaderListSize {
+		return newError("HTTP/2 header list too large")
+	}
+
+	if stream := s.getStream(f.StreamID); stream != nil {
+		stream.handleHeaders(fields, f.StreamEnded())
+		return nil
+	}
+	if !s.isServer {
+		return nil
+	}
+
+	s.access.Lock()
+	if f.StreamID%2 == 0 || f.StreamID <= s.lastPeerStreamID {
+		s.access.Unlock()
+		return nil
+	}
+	s.lastPeerStreamID = f.StreamID
+	if len(s.streams) >= h2MaxStreams {
+		s.access.Unlock()
+		s.resetStream(f.StreamID, http2.ErrCodeRefusedStream)
+		return nil
+	}
+	stream := s.newStreamLocked(f.StreamID)
+	s.access.Unlock()
+
+	stream.handleHeaders(fields, f.StreamEnded())
+	go s.handler(stream)
+	return nil
+}
+
+func (s *h2Session) handleData(f *http2.DataFrame) error {
+	length := int(f.Header().Length)
+	data := f.Data()
+	stream := s.getStream(f.StreamID)
+	if stream == nil {
+		s.consume(length)
+		return nil
+	}
+	// Padding is consumed at once.
+	stream.consume(length - len(data))
+	stream.deliver(data, f.StreamEnded())
+	return nil
+}
+
+func (s *h2Session) handleWindowUpdate(f *http2.WindowUpdateFrame) {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	if f.StreamID == 0 {
+		s.sendWindow += int64(f.Increment)
+	} else if stream, found := s.streams[f.StreamID]; found {
+		stream.sendWindow += int64(f.Increment)
+	}
+	s.notifyWindowLocked()
+}
+
+func (s *h2Session) notifyWindowLocked() {
+	close(s.windowUpdated)
+	s.windowUpdated = make(chan struct{})
+}
+
+// consume returns the receive window of the connection after n bytes are consumed.
+func (s *h2Session) consume(n int) {
+	if n <= 0 {
+		return
+	}
+	s.access.Lock()
+	s.unackedRecv += uint32(n)
+	var increment uint32
+	if s.unackedRecv >= h2ConnWindow/2 {
+		increment = s.unackedRecv
+		s.unackedRecv = 0
+	}
+	s.access.Unlock()
+
+	if increment > 0 {
+		s.write(func(framer *http2.Framer) error {
+			return framer.WriteWindowUpdate(0, increment)
+		})
+	}
+}
+
+// reserveWindow waits until the stream can send data, and returns the size of data that it can send at most.
+func (s *h2Session) reserveWindow(stream *h2Stream, size int) (int, error) {
+	for {
+		s.access.Lock()
+		if stream.done.Done() {
+			s.access.Unlock()
+			return 0, io.ErrClosedPipe
+		}
+		n := int64(size)
+		if n > s.sendWindow {
+			n = s.sendWindow
+		}
+		if n > stream.sendWindow {
+			n = stream.sendWindow
+		}
+		if n > int64(s.peerMaxFrameSize) {
+			n = int64(s.peerMaxFrameSize)
+		}
+		if n > 0 {
+			s.sendWindow -= n
+			stream.sendWindow -= n
+			s.access.Unlock()
+			return int(n), nil
+		}
+		updated := s.windowUpdated
+		s.access.Unlock()
+
+		select {
+		case <-updated:
+		case <-stream.done.Wait():
+		case <-stream.writeDeadline.wait():
+			return 0, os.ErrDeadlineExceeded
+		}
+	}
+}
+
+// available returns whether new streams can be opened on the session.
+func (s *h2Session) available() bool {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	return !s.done.Done() && !s.goingAway && uint32(len(s.streams)) < s.peerMaxStreams
+}
+
+func (s *h2Session) supportsExtendedConnect() bool {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	return s.extendedConnect
+}
+
+func (s *h2Session) newStreamLocked(id uint32) *h2Stream {
+	stream := &h2Stream{
+		session:       s,
+		id:            id,
+		sendWindow:    s.peerInitialWindow,
+		readable:      make(chan struct{}, 1),
+		headerArrived: make(chan struct{}),
+		readDeadline:  makeH2Deadline(),
+		writeDeadline: makeH2Deadline(),
+		done:          done.New(),
+	}
+	s.streams[id] = stream
+	return stream
+}
+
+func (s *h2Session) getStream(id uint32) *h2Stream {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	return s.streams[id]
+}
+
+func (s *h2Session) removeStream(id uint32) {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	delete(s.streams, id)
+}
+
+func (s *h2Session) resetStream(id uint32, code http2.ErrCode) {
+	if stream := s.getStream(id); stream != nil {
+		stream.closeWithError(newError("stream reset: ", code))
+	}
+	s.write(func(framer *http2.Framer) error {
+		return framer.WriteRSTStream(id, code)
+	})
+}
+
+// openStream opens a stream with the request header.
+func (s *h2Session) openStream(fields []hpack.HeaderField) (*h2Stream, error) {
+	// Streams must be opened in the order of their IDs, so the ID is assigned when holding writeAccess.
+	s.writeAccess.Lock()
+	defer s.writeAccess.Unlock()
+
+	s.access.Lock()
+	if s.done.Done() || s.goingAway {
+		s.access.Unlock()
+		return nil, errH2Closed
+	}
+	id := s.nextStreamID
+	s.nextStreamID += 2
+	stream := s.newStreamLocked(id)
+	s.access.Unlock()
+
+	if err := s.writeHeadersLocked(id, fields, false); err != nil {
+		stream.closeWithError(err)
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (s *h2Session) writeHeaders(id uint32, fields []hpack.HeaderField, endStream bool) error {
+	s.writeAccess.Lock()
+	defer s.writeAccess.Unlock()
+
+	if s.done.Done() {
+		return errH2Closed
+	}
+	return s.writeHeadersLocked(id, fields, endStream)
+}
+
+func (s *h2Session) writeHeadersLocked(id uint32, fields []hpack.HeaderField, endStream bool) error {
+	s.encoderBuf.Reset()
+	for _, field := range fields {
+		if err := s.encoder.WriteField(field); err != nil {
+			return err
+		}
+	}
+	block := s.encoderBuf.Bytes()
+
+	for first := true; first || len(block) > 0; first = false {
+		fragment := block
+		if len(fragment) > h2DefaultFrameSize {
+			fragment = fragment[:h2DefaultFrameSize]
+		}
+		block = block[len(fragment):]
+		var err error
+		if first {
+			err = s.framer.WriteHeaders(http2.HeadersFrameParam{
+				StreamID:      id,
+				BlockFragment: fragment,
+				EndStream:     endStream,
+				EndHeaders:    len(block) == 0,
+			})
+		} else {
+			err = s.framer.WriteContinuation(id, len(block) == 0, fragment)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *h2Session) close() {
+	if s.done.Done() {
+		return
+	}
+	s.done.Close()
+	s.conn.Close()
+
+	s.access.Lock()
+	streams := make([]*h2Stream, 0, len(s.streams))
+	for _, stream := range s.streams {
+		streams = append(streams, stream)
+	}
+	s.notifyWindowLocked()
+	s.access.Unlock()
+
+	for _, stream := range streams {
+		stream.closeWithError(errH2Closed)
+	}
+}
+
+// h2Stream is a stream of h2Session. It implements net.Conn.
+type h2Stream struct {
+	session *h2Session
+	id      uint32
+	// sendWindow is guarded by the access of the session.
+	sendWindow int64
+
+	// header is the request header on servers, and the response header on clients.
+	header        []hpack.HeaderField
+	headerArrived chan struct{}
+	headerOnce    sync.Once
+
+	access      sync.Mutex
+	recv        buf.MultiBuffer
+	recvErr     error
+	unackedRecv uint32
+	// localEnded is set when END_STREAM is sent, and reset when RST_STREAM is sent or received.
+	localEnded bool
+	reset      bool
+	readable   chan struct{}
+
+	readDeadline  h2Deadline
+	writeDeadline h2Deadline
+	done          *done.Instance
+}
+
+func (st *h2Stream) handleHeaders(fields []hpack.HeaderField, ended bool) {
+	st.headerOnce.Do(func() {
+		st.header = fields
+		close(st.headerArrived)
+	})
+	if ended {
+		st.deliver(nil, true)
+	}
+}
+
+// headerValue returns the value of the field in the header.
+func (st *h2Stream) headerValue(name string) string {
+	for _, field := range st.header {
+		if field.Name == name {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// waitHeader waits until the header arrives.
+func (st *h2Stream) waitHeader() error {
+	select {
+	case <-st.headerArrived:
+		return nil
+	case <-st.done.Wait():
+		return newError("stream closed before response")
+	case <-st.readDeadline.wait():
+		return os.ErrDeadlineExceeded
+	}
+}
+
+func (st *h2Stream) deliver(data []byte, ended bool) {
+	st.access.Lock()
+	dropped := 0
+	switch {
+	case st.recvErr != nil:
+		dropped = len(data)
+	case int(st.recv.Len())+len(data) > h2StreamWindow:
+		st.access.Unlock()
+		st.session.consume(len(data))
+		st.session.resetStream(st.id, http2.ErrCodeFlowControl)
+		return
+	case len(data) > 0:
+		st.recv = buf.MergeBytes(st.recv, data)
+	}
+	if ended && st.recvErr == nil {
+		st.recvErr = io.EOF
+	}
+	st.access.Unlock()
+
+	st.consume(dropped)
+	st.notifyReadable()
+}
+
+func (st *h2Stream) notifyReadable() {
+	select {
+	case st.readable <- struct{}{}:
+	default:
+	}
+}
+
+// consume returns the receive windows after n bytes of the stream are consumed.
+func (st *h2Stream) consume(n int) {
+	if n <= 0 {
+		return
+	}
+	st.access.Lock()
+	st.unackedRecv += uint32(n)
+	var increment uint32
+	if st.unackedRecv >= h2StreamWindow/2 && st.recvErr == nil {
+		increment = st.unackedRecv
+		st.unackedRecv = 0
+	}
+	st.access.Unlock()
+
+	if increment > 0 {
+		st.session.write(func(framer *http2.Framer) error {
+			return framer.WriteWindowUpdate(st.id, increment)
+		})
+	}
+	st.session.consume(n)
+}
+
+func (st *h2Stream) Read(b []byte) (int, error) {
+	for {
+		st.access.Lock()
+		if !st.recv.IsEmpty() {
+			var n int
+			st.recv, n = buf.SplitBytes(st.recv, b)
+			st.access.Unlock()
+			st.consume(n)
+			return n, nil
+		}
+		err := st.recvErr
+		st.access.Unlock()
+		if err != nil {
+			return 0, err
+		}
+
+		select {
+		case <-st.readable:
+		case <-st.readDeadline.wait():
+			return 0, os.ErrDeadlineExceeded
+		}
+	}
+}
+
+func (st *h2Stream) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		n, err := st.session.reserveWindow(st, len(b))
+		if err != nil {
+			return written, err
+		}
+		data := b[:n]
+		if err := st.session.write(func(framer *http2.Framer) error {
+			return framer.WriteData(st.id, false, data)
+		}); err != nil {
+			return written, err
+		}
+		b = b[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// writeResponse sends the response header on servers.
+func (st *h2Stream) writeResponse(fields []hpack.HeaderField, endStream bool) error {
+	if endStream {
+		st.access.Lock()
+		st.localEnded = true
+		st.access.Unlock()
+	}
+	return st.session.writeHeaders(st.id, fields, endStream)
+}
+
+// CloseWrite sends END_STREAM.
+func (st *h2Stream) CloseWrite() error {
+	st.access.Lock()
+	if st.localEnded || st.reset {
+		st.access.Unlock()
+		return nil
+	}
+	st.localEnded = true
+	st.access.Unlock()
+
+	return st.session.write(func(framer *http2.Framer) error {
+		return framer.WriteData(st.id, true, nil)
+	})
+}
+
+// Close ends the stream, and resets it if the peer has not ended it.
+func (st *h2Stream) Close() error {
+	if st.done.Done() {
+		return nil
+	}
+	st.CloseWrite()
+
+	st.access.Lock()
+	remoteEnded := st.recvErr == io.EOF
+	if st.recvErr == nil {
+		st.recvErr = io.ErrClosedPipe
+	}
+	unread := int(st.recv.Len())
+	st.recv = buf.ReleaseMulti(st.recv)
+	st.access.Unlock()
+
+	st.session.consume(unread)
+	st.closeWithError(io.ErrClosedPipe)
+	if !remoteEnded {
+		st.session.write(func(framer *http2.Framer) error {
+			return framer.WriteRSTStream(st.id, http2.ErrCodeCancel)
+		})
+	}
+	return nil
+}
+
+// closeWithError closes the stream without notifying the peer.
+func (st *h2Stream) closeWithError(err error) {
+	st.access.Lock()
+	if st.recvErr == nil {
+		st.recvErr = err
+	}
+	st.reset = true
+	st.access.Unlock()
+
+	st.done.Close()
+	st.notifyReadable()
+	st.session.removeStream(st.id)
+
+	st.session.access.Lock()
+	st.session.notifyWindowLocked()
+	st.session.access.Unlock()
+}
+
+func (st *h2Stream) LocalAddr() net.Addr {
+	return st.session.conn.LocalAddr()
+}
+
+func (st *h2Stream) RemoteAddr() net.Addr {
+	return st.session.conn.RemoteAddr()
+}
+
+func (st *h2Stream) SetDeadline(t time.Time) error {
+	st.readDeadline.set(t)
+	st.writeDeadline.set(t)
+	return nil
+}
+
+func (st *h2Stream) SetReadDeadline(t time.Time) error {
+	st.readDeadline.set(t)
+	return nil
+}
+
+func (st *h2Stream) SetWriteDeadline(t time.Time) error {
+	st.writeDeadline.set(t)
+	return nil
+}
+
+// h2Deadline is a deadline of stream operations, like the deadlines of net.Pipe.
+type h2Deadline struct {
+	access sync.Mutex
+	timer  *time.Timer
+	// expired is closed when the deadline expires.
+	expired chan struct{}
+}
+
+func makeH2Deadline() h2Deadline {
+	return h2Deadline{expired: make(chan struct{})}
+}
+
+func (d *h2Deadline) set(t time.Time) {
+	d.access.Lock()
+	defer d.access.Unlock()
+
+	if d.timer != nil && !d.timer.Stop() {
+		<-d.expired // The timer has fired, wait for it to close expired.
+	}
+	d.timer = nil
+
+	expired := false
+	select {
+	case <-d.expired:
+		expired = true
+	default:
+	}
+
+	if t.IsZero() {
+		if expired {
+			d.expired = make(chan struct{})
+		}
+		return
+	}
+	if duration := time.Until(t); duration > 0 {
+		if expired {
+			d.expired = make(chan struct{})
+		}
+		ch := d.expired
+		d.timer = time.AfterFunc(duration, func() {
+			close(ch)
+		})
+		return
+	}
+	if !expired {
+		close(d.expired)
+	}
+}
+
+func (d *h2Deadline) wait() <-chan struct{} {
+	d.access.Lock()
+	defer d.access.Unlock()
+
+	return d.expired
+}
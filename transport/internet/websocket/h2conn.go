@@ -0,0 +1,445 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" // nolint: gosec
+	gotls "crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2/hpack"
+
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/transport/internet"
+	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
+)
+
+// The WebSocket handshake of gorilla/websocket is in HTTP/1.1, so it is translated to extended CONNECT on HTTP/2 streams.
+// On clients, h2ClientConn sends the request written by gorilla/websocket as a CONNECT request, and replies its response.
+// On servers, the CONNECT request is served by requestHandler as a WebSocket upgrade request, with h2ResponseWriter.
+
+var (
+	handshakeEnd    = []byte("\r\n\r\n")
+	websocketGUID   = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	h2SkippedHeader = map[string]bool{
+		"Host":                  true,
+		"Connection":            true,
+		"Upgrade":               true,
+		"Sec-Websocket-Key":     true,
+		"Sec-Websocket-Version": true,
+		"Sec-Websocket-Accept":  true,
+		"Keep-Alive":            true,
+		"Transfer-Encoding":     true,
+		"Proxy-Connection":      true,
+	}
+)
+
+func computeAcceptKey(challengeKey string) string {
+	h := sha1.New() // nolint: gosec
+	h.Write([]byte(challengeKey + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// appendHeaderFields appends the fields of header in lower case, skipping those of HTTP/1.1 only.
+func appendHeaderFields(fields []hpack.HeaderField, header http.Header) []hpack.HeaderField {
+	for name, values := range header {
+		if h2SkippedHeader[name] {
+			continue
+		}
+		for _, value := range values {
+			fields = append(fields, hpack.HeaderField{Name: strings.ToLower(name), Value: value})
+		}
+	}
+	return fields
+}
+
+type h2DialerConf struct {
+	net.Destination
+	*internet.SocketConfig
+	*tls.Config
+}
+
+var (
+	globalH2Sessions      map[h2DialerConf]*h2Session
+	globalH2SessionAccess sync.Mutex
+)
+
+// getH2Session returns an HTTP/2 connection to dest that can open streams, dialing a new one if there is none.
+func getH2Session(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig) (*h2Session, error) {
+	tlsSettings := tls.ConfigFromStreamSettings(streamSettings)
+	if tlsSettings == nil {
+		return nil, newError("WebSocket over HTTP/2 requires TLS")
+	}
+	conf := h2DialerConf{dest, streamSettings.SocketSettings, tlsSettings}
+
+	globalH2SessionAccess.Lock()
+	defer globalH2SessionAccess.Unlock()
+
+	if globalH2Sessions == nil {
+		globalH2Sessions = make(map[h2DialerConf]*h2Session)
+	}
+	if session, found := globalH2Sessions[conf]; found && session.available() {
+		return session, nil
+	}
+
+	session, err := dialH2Session(ctx, dest, streamSettings, tlsSettings)
+	if err != nil {
+		return nil, err
+	}
+	globalH2Sessions[conf] = session
+	go func() {
+		<-session.done.Wait()
+		globalH2SessionAccess.Lock()
+		defer globalH2SessionAccess.Unlock()
+		if globalH2Sessions[conf] == session {
+			delete(globalH2Sessions, conf)
+		}
+	}()
+	return session, nil
+}
+
+func dialH2Session(ctx context.Context, dest net.Destination, streamSettings *internet.MemoryStreamConfig, tlsSettings *tls.Config) (*h2Session, error) {
+	// The connection is shared by WebSocket connections, so it must not be canceled with the first one.
+	detachedContext := core.ToBackgroundDetachedContext(ctx)
+	conn, err := internet.DialSystem(detachedContext, dest, streamSettings.SocketSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConn := gotls.Client(conn, tlsSettings.GetTLSConfig(tls.WithDestination(dest), tls.WithNextProto("h2")))
+	tlsConn.SetDeadline(time.Now().Add(h2HandshakeTimeout))
+	if err := tlsConn.Handshake(); err != nil {
+		tlsConn.Close()
+		return nil, newError("failed to complete TLS handshake").Base(err)
+	}
+	if protocol := tlsConn.ConnectionState().NegotiatedProtocol; protocol != "h2" {
+		tlsConn.Close()
+		return nil, newError("server negotiated ", protocol, " instead of HTTP/2")
+	}
+
+	session := newH2Session(tlsConn, false, nil)
+	if err := session.handshake(); err != nil {
+		tlsConn.Close()
+		return nil, err
+	}
+	go session.run()
+	select {
+	case <-session.settingsReceived:
+	case <-session.done.Wait():
+		return nil, newError("HTTP/2 connection closed before settings")
+	}
+	tlsConn.SetDeadline(time.Time{})
+
+	if !session.supportsExtendedConnect() {
+		session.close()
+		return nil, newError("server does not support WebSocket over HTTP/2")
+	}
+	return session, nil
+}
+
+// h2ClientConn is the connection for gorilla/websocket to dial WebSocket over a stream of session.
+type h2ClientConn struct {
+	session *h2Session
+	stream  *h2Stream
+
+	request  bytes.Buffer
+	response bytes.Reader
+
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func newH2ClientConn(session *h2Session) *h2ClientConn {
+	return &h2ClientConn{
+		session: session,
+	}
+}
+
+// connect sends the handshake request as extended CONNECT, and prepares the handshake response from its response.
+func (c *h2ClientConn) connect() error {
+	request, err := http.ReadRequest(bufio.NewReader(&c.request))
+	if err != nil {
+		return newError("failed to parse WebSocket handshake").Base(err)
+	}
+
+	fields := []hpack.HeaderField{
+		{Name: ":method", Value: http.MethodConnect},
+		{Name: ":protocol", Value: "websocket"},
+		{Name: ":scheme", Value: "https"},
+		{Name: ":path", Value: request.URL.RequestURI()},
+		{Name: ":authority", Value: request.Host},
+		{Name: "sec-websocket-version", Value: "13"},
+	}
+	stream, err := c.session.openStream(appendHeaderFields(fields, request.Header))
+	if err != nil {
+		return newError("failed to open HTTP/2 stream").Base(err)
+	}
+	stream.SetReadDeadline(c.readDeadline)
+	stream.SetWriteDeadline(c.writeDeadline)
+	if err := stream.waitHeader(); err != nil {
+		stream.Close()
+		return newError("failed to read response").Base(err)
+	}
+
+	status, _ := strconv.Atoi(stream.headerValue(":status"))
+	var response string
+	if status >= 200 && status < 300 {
+		c.stream = stream
+		response = "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " +
+			computeAcceptKey(request.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n"
+	} else {
+		stream.Close()
+		response = fmt.Sprintf("HTTP/1.1 %d %s\r\nContent-Length: 0\r\n\r\n", status, http.StatusText(status))
+	}
+	c.response.Reset([]byte(response))
+	return nil
+}
+
+func (c *h2ClientConn) Read(b []byte) (int, error) {
+	if c.response.Len() > 0 {
+		return c.response.Read(b)
+	}
+	if c.stream == nil {
+		return 0, io.EOF
+	}
+	return c.stream.Read(b)
+}
+
+func (c *h2ClientConn) Write(b []byte) (int, error) {
+	if c.stream != nil {
+		return c.stream.Write(b)
+	}
+	c.request.Write(b)
+	if !bytes.Contains(c.request.Bytes(), handshakeEnd) {
+		return len(b), nil
+	}
+	if err := c.connect(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *h2ClientConn) Close() error {
+	if c.stream != nil {
+		return c.stream.Close()
+	}
+	return nil
+}
+
+func (c *h2ClientConn) LocalAddr() net.Addr {
+	return c.session.conn.LocalAddr()
+}
+
+func (c *h2ClientConn) RemoteAddr() net.Addr {
+	return c.session.conn.RemoteAddr()
+}
+
+func (c *h2ClientConn) SetDeadline(t time.Time) error {
+	c.SetReadDeadline(t)
+	return c.SetWriteDeadline(t)
+}
+
+func (c *h2ClientConn) SetReadDeadline(t time.Time) error {
+	c.readDeadline = t
+	if c.stream != nil {
+		return c.stream.SetReadDeadline(t)
+	}
+	return nil
+}
+
+func (c *h2ClientConn) SetWriteDeadline(t time.Time) error {
+	c.writeDeadline = t
+	if c.stream != nil {
+		return c.stream.SetWriteDeadline(t)
+	}
+	return nil
+}
+
+// serveH2 serves WebSocket over HTTP/2 on conn until it is closed.
+func (h *requestHandler) serveH2(conn net.Conn) {
+	session := newH2Session(conn, true, h.serveH2Stream)
+	// The read header timeout of the HTTP server does not apply here, so the handshake has its own deadline.
+	conn.SetDeadline(time.Now().Add(h2HandshakeTimeout))
+	if err := session.handshake(); err != nil {
+		newError("failed to serve HTTP/2 for WebSocket").Base(err).AtInfo().WriteToLog()
+		conn.Close()
+		return
+	}
+	go func() {
+		select {
+		case <-session.settingsReceived:
+			conn.SetDeadline(time.Time{})
+		case <-session.done.Wait():
+		}
+	}()
+	session.run()
+}
+
+func (h *requestHandler) serveH2Stream(stream *h2Stream) {
+	request, err := newH2Request(stream)
+	if err != nil {
+		newError("invalid HTTP/2 request").Base(err).AtInfo().WriteToLog()
+		stream.writeResponse([]hpack.HeaderField{{Name: ":status", Value: "400"}}, true)
+		stream.Close()
+		return
+	}
+
+	writer := &h2ResponseWriter{
+		stream: stream,
+		header: http.Header{},
+	}
+	h.ServeHTTP(writer, request)
+	if writer.hijacked {
+		return
+	}
+	if !writer.wroteHeader {
+		writer.WriteHeader(http.StatusOK)
+	}
+	stream.Close()
+}
+
+// newH2Request creates the request of a stream. A WebSocket CONNECT request is turned into the upgrade request of HTTP/1.1.
+func newH2Request(stream *h2Stream) (*http.Request, error) {
+	var method, path, authority, protocol string
+	header := http.Header{}
+	for _, field := range stream.header {
+		switch field.Name {
+		case ":method":
+			method = field.Value
+		case ":path":
+			path = field.Value
+		case ":authority":
+			authority = field.Value
+		case ":protocol":
+			protocol = field.Value
+		default:
+			if !strings.HasPrefix(field.Name, ":") {
+				header.Add(http.CanonicalHeaderKey(field.Name), field.Value)
+			}
+		}
+	}
+
+	body := io.ReadCloser(io.NopCloser(stream))
+	contentLength := int64(-1)
+	if method == http.MethodConnect {
+		if protocol != "websocket" {
+			return nil, newError("unsupported CONNECT protocol: ", protocol)
+		}
+		var key [16]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return nil, err
+		}
+		method = http.MethodGet
+		header.Set("Connection", "Upgrade")
+		header.Set("Upgrade", "websocket")
+		header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString(key[:]))
+		body = http.NoBody
+		contentLength = 0
+	} else if value := header.Get("Content-Length"); value != "" {
+		contentLength, _ = strconv.ParseInt(value, 10, 64)
+	}
+
+	requestURL, err := url.ParseRequestURI(path)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Request{
+		Method:        method,
+		URL:           requestURL,
+		Proto:         "HTTP/2.0",
+		ProtoMajor:    2,
+		Header:        header,
+		Body:          body,
+		ContentLength: contentLength,
+		Host:          authority,
+		RemoteAddr:    stream.RemoteAddr().String(),
+		RequestURI:    path,
+	}, nil
+}
+
+// h2ResponseWriter is the http.ResponseWriter of a request on a stream.
+type h2ResponseWriter struct {
+	stream      *h2Stream
+	header      http.Header
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (w *h2ResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *h2ResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	fields := []hpack.HeaderField{{Name: ":status", Value: strconv.Itoa(statusCode)}}
+	w.stream.writeResponse(appendHeaderFields(fields, w.header), false)
+}
+
+func (w *h2ResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.stream.Write(b)
+}
+
+// Flush implements http.Flusher. Data is sent as it is written.
+func (w *h2ResponseWriter) Flush() {}
+
+// Hijack implements http.Hijacker for gorilla/websocket to take the stream.
+func (w *h2ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	conn := &h2HijackedConn{h2Stream: w.stream}
+	return conn, bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)), nil
+}
+
+// h2HijackedConn is a stream hijacked by gorilla/websocket, which sends the handshake response of HTTP/1.1 as the response of the stream.
+type h2HijackedConn struct {
+	*h2Stream
+	response []byte
+	upgraded bool
+}
+
+func (c *h2HijackedConn) Write(b []byte) (int, error) {
+	if c.upgraded {
+		return c.h2Stream.Write(b)
+	}
+	c.response = append(c.response, b...)
+	end := bytes.Index(c.response, handshakeEnd)
+	if end < 0 {
+		return len(b), nil
+	}
+	response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(c.response[:end+len(handshakeEnd)])), nil)
+	if err != nil {
+		return 0, newError("failed to parse WebSocket handshake response").Base(err)
+	}
+	status := response.StatusCode
+	if status == http.StatusSwitchingProtocols {
+		status = http.StatusOK
+	}
+	fields := []hpack.HeaderField{{Name: ":status", Value: strconv.Itoa(status)}}
+	if err := c.writeResponse(appendHeaderFields(fields, response.Header), false); err != nil {
+		return 0, err
+	}
+	c.upgraded = true
+	if rest := c.response[end+len(handshakeEnd):]; len(rest) > 0 {
+		if _, err := c.h2Stream.Write(rest); err != nil {
+			return 0, err
+		}
+	}
+	c.response = nil
+	return len(b), nil
+}
@@ -22,10 +22,11 @@ import (
 )
 
 type requestHandler struct {
-	path                string
+	paths               []pathEntry
 	ln                  *Listener
 	earlyDataEnabled    bool
 	earlyDataHeaderName string
+	fallback            http.Handler
 }
 
 var upgrader = &websocket.Upgrader{
@@ -37,28 +38,49 @@ var upgrader = &websocket.Upgrader{
 	},
 }
 
+// matchPath returns the path entry that the request matches, the matched path, and the early data in the path if any.
+func (h *requestHandler) matchPath(request *http.Request) (*pathEntry, string, string, bool) {
+	earlyDataInPath := h.earlyDataEnabled && h.earlyDataHeaderName == ""
+	for i := range h.paths {
+		entry := &h.paths[i]
+		switch {
+		case entry.prefix:
+			if strings.HasPrefix(request.URL.Path, entry.path) {
+				return entry, request.URL.Path, "", true
+			}
+		case earlyDataInPath:
+			if strings.HasPrefix(request.URL.RequestURI(), entry.path) {
+				return entry, entry.path, request.URL.RequestURI()[len(entry.path):], true
+			}
+		default:
+			if request.URL.Path == entry.path {
+				return entry, entry.path, "", true
+			}
+		}
+	}
+	return nil, "", "", false
+}
+
 func (h *requestHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if v2tls.ServeACMEChallenge(writer, request) {
+		return
+	}
+	entry, path, earlyDataStr, found := h.matchPath(request)
+	if !found {
+		h.fallback.ServeHTTP(writer, request)
+		return
+	}
 	var earlyData io.Reader
-	if !h.earlyDataEnabled { // nolint: gocritic
-		if request.URL.Path != h.path {
-			writer.WriteHeader(http.StatusNotFound)
-			return
+	if h.earlyDataEnabled {
+		if h.earlyDataHeaderName != "" {
+			earlyDataStr = request.Header.Get(h.earlyDataHeaderName)
 		}
-	} else if h.earlyDataHeaderName != "" {
-		if request.URL.Path != h.path {
-			writer.WriteHeader(http.StatusNotFound)
-			return
-		}
-		earlyDataStr := request.Header.Get(h.earlyDataHeaderName)
 		earlyData = base64.NewDecoder(base64.RawURLEncoding, bytes.NewReader([]byte(earlyDataStr)))
-	} else {
-		if strings.HasPrefix(request.URL.RequestURI(), h.path) {
-			earlyDataStr := request.URL.RequestURI()[len(h.path):]
-			earlyData = base64.NewDecoder(base64.RawURLEncoding, bytes.NewReader([]byte(earlyDataStr)))
-		} else {
-			writer.WriteHeader(http.StatusNotFound)
-			return
-		}
+	}
+
+	if !websocket.IsWebSocketUpgrade(request) {
+		h.fallback.ServeHTTP(writer, request)
+		return
 	}
 
 	conn, err := upgrader.Upgrade(writer, request, nil)
@@ -75,11 +97,16 @@ func (h *requestHandler) ServeHTTP(writer http.ResponseWriter, request *http.Req
 			Port: int(0),
 		}
 	}
+	var c *connection
 	if earlyData == nil {
-		h.ln.addConn(newConnection(conn, remoteAddr))
+		c = newConnection(conn, remoteAddr)
 	} else {
-		h.ln.addConn(newConnectionWithEarlyData(conn, remoteAddr, earlyData))
+		c = newConnectionWithEarlyData(conn, remoteAddr, earlyData)
 	}
+	c.path = path
+	c.pathTag = entry.tag
+	c.pathUser = entry.user
+	h.ln.addConn(c)
 }
 
 type Listener struct {
@@ -89,6 +116,7 @@ type Listener struct {
 	config   *Config
 	addConn  internet.ConnHandler
 	locker   *internet.FileLocker // for unix domain socket
+	fallback http.Handler
 }
 
 func ListenWS(ctx context.Context, address net.Address, port net.Port, streamSettings *internet.MemoryStreamConfig, addConn internet.ConnHandler) (internet.Listener, error) {
@@ -103,8 +131,12 @@ func ListenWS(ctx context.Context, address net.Address, port net.Port, streamSet
 		}
 		streamSettings.SocketSettings.AcceptProxyProtocol = l.config.AcceptProxyProtocol
 	}
+	fallback, err := newFallbackHandler(wsSettings.Fallback)
+	if err != nil {
+		return nil, err
+	}
+	l.fallback = fallback
 	var listener net.Listener
-	var err error
 	if port == net.Port(0) { // unix
 		listener, err = internet.ListenSystem(ctx, &net.UnixAddr{
 			Name: address.Domain(),
@@ -147,16 +179,26 @@ func ListenWS(ctx context.Context, address net.Address, port net.Port, streamSet
 		earlyDataHeaderName = wsSettings.EarlyDataHeaderName
 	}
 
+	handler := &requestHandler{
+		paths:               wsSettings.getPathEntries(),
+		ln:                  l,
+		earlyDataEnabled:    useEarlyData,
+		earlyDataHeaderName: earlyDataHeaderName,
+		fallback:            fallback,
+	}
 	l.server = http.Server{
-		Handler: &requestHandler{
-			path:                wsSettings.GetNormalizedPath(),
-			ln:                  l,
-			earlyDataEnabled:    useEarlyData,
-			earlyDataHeaderName: earlyDataHeaderName,
-		},
+		Handler:           handler,
 		ReadHeaderTimeout: time.Second * 4,
 		MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
 	}
+	if wsSettings.UseHttp2 {
+		// HTTP/2 negotiated by TLS is served by serveH2 instead of net/http, which does not support extended CONNECT.
+		l.server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){
+			"h2": func(_ *http.Server, conn *tls.Conn, _ http.Handler) {
+				handler.serveH2(conn)
+			},
+		}
+	}
 
 	go func() {
 		if err := l.server.Serve(l.listener); err != nil {
@@ -177,6 +219,7 @@ func (ln *Listener) Close() error {
 	if ln.locker != nil {
 		ln.locker.Release()
 	}
+	common.Close(ln.fallback)
 	return ln.listener.Close()
 }
 
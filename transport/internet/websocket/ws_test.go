@@ -1,14 +1,29 @@
 package websocket_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	gotls "crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/net/http2"
+
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/net"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/common/protocol/tls/cert"
+	"github.com/v2fly/v2ray-core/v5/testing/servers/acme"
 	"github.com/v2fly/v2ray-core/v5/transport/internet"
 	"github.com/v2fly/v2ray-core/v5/transport/internet/tls"
 	. "github.com/v2fly/v2ray-core/v5/transport/internet/websocket"
@@ -146,3 +161,435 @@ func Test_listenWSAndDial_TLS(t *testing.T) {
 		t.Error("end: ", end, " start: ", start)
 	}
 }
+
+func TestListenWSFallback(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte("backend " + request.URL.Path))
+	}))
+	defer backend.Close()
+
+	// Connections are not reused, as the server of a closed listener still serves them.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	get := func(url string) (int, string) {
+		resp, err := client.Get(url)
+		common.Must(err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		common.Must(err)
+		return resp.StatusCode, string(body)
+	}
+
+	testCases := []struct {
+		fallback *Fallback
+		code     int
+		body     string
+	}{
+		{
+			code: http.StatusNotFound,
+		},
+		{
+			fallback: &Fallback{
+				StatusCode: http.StatusForbidden,
+				Body:       []byte("forbidden"),
+			},
+			code: http.StatusForbidden,
+			body: "forbidden",
+		},
+		{
+			fallback: &Fallback{
+				ProxyUrl: backend.URL,
+			},
+			code: http.StatusOK,
+			body: "backend /ws",
+		},
+	}
+	for _, testCase := range testCases {
+		listen, err := ListenWS(context.Background(), net.LocalHostIP, 13149, &internet.MemoryStreamConfig{
+			ProtocolName: "websocket",
+			ProtocolSettings: &Config{
+				Path:     "ws",
+				Fallback: testCase.fallback,
+			},
+		}, func(conn internet.Connection) {
+			conn.Close()
+		})
+		common.Must(err)
+
+		// Neither the request to another path nor the non-upgrade request to the path is WebSocket.
+		for _, path := range []string{"/other", "/ws"} {
+			code, body := get("http://127.0.0.1:13149" + path)
+			if code != testCase.code {
+				t.Error("status code: ", code)
+			}
+			if testCase.fallback != nil && testCase.fallback.ProxyUrl != "" {
+				if body != "backend "+path {
+					t.Error("body: ", body)
+				}
+			} else if body != testCase.body {
+				t.Error("body: ", body)
+			}
+		}
+
+		common.Must(listen.Close())
+	}
+}
+
+func TestListenWSFallbackAccessLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fallback.log")
+	listen, err := ListenWS(context.Background(), net.LocalHostIP, 13156, &internet.MemoryStreamConfig{
+		ProtocolName: "websocket",
+		ProtocolSettings: &Config{
+			Path: "ws",
+			Fallback: &Fallback{
+				AccessLog: path,
+			},
+		},
+	}, func(conn internet.Connection) {
+		conn.Close()
+	})
+	common.Must(err)
+	defer listen.Close()
+
+	resp, err := http.Get("http://127.0.0.1:13156/other?q=1")
+	common.Must(err)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Error("status code: ", resp.StatusCode)
+	}
+
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		b, err := os.ReadFile(path)
+		common.Must(err)
+		if strings.Contains(string(b), "127.0.0.1:13156/other?q=1") && strings.Contains(string(b), "websocket fallback") {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("fallback request is not logged: ", string(b))
+		}
+	}
+}
+
+func TestListenWSPaths(t *testing.T) {
+	conns := make(chan internet.Connection, 1)
+	listen, err := ListenWS(context.Background(), net.LocalHostIP, 13150, &internet.MemoryStreamConfig{
+		ProtocolName: "websocket",
+		ProtocolSettings: &Config{
+			Paths: []*PathEntry{
+				{Path: "/alice", Tag: "alice", Email: "alice@v2fly.org", Level: 1},
+				{Path: "bob", Tag: "bob"},
+				{Path: "/api/*", Tag: "api"},
+				{Path: "/api/v2*", Tag: "api2"},
+			},
+		},
+	}, func(conn internet.Connection) {
+		conns <- conn
+	})
+	common.Must(err)
+	defer listen.Close()
+
+	testCases := []struct {
+		path string
+		tag  string
+		user *protocol.MemoryUser
+	}{
+		{path: "/alice", tag: "alice", user: &protocol.MemoryUser{Email: "alice@v2fly.org", Level: 1}},
+		{path: "/bob", tag: "bob"},
+		{path: "/api/v1/stream", tag: "api"},
+		{path: "/api/v2/stream", tag: "api2"},
+	}
+	for _, testCase := range testCases {
+		conn, err := Dial(context.Background(), net.TCPDestination(net.LocalHostIP, 13150), &internet.MemoryStreamConfig{
+			ProtocolName:     "websocket",
+			ProtocolSettings: &Config{Path: testCase.path},
+		})
+		common.Must(err)
+		common.Must2(conn.Write([]byte("test")))
+
+		pathConn := (<-conns).(internet.PathConnection)
+		if pathConn.Path() != testCase.path {
+			t.Error("path: ", pathConn.Path())
+		}
+		if pathConn.PathTag() != testCase.tag {
+			t.Error("tag: ", pathConn.PathTag())
+		}
+		if r := cmp.Diff(pathConn.PathUser(), testCase.user); r != "" {
+			t.Error("user: ", r)
+		}
+		pathConn.(internet.Connection).Close()
+		conn.Close()
+	}
+
+	// Neither the root path nor an unknown path is accepted when paths are set.
+	for _, path := range []string{"/", "/carol"} {
+		conn, err := Dial(context.Background(), net.TCPDestination(net.LocalHostIP, 13150), &internet.MemoryStreamConfig{
+			ProtocolName:     "websocket",
+			ProtocolSettings: &Config{Path: path},
+		})
+		if err == nil {
+			conn.Close()
+			t.Error("connected to unknown path ", path)
+		}
+	}
+}
+
+func TestListenWSAndDialHTTP2(t *testing.T) {
+	streamSettings := &internet.MemoryStreamConfig{
+		ProtocolName: "websocket",
+		ProtocolSettings: &Config{
+			Path:     "ws",
+			UseHttp2: true,
+		},
+		SecurityType: "tls",
+		SecuritySettings: &tls.Config{
+			AllowInsecure: true,
+			Certificate:   []*tls.Certificate{tls.ParseCertificate(cert.MustGenerate(nil, cert.CommonName("localhost")))},
+		},
+	}
+	listen, err := ListenWS(context.Background(), net.LocalHostIP, 13151, streamSettings, func(conn internet.Connection) {
+		go func() {
+			defer conn.Close()
+			io.Copy(conn, conn)
+		}()
+	})
+	common.Must(err)
+	defer listen.Close()
+
+	dest := net.TCPDestination(net.DomainAddress("localhost"), 13151)
+	payload := make([]byte, 3*1024*1024)
+	common.Must2(rand.Read(payload))
+
+	var conns []internet.Connection
+	for i := 0; i < 3; i++ {
+		conn, err := Dial(context.Background(), dest, streamSettings)
+		common.Must(err)
+		conns = append(conns, conn)
+	}
+	for _, conn := range conns[1:] {
+		if conn.LocalAddr().String() != conns[0].LocalAddr().String() {
+			t.Error("WebSocket connections do not share the HTTP/2 connection")
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func(conn internet.Connection) {
+			defer wg.Done()
+			go conn.Write(payload)
+			received := make([]byte, len(payload))
+			if _, err := io.ReadFull(conn, received); err != nil {
+				t.Error(err)
+				return
+			}
+			if !bytes.Equal(received, payload) {
+				t.Error("payload mismatch")
+			}
+		}(conn)
+	}
+	wg.Wait()
+	for _, conn := range conns {
+		common.Must(conn.Close())
+	}
+
+	// HTTP/1.1 WebSocket and plain HTTP/2 requests are still served.
+	http1Settings := *streamSettings
+	http1Settings.ProtocolSettings = &Config{Path: "ws"}
+	conn, err := Dial(context.Background(), dest, &http1Settings)
+	common.Must(err)
+	common.Must2(conn.Write([]byte("test")))
+	var b [4]byte
+	common.Must2(io.ReadFull(conn, b[:]))
+	common.Must(conn.Close())
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &gotls.Config{InsecureSkipVerify: true}, // nolint: gosec
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get("https://localhost:13151/other")
+	common.Must(err)
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 || resp.StatusCode != http.StatusNotFound {
+		t.Error("response: ", resp.Proto, " ", resp.Status)
+	}
+}
+
+func TestListenWSHTTP2HeaderLimit(t *testing.T) {
+	streamSettings := &internet.MemoryStreamConfig{
+		ProtocolName: "websocket",
+		ProtocolSettings: &Config{
+			Path:     "ws",
+			UseHttp2: true,
+		},
+		SecurityType: "tls",
+		SecuritySettings: &tls.Config{
+			Certificate: []*tls.Certificate{tls.ParseCertificate(cert.MustGenerate(nil, cert.CommonName("localhost")))},
+		},
+	}
+	listen, err := ListenWS(context.Background(), net.LocalHostIP, 13154, streamSettings, func(conn internet.Connection) {
+		conn.Close()
+	})
+	common.Must(err)
+	defer listen.Close()
+
+	conn, err := gotls.Dial("tcp", "127.0.0.1:13154", &gotls.Config{
+		InsecureSkipVerify: true, // nolint: gosec
+		NextProtos:         []string{"h2"},
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	// A header block that never ends.
+	go func() {
+		framer := http2.NewFramer(conn, conn)
+		if _, err := io.WriteString(conn, http2.ClientPreface); err != nil {
+			return
+		}
+		if err := framer.WriteSettings(); err != nil {
+			return
+		}
+		fragment := make([]byte, 16384)
+		if err := framer.WriteHeaders(http2.HeadersFrameParam{StreamID: 1, BlockFragment: fragment}); err != nil {
+			return
+		}
+		for i := 0; i < 1024; i++ {
+			if err := framer.WriteContinuation(1, false, fragment); err != nil {
+				return
+			}
+		}
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	framer := http2.NewFramer(nil, conn)
+	for {
+		if _, err := framer.ReadFrame(); err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				t.Fatal("connection not closed on oversized header block")
+			}
+			break
+		}
+	}
+}
+
+func TestListenWSHTTP2FrameSizeLimit(t *testing.T) {
+	streamSettings := &internet.MemoryStreamConfig{
+		ProtocolName: "websocket",
+		ProtocolSettings: &Config{
+			Path:     "ws",
+			UseHttp2: true,
+		},
+		SecurityType: "tls",
+		SecuritySettings: &tls.Config{
+			Certificate: []*tls.Certificate{tls.ParseCertificate(cert.MustGenerate(nil, cert.CommonName("localhost")))},
+		},
+	}
+	listen, err := ListenWS(context.Background(), net.LocalHostIP, 13155, streamSettings, func(conn internet.Connection) {
+		conn.Close()
+	})
+	common.Must(err)
+	defer listen.Close()
+
+	conn, err := gotls.Dial("tcp", "127.0.0.1:13155", &gotls.Config{
+		InsecureSkipVerify: true, // nolint: gosec
+		NextProtos:         []string{"h2"},
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	// A frame larger than the default SETTINGS_MAX_FRAME_SIZE.
+	go func() {
+		framer := http2.NewFramer(conn, conn)
+		if _, err := io.WriteString(conn, http2.ClientPreface); err != nil {
+			return
+		}
+		if err := framer.WriteSettings(); err != nil {
+			return
+		}
+		framer.WriteHeaders(http2.HeadersFrameParam{StreamID: 1, BlockFragment: make([]byte, 16385), EndHeaders: true})
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	framer := http2.NewFramer(nil, conn)
+	var goAway *http2.GoAwayFrame
+	for {
+		frame, err := framer.ReadFrame()
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				t.Fatal("connection not closed on oversized frame")
+			}
+			break
+		}
+		if f, ok := frame.(*http2.GoAwayFrame); ok {
+			goAway = f
+		}
+	}
+	if goAway == nil || goAway.ErrCode != http2.ErrCodeFrameSize {
+		t.Error("expected GOAWAY with FRAME_SIZE_ERROR, got ", goAway)
+	}
+}
+
+func TestListenWSACMEHTTP01(t *testing.T) {
+	const domain = "ws.v2fly.test"
+
+	// The plain listener answers the HTTP-01 challenges for the TLS one.
+	plainListener, err := ListenWS(context.Background(), net.LocalHostIP, 13152, &internet.MemoryStreamConfig{
+		ProtocolName:     "websocket",
+		ProtocolSettings: &Config{Path: "ws"},
+	}, func(conn internet.Connection) {
+		conn.Close()
+	})
+	common.Must(err)
+	defer plainListener.Close()
+
+	ca := &acme.Server{
+		HTTPPort:   13152,
+		Challenges: []string{acme.ChallengeHTTP01},
+	}
+	common.Must2(ca.Start())
+	defer ca.Close()
+
+	listen, err := ListenWS(context.Background(), net.LocalHostIP, 13153, &internet.MemoryStreamConfig{
+		ProtocolName:     "websocket",
+		ProtocolSettings: &Config{Path: "ws"},
+		SecurityType:     "tls",
+		SecuritySettings: &tls.Config{
+			Acme: &tls.ACME{
+				Domain:       []string{domain},
+				DirectoryUrl: ca.DirectoryURL(),
+				StorageDir:   t.TempDir(),
+			},
+		},
+	}, func(conn internet.Connection) {
+		go func() {
+			defer conn.Close()
+			io.Copy(conn, conn)
+		}()
+	})
+	common.Must(err)
+	defer listen.Close()
+
+	conn, err := Dial(context.Background(), net.TCPDestination(net.LocalHostIP, 13153), &internet.MemoryStreamConfig{
+		ProtocolName:     "websocket",
+		ProtocolSettings: &Config{Path: "ws"},
+		SecurityType:     "tls",
+		SecuritySettings: &tls.Config{
+			ServerName:        domain,
+			DisableSystemRoot: true,
+			Certificate: []*tls.Certificate{{
+				Certificate: ca.CACertificate(),
+				Usage:       tls.Certificate_AUTHORITY_VERIFY,
+			}},
+		},
+	})
+	common.Must(err)
+	defer conn.Close()
+
+	common.Must2(conn.Write([]byte("Test connection")))
+	b := make([]byte, 15)
+	common.Must2(io.ReadFull(conn, b))
+	if string(b) != "Test connection" {
+		t.Error("unexpected response: ", string(b))
+	}
+	if n := ca.Issued(); n != 1 {
+		t.Error("expected 1 certificate issued, but got ", n)
+	}
+}
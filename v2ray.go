@@ -7,11 +7,14 @@ import (
 
 	"github.com/v2fly/v2ray-core/v5/common"
 	"github.com/v2fly/v2ray-core/v5/common/environment"
+	"github.com/v2fly/v2ray-core/v5/common/environment/deferredpersistentstorage"
 	"github.com/v2fly/v2ray-core/v5/common/environment/transientstorageimpl"
 	"github.com/v2fly/v2ray-core/v5/common/serial"
 	"github.com/v2fly/v2ray-core/v5/features"
 	"github.com/v2fly/v2ray-core/v5/features/dns"
 	"github.com/v2fly/v2ray-core/v5/features/dns/localdns"
+	"github.com/v2fly/v2ray-core/v5/features/extension"
+	"github.com/v2fly/v2ray-core/v5/features/extension/storage"
 	"github.com/v2fly/v2ray-core/v5/features/inbound"
 	"github.com/v2fly/v2ray-core/v5/features/outbound"
 	"github.com/v2fly/v2ray-core/v5/features/policy"
@@ -93,24 +96,41 @@ type Instance struct {
 	featureResolutions []resolution
 	running            bool
 	env                environment.RootEnvironment
+	runningConfig      runningConfig
+	apps               map[string]features.Feature
+	configSource       ConfigSource
+	reloadAccess       sync.Mutex
 
 	ctx context.Context
 }
 
 func AddInboundHandler(server *Instance, config *InboundHandlerConfig) error {
-	inboundManager := server.GetFeature(inbound.ManagerType()).(inbound.Manager)
+	handler, err := createInboundHandler(server, config)
+	if err != nil {
+		return err
+	}
+	return addInboundHandler(server, config, handler)
+}
+
+func createInboundHandler(server *Instance, config *InboundHandlerConfig) (inbound.Handler, error) {
 	proxyEnv := server.env.ProxyEnvironment("i" + config.Tag)
 	rawHandler, err := CreateObjectWithEnvironment(server, config, proxyEnv)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	handler, ok := rawHandler.(inbound.Handler)
 	if !ok {
-		return newError("not an InboundHandler")
+		return nil, newError("not an InboundHandler")
 	}
+	return handler, nil
+}
+
+func addInboundHandler(server *Instance, config *InboundHandlerConfig, handler inbound.Handler) error {
+	inboundManager := server.GetFeature(inbound.ManagerType()).(inbound.Manager)
 	if err := inboundManager.AddHandler(server.ctx, handler); err != nil {
 		return err
 	}
+	server.runningConfig.putInbound(config)
 	return nil
 }
 
@@ -138,6 +158,7 @@ func AddOutboundHandler(server *Instance, config *OutboundHandlerConfig) error {
 	if err := outboundManager.AddHandler(server.ctx, handler); err != nil {
 		return err
 	}
+	server.runningConfig.putOutbound(config)
 	return nil
 }
 
@@ -190,8 +211,16 @@ func initInstanceWithConfig(config *Config, server *Instance) (bool, error) {
 	if err := config.Transport.Apply(); err != nil {
 		return true, err
 	}
+	server.runningConfig.setBase(config)
 
-	server.env = environment.NewRootEnvImpl(server.ctx, transientstorageimpl.NewScopedTransientStorageImpl())
+	persistentStorage := deferredpersistentstorage.NewDeferredPersistentStorage(func(ctx context.Context) (storage.ScopedPersistentStorage, error) {
+		if engine, ok := server.GetFeature(extension.PersistentStorageEngineType()).(storage.ScopedPersistentStorage); ok {
+			return engine, nil
+		}
+		return nil, newError("persistent storage is not configured")
+	})
+	server.apps = make(map[string]features.Feature, len(config.App))
+	server.env = environment.NewRootEnvImpl(server.ctx, transientstorageimpl.NewScopedTransientStorageImpl(), persistentStorage)
 
 	for _, appSettings := range config.App {
 		settings, err := serial.GetInstanceOf(appSettings)
@@ -208,6 +237,7 @@ func initInstanceWithConfig(config *Config, server *Instance) (bool, error) {
 			if err := server.AddFeature(feature); err != nil {
 				return true, err
 			}
+			server.apps[key] = feature
 		}
 	}
 